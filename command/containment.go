@@ -0,0 +1,78 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+// CheckContainment confirms that every path in the given plan (see the Plan function) is within the rootDirectory.
+// Paths are checked as they are, and again after resolving any symbolic links in the parts of the path that already exist
+// (so a symbolic link can't be used to write outside of the rootDirectory).
+// A ValidationError is returned for the first path that is outside of the rootDirectory.
+func CheckContainment(items []models.PlanItem, rootDirectory string) error {
+	resolvedRoot := resolveExistingPath(rootDirectory)
+
+	for _, item := range items {
+		if !isWithinDirectory(rootDirectory, item.Path) {
+			return &customerrors.ValidationError{
+				Message: fmt.Sprintf(
+					"the path '%s' is outside of the output directory '%s' (use '--allow-outside-root' to allow this)",
+					item.Path,
+					rootDirectory,
+				),
+			}
+		}
+
+		resolvedPath := resolveExistingPath(item.Path)
+		if !isWithinDirectory(resolvedRoot, resolvedPath) {
+			return &customerrors.ValidationError{
+				Message: fmt.Sprintf(
+					"the path '%s' resolves to '%s' (through a symbolic link), which is outside of the output directory '%s' (use '--allow-outside-root' to allow this)",
+					item.Path,
+					resolvedPath,
+					rootDirectory,
+				),
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveExistingPath resolves any symbolic links in the longest part of the given path that already exists, then
+// rejoins the rest of the path to it. If nothing can be resolved, the path is returned as it is.
+func resolveExistingPath(pathToResolve string) string {
+	existingPath := filepath.Clean(pathToResolve)
+	remainingParts := []string{}
+
+	for {
+		if resolvedPath, err := EvalSymlinks(existingPath); err == nil {
+			return filepath.Join(append([]string{resolvedPath}, remainingParts...)...)
+		}
+
+		parentPath := filepath.Dir(existingPath)
+		if parentPath == existingPath {
+			return pathToResolve
+		}
+
+		remainingParts = append([]string{filepath.Base(existingPath)}, remainingParts...)
+		existingPath = parentPath
+	}
+}
+
+// isWithinDirectory identifies if the given path is inside the given directory (the directory itself is not inside)
+func isWithinDirectory(directoryPath, pathToCheck string) bool {
+	relativePath, relErr := filepath.Rel(directoryPath, pathToCheck)
+	if relErr != nil {
+		return false
+	}
+
+	return relativePath != "." &&
+		relativePath != ".." &&
+		!strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) &&
+		!filepath.IsAbs(relativePath)
+}
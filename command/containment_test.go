@@ -0,0 +1,133 @@
+package command_test
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/command"
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+var containmentTestRoot = filepath.FromSlash("/project")
+
+// getEvalSymlinks creates a mock EvalSymlinks func, where the given paths exist (mapped to the paths they resolve to)
+func getEvalSymlinks(existingPaths map[string]string) func(string) (string, error) {
+	return func(path string) (string, error) {
+		if resolvedPath, exists := existingPaths[path]; exists {
+			return resolvedPath, nil
+		}
+
+		return "", fs.ErrNotExist
+	}
+}
+
+// setup runs any setup code that is generic across all tests for the CheckContainment func
+func containmentBeforeEach() {
+	command.EvalSymlinks = getEvalSymlinks(map[string]string{
+		containmentTestRoot: containmentTestRoot,
+	})
+}
+
+func TestCheckContainmentWillReturnNilForPathsInsideTheRoot(t *testing.T) {
+	containmentBeforeEach()
+
+	items := []models.PlanItem{
+		{Path: filepath.Join(containmentTestRoot, "my_dir"), IsDirectory: true},
+		{Path: filepath.Join(containmentTestRoot, "my_dir", "my_file.txt")},
+		{Path: filepath.Join(containmentTestRoot, "..project_file.txt")},
+	}
+
+	if err := command.CheckContainment(items, containmentTestRoot); err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+}
+
+func TestCheckContainmentWillReturnValidationErrorForPathsOutsideTheRoot(t *testing.T) {
+	pathsToTest := []string{
+		filepath.FromSlash("/outside.txt"),
+		filepath.FromSlash("/etc/passwd"),
+		filepath.FromSlash("/project_other/file.txt"),
+		containmentTestRoot,
+	}
+
+	for _, pathToTest := range pathsToTest {
+		containmentBeforeEach()
+
+		items := []models.PlanItem{
+			{Path: filepath.Join(containmentTestRoot, "my_file.txt")},
+			{Path: pathToTest},
+		}
+
+		err := command.CheckContainment(items, containmentTestRoot)
+		if err == nil {
+			t.Errorf("expected an error for the path '%s'. Got nil", pathToTest)
+			continue
+		}
+
+		var validationErr *customerrors.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("expected a ValidationError for the path '%s'. Got '%s'", pathToTest, err.Error())
+		}
+	}
+}
+
+func TestCheckContainmentWillReturnValidationErrorForPathsThroughSymlinksThatLeaveTheRoot(t *testing.T) {
+	linkPath := filepath.Join(containmentTestRoot, "my_link")
+
+	command.EvalSymlinks = getEvalSymlinks(map[string]string{
+		containmentTestRoot: containmentTestRoot,
+		linkPath:            filepath.FromSlash("/etc"),
+	})
+
+	items := []models.PlanItem{
+		{Path: filepath.Join(linkPath, "my_dir"), IsDirectory: true},
+		{Path: filepath.Join(linkPath, "my_dir", "passwd")},
+	}
+
+	err := command.CheckContainment(items, containmentTestRoot)
+	if err == nil {
+		t.Errorf("expected an error. Got nil")
+		return
+	}
+
+	var validationErr *customerrors.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected a ValidationError. Got '%s'", err.Error())
+	}
+}
+
+func TestCheckContainmentWillAllowSymlinksThatStayInsideTheRoot(t *testing.T) {
+	linkPath := filepath.Join(containmentTestRoot, "my_link")
+
+	command.EvalSymlinks = getEvalSymlinks(map[string]string{
+		containmentTestRoot: containmentTestRoot,
+		linkPath:            filepath.Join(containmentTestRoot, "my_real_dir"),
+	})
+
+	items := []models.PlanItem{
+		{Path: filepath.Join(linkPath, "my_file.txt")},
+	}
+
+	if err := command.CheckContainment(items, containmentTestRoot); err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+}
+
+func TestCheckContainmentWillResolveSymlinksInTheRoot(t *testing.T) {
+	realRoot := filepath.FromSlash("/private/project")
+
+	command.EvalSymlinks = getEvalSymlinks(map[string]string{
+		containmentTestRoot: realRoot,
+	})
+
+	items := []models.PlanItem{
+		{Path: filepath.Join(containmentTestRoot, "my_dir", "my_file.txt")},
+	}
+
+	if err := command.CheckContainment(items, containmentTestRoot); err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+}
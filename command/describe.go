@@ -0,0 +1,196 @@
+package command
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/M-Derbyshire/scaff/models"
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+// Describe returns a description of what the given command needs, and what it creates, without populating anything: every
+// variable that it declares, or uses in its names/templates (found by scanning them, see Command.VariableUsages), and the
+// tree of directories/files it creates (including the contents of any template trees).
+// The fullTemplatesDirectoryPath is the path to the command's template directory.
+// If any templates can't be read, the description is still returned (along with the error).
+func Describe(command models.Command, fullTemplatesDirectoryPath string) (models.CommandDescription, error) {
+	description := models.CommandDescription{
+		Name:              command.Name,
+		Description:       command.Description,
+		TemplateDirectory: fullTemplatesDirectoryPath,
+		Variables:         []models.DescribedVariable{},
+		Tree:              []models.DescribedScaffold{},
+	}
+
+	command, err := command.ExpandTemplateTrees(fullTemplatesDirectoryPath)
+	if err != nil {
+		return description, err
+	}
+
+	usages, usageErr := command.VariableUsages(fullTemplatesDirectoryPath)
+
+	for _, definition := range command.Vars {
+		described := models.DescribedVariable{Definition: definition, IsDeclared: true, HasDefault: definition.Default != "", Locations: []string{}}
+		if usageIndex := slices.IndexFunc(usages, func(usage models.VariableUsage) bool { return usage.Name == definition.Name }); usageIndex >= 0 {
+			described.Locations = usages[usageIndex].Locations
+		}
+
+		description.Variables = append(description.Variables, described)
+	}
+
+	for _, usage := range usages {
+		isDeclared := slices.ContainsFunc(command.Vars, func(definition variable.Definition) bool {
+			return definition.Name == usage.Name
+		})
+		if isDeclared {
+			continue
+		}
+
+		definition := variable.Definition{Name: usage.Name, Type: variable.TypeString}
+		if usage.IsList {
+			definition.Type = variable.TypeList
+		}
+
+		description.Variables = append(description.Variables, models.DescribedVariable{
+			Definition: definition,
+			HasDefault: usage.HasDefault,
+			Locations:  usage.Locations,
+		})
+	}
+
+	description.Tree = describeScaffolds(command.Files, command.Directories, fullTemplatesDirectoryPath)
+
+	return description, usageErr
+}
+
+// describeScaffolds returns a description of each of the given files, followed by each of the given directories.
+// Files are described as raw if they are copied from their templates as they are (including binary templates, which are
+// found by reading them). Templates that can't be read are only described as raw if the file is marked as raw (the read
+// error is reported by Command.VariableUsages).
+func describeScaffolds(files []models.FileScaffold, directories []models.DirectoryScaffold, fullTemplatesDirectoryPath string) []models.DescribedScaffold {
+	described := []models.DescribedScaffold{}
+
+	for _, file := range files {
+		isRaw := file.Raw
+		if templateBytes, readErr := ReadFile(file.GetFullTemplatePath(fullTemplatesDirectoryPath)); readErr == nil {
+			isRaw = file.IsRawCopy(templateBytes)
+		}
+
+		described = append(described, models.DescribedScaffold{
+			Name:         file.Name,
+			TemplatePath: file.TemplatePath,
+			IsRaw:        isRaw,
+			When:         file.When,
+			ForEach:      file.ForEach,
+			As:           file.As,
+		})
+	}
+
+	for _, directory := range directories {
+		described = append(described, models.DescribedScaffold{
+			Name:        directory.Name,
+			IsDirectory: true,
+			When:        directory.When,
+			ForEach:     directory.ForEach,
+			As:          directory.As,
+			Children:    describeScaffolds(directory.Files, directory.Directories, fullTemplatesDirectoryPath),
+		})
+	}
+
+	return described
+}
+
+// FormatDescription returns the given description as printable text
+func FormatDescription(description models.CommandDescription) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "Command: %s\n", description.Name)
+	if description.Description != "" {
+		fmt.Fprintf(&builder, "Description: %s\n", description.Description)
+	}
+	fmt.Fprintf(&builder, "Template directory: %s\n", description.TemplateDirectory)
+
+	builder.WriteString("\nVariables:\n")
+	if len(description.Variables) == 0 {
+		builder.WriteString("  (none)\n")
+	}
+
+	for _, described := range description.Variables {
+		fmt.Fprintf(&builder, "  %s (%s)\n", described.Name, strings.Join(variableTraits(described), ", "))
+
+		if described.Description != "" {
+			fmt.Fprintf(&builder, "    %s\n", described.Description)
+		}
+		if described.Default != "" {
+			fmt.Fprintf(&builder, "    default: %s\n", described.Default)
+		}
+		if len(described.Options) > 0 {
+			fmt.Fprintf(&builder, "    options: %s\n", strings.Join(described.Options, ", "))
+		}
+		if described.Pattern != "" {
+			fmt.Fprintf(&builder, "    pattern: %s\n", described.Pattern)
+		}
+		if len(described.Locations) > 0 {
+			builder.WriteString("    used in:\n")
+			for _, location := range described.Locations {
+				fmt.Fprintf(&builder, "      - %s\n", location)
+			}
+		}
+	}
+
+	builder.WriteString("\nCreates:\n.\n")
+	writeDescribedScaffolds(&builder, description.Tree, "")
+
+	return builder.String()
+}
+
+// variableTraits returns the type of the described variable, followed by anything else that affects how its value is given
+func variableTraits(described models.DescribedVariable) []string {
+	traits := []string{string(described.GetType())}
+
+	if described.Required {
+		traits = append(traits, "required")
+	}
+
+	if !described.IsDeclared {
+		traits = append(traits, "not declared")
+	}
+
+	if !described.IsDeclared && described.HasDefault {
+		traits = append(traits, "optional")
+	}
+
+	return traits
+}
+
+// writeDescribedScaffolds writes a line for each of the described directories/files (and their children) to the builder,
+// as a tree. The indent is written before each line.
+func writeDescribedScaffolds(builder *strings.Builder, scaffolds []models.DescribedScaffold, indent string) {
+	for i, scaffold := range scaffolds {
+		branch, childIndent := "├── ", "│   "
+		if i == len(scaffolds)-1 {
+			branch, childIndent = "└── ", "    "
+		}
+
+		builder.WriteString(indent + branch + scaffold.Name)
+		if scaffold.IsDirectory {
+			builder.WriteString("/")
+		} else if scaffold.IsRaw {
+			builder.WriteString("  (copy of: " + scaffold.TemplatePath + ")")
+		} else {
+			builder.WriteString("  (template: " + scaffold.TemplatePath + ")")
+		}
+
+		if scaffold.ForEach != "" {
+			builder.WriteString("  [for each " + scaffold.As + " in " + scaffold.ForEach + "]")
+		}
+
+		if scaffold.When != "" {
+			builder.WriteString("  [when " + scaffold.When + "]")
+		}
+
+		builder.WriteString("\n")
+		writeDescribedScaffolds(builder, scaffold.Children, indent+childIndent)
+	}
+}
@@ -0,0 +1,152 @@
+package command_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/command"
+	"github.com/M-Derbyshire/scaff/models"
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+// describeBeforeEach mocks the templates for the describe tests (every template uses the "name" variable, except PNG
+// templates, which are binary)
+func describeBeforeEach() {
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		if strings.HasSuffix(filePath, ".png") {
+			return []byte("\x89PNG\x00"), nil
+		}
+
+		return []byte("{: name | pascal :}"), nil
+	}
+	command.ReadFile = models.ReadFile
+}
+
+func TestDescribeWillListDeclaredVariablesThenUsedVariables(t *testing.T) {
+	describeBeforeEach()
+
+	testCommand := models.Command{
+		Name:        "service",
+		Description: "Creates a service",
+		Vars: []variable.Definition{
+			{Name: "kind", Type: variable.TypeEnum, Options: []string{"api", "cli"}},
+			{Name: "name", Required: true},
+		},
+		Files: []models.FileScaffold{
+			{Name: "{: entity :}.go", TemplatePath: "model.go", ForEach: "entities", As: "entity"},
+			{Name: "{: suffix | default \"x\" :}.txt", TemplatePath: "other.txt"},
+		},
+	}
+
+	result, err := command.Describe(testCommand, "/templates")
+	if err != nil {
+		t.Fatalf("expected no error. Got '%s'", err.Error())
+	}
+
+	if result.Name != "service" || result.Description != "Creates a service" || result.TemplateDirectory != "/templates" {
+		t.Errorf("expected the command's details to be described. Got %+v", result)
+	}
+
+	expectedNames := []string{"kind", "name", "entities", "suffix"}
+	names := []string{}
+	for _, described := range result.Variables {
+		names = append(names, described.Name)
+	}
+
+	if !slices.Equal(names, expectedNames) {
+		t.Fatalf("expected the variables %v. Got %v", expectedNames, names)
+	}
+
+	if !result.Variables[0].IsDeclared || len(result.Variables[0].Locations) != 0 || result.Variables[0].GetType() != variable.TypeEnum {
+		t.Errorf("expected 'kind' to be declared (as an enum), and unused. Got %+v", result.Variables[0])
+	}
+
+	if len(result.Variables[1].Locations) != 2 || !result.Variables[1].Required {
+		t.Errorf("expected 'name' to be required, and used in both templates. Got %+v", result.Variables[1])
+	}
+
+	if result.Variables[2].IsDeclared || result.Variables[2].GetType() != variable.TypeList {
+		t.Errorf("expected 'entities' to be an undeclared list. Got %+v", result.Variables[2])
+	}
+
+	if !result.Variables[3].HasDefault {
+		t.Errorf("expected 'suffix' to have a default. Got %+v", result.Variables[3])
+	}
+}
+
+func TestDescribeWillDescribeBinaryTemplatesAsRaw(t *testing.T) {
+	describeBeforeEach()
+
+	testCommand := models.Command{
+		Name: "service",
+		Files: []models.FileScaffold{
+			{Name: "logo.png", TemplatePath: "logo.png"},
+			{Name: "{: name :}.go", TemplatePath: "model.go"},
+		},
+	}
+
+	result, err := command.Describe(testCommand, "/templates")
+	if err != nil {
+		t.Fatalf("expected no error. Got '%s'", err.Error())
+	}
+
+	if len(result.Tree) != 2 || !result.Tree[0].IsRaw || result.Tree[1].IsRaw {
+		t.Errorf("expected only the binary template to be described as raw. Got %+v", result.Tree)
+	}
+}
+
+func TestFormatDescriptionWillShowVariablesAndTheTreeWithPlaceholders(t *testing.T) {
+	describeBeforeEach()
+
+	testCommand := models.Command{
+		Name: "service",
+		Vars: []variable.Definition{{Name: "name", Description: "The service's name", Default: "svc"}},
+		Files: []models.FileScaffold{
+			{Name: "Dockerfile", TemplatePath: "Dockerfile", When: "withDocker"},
+		},
+		Directories: []models.DirectoryScaffold{
+			{
+				Name:    "{: module :}",
+				ForEach: "modules",
+				As:      "module",
+				Files:   []models.FileScaffold{{Name: "{: name :}.sh", TemplatePath: "script.sh", Raw: true}},
+			},
+		},
+	}
+
+	description, err := command.Describe(testCommand, "/templates")
+	if err != nil {
+		t.Fatalf("expected no error. Got '%s'", err.Error())
+	}
+
+	expected := strings.Join([]string{
+		"Command: service",
+		"Template directory: /templates",
+		"",
+		"Variables:",
+		"  name (string)",
+		"    The service's name",
+		"    default: svc",
+		"    used in:",
+		"      - the template '/templates/Dockerfile'",
+		"      - the file name '{: name :}.sh'",
+		"  withDocker (string, not declared)",
+		"    used in:",
+		"      - the 'when' condition of the file 'Dockerfile'",
+		"  modules (list, not declared)",
+		"    used in:",
+		"      - the 'forEach' of the directory '{: module :}'",
+		"",
+		"Creates:",
+		".",
+		"├── Dockerfile  (template: Dockerfile)  [when withDocker]",
+		"└── {: module :}/  [for each module in modules]",
+		"    └── {: name :}.sh  (copy of: script.sh)",
+		"",
+	}, "\n")
+
+	if result := command.FormatDescription(description); result != expected {
+		t.Errorf("expected the description:\n%s\nGot:\n%s", expected, result)
+	}
+}
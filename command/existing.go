@@ -1,44 +1,27 @@
 package command
 
 import (
-	"path/filepath"
-
 	"github.com/M-Derbyshire/scaff/models"
-	"github.com/M-Derbyshire/scaff/variable"
 )
 
-// IdentifyExistingPaths identifies file/directory paths in the given command that already exist
-// The workingDirectory is the path to the current working directory
-// The vars is a map of variables that may be needed to populate the directory/file names
-func IdentifyExistingPaths(command models.Command, workingDirectory string, vars map[string]string) ([]string, error) {
+// IdentifyExistingPaths identifies the paths in the given plan (see the Plan function) that would collide with existing paths.
+// This checks every item in the plan (not just the top level). Existing directories don't collide, as they are reused, so
+// only files that already exist (or paths that exist as the wrong type) are returned.
+func IdentifyExistingPaths(items []models.PlanItem) []string {
 	results := []string{}
 
-	for _, file := range command.Files {
-		populatedName, err := variable.Populate(file.Name, vars)
-		if err != nil {
-			return results, err
+	for _, item := range items {
+		pathInfo, _ := FileStat(item.Path)
+		if pathInfo == nil {
+			continue
 		}
 
-		filePathToCheck := filepath.Join(workingDirectory, populatedName)
-
-		if fileInfo, _ := FileStat(filePathToCheck); fileInfo != nil && !fileInfo.IsDir() {
-			results = append(results, filePathToCheck)
+		if item.IsDirectory && pathInfo.IsDir() {
+			continue
 		}
-	}
 
-	// We only need to check the first layer of directories (the inner files/directories don't need to be checked)
-	for _, directory := range command.Directories {
-		populatedName, err := variable.Populate(directory.Name, vars)
-		if err != nil {
-			return results, err
-		}
-
-		directoryPathToCheck := filepath.Join(workingDirectory, populatedName)
-
-		if dirInfo, _ := FileStat(directoryPathToCheck); dirInfo != nil && dirInfo.IsDir() {
-			results = append(results, directoryPathToCheck)
-		}
+		results = append(results, item.Path)
 	}
 
-	return results, nil
+	return results
 }
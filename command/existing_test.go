@@ -11,46 +11,28 @@ import (
 	"github.com/M-Derbyshire/scaff/models"
 )
 
-func TestIdentifyExistingPathsWillReturnExistingPaths(t *testing.T) {
-	workingDirectory := "C:/project"
+var existingTestWorkingDirectory = "C:/project"
+
+// A plan with files/directories at several levels
+var existingTestPlan = []models.PlanItem{
+	{Path: filepath.Join(existingTestWorkingDirectory, "my_file.txt")},
+	{Path: filepath.Join(existingTestWorkingDirectory, "my_file2.txt")},
+	{Path: filepath.Join(existingTestWorkingDirectory, "my_dir"), IsDirectory: true},
+	{Path: filepath.Join(existingTestWorkingDirectory, "my_dir", "my_inner_file.txt")},
+	{Path: filepath.Join(existingTestWorkingDirectory, "my_dir", "my_inner_dir"), IsDirectory: true},
+	{Path: filepath.Join(existingTestWorkingDirectory, "my_dir", "my_inner_dir", "my_deep_file.txt")},
+	{Path: filepath.Join(existingTestWorkingDirectory, "my_dir2"), IsDirectory: true},
+}
 
+func TestIdentifyExistingPathsWillReturnExistingFiles(t *testing.T) {
 	files := []mocks.MockFileInfo{
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_file.txt"), false),
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_file2.txt"), false),
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_dir"), true),
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_dir2"), true),
+		mocks.CreateMockInfo(filepath.Join(existingTestWorkingDirectory, "my_file.txt"), false),
+		mocks.CreateMockInfo(filepath.Join(existingTestWorkingDirectory, "my_file2.txt"), false),
 	}
 
 	command.FileStat = mocks.GetFileStat(files)
 
-	testCommand := models.Command{
-		Name:                  "test",
-		TemplateDirectoryPath: "/test",
-		Files: []models.FileScaffold{
-			{
-				Name:         "my_file.txt",
-				TemplatePath: "",
-			},
-			{
-				Name:         "my_file2.txt",
-				TemplatePath: "",
-			},
-		},
-		Directories: []models.DirectoryScaffold{
-			{
-				Name:        "my_dir",
-				Files:       []models.FileScaffold{},
-				Directories: []models.DirectoryScaffold{},
-			},
-			{
-				Name:        "my_dir2",
-				Files:       []models.FileScaffold{},
-				Directories: []models.DirectoryScaffold{},
-			},
-		},
-	}
-
-	results, _ := command.IdentifyExistingPaths(testCommand, workingDirectory, map[string]string{})
+	results := command.IdentifyExistingPaths(existingTestPlan)
 
 	if len(results) != len(files) {
 		t.Errorf("expected length of results from IdentifyExistingPaths to be %d. got %d", len(files), len(results))
@@ -64,213 +46,67 @@ func TestIdentifyExistingPathsWillReturnExistingPaths(t *testing.T) {
 	}
 }
 
-func TestIdentifyExistingPathsWillNotReturnNonExistantPaths(t *testing.T) {
-	workingDirectory := "C:/project"
-
-	existingFiles := []mocks.MockFileInfo{
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_file.txt"), false),
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_dir"), true),
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_file2.txt"), false),
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_dir2"), true),
+func TestIdentifyExistingPathsWillCheckPathsWithinInnerDirectories(t *testing.T) {
+	files := []mocks.MockFileInfo{
+		mocks.CreateMockInfo(filepath.Join(existingTestWorkingDirectory, "my_dir"), true),
+		mocks.CreateMockInfo(filepath.Join(existingTestWorkingDirectory, "my_dir", "my_inner_file.txt"), false),
+		mocks.CreateMockInfo(filepath.Join(existingTestWorkingDirectory, "my_dir", "my_inner_dir"), true),
+		mocks.CreateMockInfo(filepath.Join(existingTestWorkingDirectory, "my_dir", "my_inner_dir", "my_deep_file.txt"), false),
 	}
-	nonExistingPaths := []string{
-		filepath.Join(workingDirectory, "my_file3.txt"),
-		filepath.Join(workingDirectory, "my_dir3"),
-		filepath.Join(workingDirectory, "my_file4.txt"),
-		filepath.Join(workingDirectory, "my_dir4"),
+	expectedPaths := []string{
+		filepath.Join(existingTestWorkingDirectory, "my_dir", "my_inner_file.txt"),
+		filepath.Join(existingTestWorkingDirectory, "my_dir", "my_inner_dir", "my_deep_file.txt"),
 	}
 
-	command.FileStat = mocks.GetFileStat(existingFiles)
-
-	// Has a mixture of existing and non-existing files/directories
-	testCommand := models.Command{
-		Name:                  "test",
-		TemplateDirectoryPath: "/test",
-		Files: []models.FileScaffold{
-			{
-				Name:         "my_file.txt",
-				TemplatePath: "",
-			},
-			{
-				Name:         "my_file2.txt",
-				TemplatePath: "",
-			},
-			{
-				Name:         "my_file3.txt",
-				TemplatePath: "",
-			},
-			{
-				Name:         "my_file4.txt",
-				TemplatePath: "",
-			},
-		},
-		Directories: []models.DirectoryScaffold{
-			{
-				Name:        "my_dir",
-				Files:       []models.FileScaffold{},
-				Directories: []models.DirectoryScaffold{},
-			},
-			{
-				Name:        "my_dir2",
-				Files:       []models.FileScaffold{},
-				Directories: []models.DirectoryScaffold{},
-			},
-			{
-				Name:        "my_dir3",
-				Files:       []models.FileScaffold{},
-				Directories: []models.DirectoryScaffold{},
-			},
-			{
-				Name:        "my_dir4",
-				Files:       []models.FileScaffold{},
-				Directories: []models.DirectoryScaffold{},
-			},
-		},
-	}
+	command.FileStat = mocks.GetFileStat(files)
 
-	results, _ := command.IdentifyExistingPaths(testCommand, workingDirectory, map[string]string{})
+	results := command.IdentifyExistingPaths(existingTestPlan)
 
-	for _, path := range results {
-		if slices.Contains(nonExistingPaths, path) {
-			t.Errorf("expected results from IdentifyExistingPaths not to contain non-existant paths, but got \"%s\"", path)
-		}
+	if !slices.Equal(results, expectedPaths) {
+		t.Errorf("expected results from IdentifyExistingPaths to be %v. got %v", expectedPaths, results)
 	}
 }
 
-func TestIdentifyExistingPathsWillReturnEmptySliceIfNoExistingPaths(t *testing.T) {
-	workingDirectory := "C:/project"
-
-	command.FileStat = mocks.GetFileStat([]mocks.MockFileInfo{})
-
-	testCommand := models.Command{
-		Name:                  "test",
-		TemplateDirectoryPath: "/test",
-		Files: []models.FileScaffold{
-			{
-				Name:         "my_file.txt",
-				TemplatePath: "",
-			},
-			{
-				Name:         "my_file2.txt",
-				TemplatePath: "",
-			},
-		},
-		Directories: []models.DirectoryScaffold{
-			{
-				Name:        "my_dir",
-				Files:       []models.FileScaffold{},
-				Directories: []models.DirectoryScaffold{},
-			},
-			{
-				Name:        "my_dir2",
-				Files:       []models.FileScaffold{},
-				Directories: []models.DirectoryScaffold{},
-			},
-		},
+func TestIdentifyExistingPathsWillNotReturnExistingDirectories(t *testing.T) {
+	files := []mocks.MockFileInfo{
+		mocks.CreateMockInfo(filepath.Join(existingTestWorkingDirectory, "my_dir"), true),
+		mocks.CreateMockInfo(filepath.Join(existingTestWorkingDirectory, "my_dir2"), true),
 	}
 
-	results, _ := command.IdentifyExistingPaths(testCommand, workingDirectory, map[string]string{})
+	command.FileStat = mocks.GetFileStat(files)
+
+	results := command.IdentifyExistingPaths(existingTestPlan)
 
 	if len(results) > 0 {
-		t.Errorf("expected IdentifyExistingPaths to return empty slice. got length of %d", len(results))
+		t.Errorf("expected IdentifyExistingPaths to return empty slice. got %v", results)
 	}
 }
 
-func TestIdentifyExistingPathsWillPopulatePathNamesUsingGivenVars(t *testing.T) {
-	workingDirectory := "C:/project"
-
-	vars := map[string]string{
-		"files_var": "files_text",
-		"dirs_var":  "dirs_text",
-	}
-
+func TestIdentifyExistingPathsWillReturnPathsThatExistAsTheWrongType(t *testing.T) {
 	files := []mocks.MockFileInfo{
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_files_text_file.txt"), false),
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_dirs_text_dir"), true),
+		mocks.CreateMockInfo(filepath.Join(existingTestWorkingDirectory, "my_file.txt"), true), // A directory where a file should be
+		mocks.CreateMockInfo(filepath.Join(existingTestWorkingDirectory, "my_dir2"), false),    // A file where a directory should be
 	}
-
-	command.FileStat = mocks.GetFileStat(files)
-
-	testCommand := models.Command{
-		Name:                  "test",
-		TemplateDirectoryPath: "/test",
-		Files: []models.FileScaffold{
-			{
-				Name:         "my_{: files_var :}_file.txt",
-				TemplatePath: "",
-			},
-		},
-		Directories: []models.DirectoryScaffold{
-			{
-				Name:        "my_{: dirs_var :}_dir",
-				Files:       []models.FileScaffold{},
-				Directories: []models.DirectoryScaffold{},
-			},
-		},
+	expectedPaths := []string{
+		filepath.Join(existingTestWorkingDirectory, "my_file.txt"),
+		filepath.Join(existingTestWorkingDirectory, "my_dir2"),
 	}
 
-	results, _ := command.IdentifyExistingPaths(testCommand, workingDirectory, vars)
+	command.FileStat = mocks.GetFileStat(files)
 
-	if len(results) != len(files) {
-		t.Errorf("expected length of results from IdentifyExistingPaths to be %d. got %d", len(files), len(results))
-		return
-	}
+	results := command.IdentifyExistingPaths(existingTestPlan)
 
-	for idx, result := range results {
-		if result != files[idx].FilePath {
-			t.Errorf("expected path from IdentifyExistingPaths to be \"%s\". got \"%s\"", files[idx].FilePath, result)
-		}
+	if !slices.Equal(results, expectedPaths) {
+		t.Errorf("expected results from IdentifyExistingPaths to be %v. got %v", expectedPaths, results)
 	}
 }
 
-func TestIdentifyExistingPathsWillNotCheckPathsWithinInnerDirectories(t *testing.T) {
-	workingDirectory := "C:/project"
-
-	rootFiles := []mocks.MockFileInfo{
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_file.txt"), false),
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_dir"), true),
-	}
-	innerFiles := []mocks.MockFileInfo{
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_dir", "my_inner_dir"), true),
-		mocks.CreateMockInfo(filepath.Join(workingDirectory, "my_dir", "my_inner_file.txt"), false),
-	}
-
-	command.FileStat = mocks.GetFileStat(append(rootFiles, innerFiles...))
-
-	testCommand := models.Command{
-		Name:                  "test",
-		TemplateDirectoryPath: "/test",
-		Files: []models.FileScaffold{
-			{
-				Name:         "my_file.txt",
-				TemplatePath: "",
-			},
-		},
-		Directories: []models.DirectoryScaffold{
-			{
-				Name: "my_dir",
-				Files: []models.FileScaffold{
-					{
-						Name:         "my_inner_file.txt",
-						TemplatePath: "",
-					},
-				},
-				Directories: []models.DirectoryScaffold{
-					{
-						Name:        "my_inner_dir",
-						Files:       []models.FileScaffold{},
-						Directories: []models.DirectoryScaffold{},
-					},
-				},
-			},
-		},
-	}
+func TestIdentifyExistingPathsWillReturnEmptySliceIfNoExistingPaths(t *testing.T) {
+	command.FileStat = mocks.GetFileStat([]mocks.MockFileInfo{})
 
-	results, _ := command.IdentifyExistingPaths(testCommand, workingDirectory, map[string]string{})
+	results := command.IdentifyExistingPaths(existingTestPlan)
 
-	for _, innerFile := range innerFiles {
-		if slices.Contains(results, innerFile.FilePath) {
-			t.Errorf("expected IdentifyExistingPaths not to check for any paths within inner directories, but it checked \"%s\"", innerFile.FilePath)
-		}
+	if len(results) > 0 {
+		t.Errorf("expected IdentifyExistingPaths to return empty slice. got length of %d", len(results))
 	}
 }
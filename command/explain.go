@@ -0,0 +1,96 @@
+package command
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+// Explain searches for the given command in the same way as Find, and returns a trace of how it is resolved: every
+// directory that is checked for a file (with the given "fileNameAndExt"), every scaff file (and child file) that is read,
+// and every definition of the command (in precedence order, so the first is the one that Find returns). Unlike Find, the
+// search doesn't stop at the first definition.
+// If there is an error reading a file, the trace up to that point is returned along with the error.
+func Explain(commandName, fileNameAndExt, currentPath string) (models.Resolution, error) {
+	resolution := models.Resolution{
+		CommandName:        commandName,
+		CheckedDirectories: []models.CheckedDirectory{},
+		ReadFiles:          []models.ReadScaffFile{},
+		Definitions:        []models.CommandDefinition{},
+	}
+
+	for _, filePathToCheck := range scaffFilePathsToCheck(fileNameAndExt, currentPath) {
+		_, statErr := FileStat(filePathToCheck)
+		resolution.CheckedDirectories = append(resolution.CheckedDirectories, models.CheckedDirectory{
+			Path:         path.Dir(filePathToCheck),
+			HasScaffFile: statErr == nil,
+		})
+	}
+
+	err := walkScaffFiles(fileNameAndExt, currentPath, func(file walkedScaffFile) bool {
+		resolution.ReadFiles = append(resolution.ReadFiles, models.ReadScaffFile{Path: file.filePath, ChildOf: file.parentFilePath})
+
+		for _, command := range file.scaffFile.Commands {
+			if command.Name == commandName {
+				resolution.Definitions = append(resolution.Definitions, models.CommandDefinition{
+					ScaffFilePath:    file.filePath,
+					FullTemplatePath: commandTemplatePath(file, command),
+				})
+			}
+		}
+
+		return false
+	})
+
+	return resolution, err
+}
+
+// commandTemplatePath returns the full path to the template directory of a command in the given scaff file
+func commandTemplatePath(file walkedScaffFile, command models.Command) string {
+	containingDir, _ := path.Split(file.filePath)
+	return path.Join(containingDir, command.TemplateDirectoryPath)
+}
+
+// FormatResolution returns the given resolution as a printable trace
+func FormatResolution(resolution models.Resolution) string {
+	var builder strings.Builder
+
+	builder.WriteString("Directories checked for a scaff file (nearest first):\n")
+	for _, directory := range resolution.CheckedDirectories {
+		result := "not found"
+		if directory.HasScaffFile {
+			result = "found"
+		}
+
+		fmt.Fprintf(&builder, "  %s  (%s)\n", directory.Path, result)
+	}
+
+	builder.WriteString("\nScaff files read (in search order):\n")
+	for _, file := range resolution.ReadFiles {
+		fmt.Fprintf(&builder, "  %s", file.Path)
+		if file.ChildOf != "" {
+			fmt.Fprintf(&builder, "  (child of %s)", file.ChildOf)
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(resolution.Definitions) == 0 {
+		fmt.Fprintf(&builder, "\nNo definitions of '%s' were found\n", resolution.CommandName)
+		return builder.String()
+	}
+
+	fmt.Fprintf(&builder, "\nDefinitions of '%s' (in precedence order):\n", resolution.CommandName)
+	for i, definition := range resolution.Definitions {
+		status := "shadowed"
+		if i == 0 {
+			status = "used"
+		}
+
+		fmt.Fprintf(&builder, "  %d. %s  (%s)\n", i+1, definition.ScaffFilePath, status)
+		fmt.Fprintf(&builder, "     template directory: %s\n", definition.FullTemplatePath)
+	}
+
+	return builder.String()
+}
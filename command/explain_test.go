@@ -0,0 +1,123 @@
+package command_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/command"
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+func TestExplainWillTraceEveryDirectoryFileAndDefinition(t *testing.T) {
+	listBeforeEach(map[string]models.ScaffFile{
+		"C:/project/app/scaff.json": {
+			Commands: []models.Command{{Name: "other"}},
+			Children: []string{"scaff_files/child.json"},
+		},
+		"C:/project/app/scaff_files/child.json": {
+			Commands: []models.Command{{Name: "service", TemplateDirectoryPath: "templates"}},
+		},
+		"C:/scaff.json": {
+			Commands: []models.Command{{Name: "service", TemplateDirectoryPath: "root_templates"}},
+		},
+	})
+
+	result, err := command.Explain("service", commandFileNameAndExt, "C:/project/app")
+	if err != nil {
+		t.Fatalf("expected no error. got '%s'", err.Error())
+	}
+
+	expectedDirectories := []models.CheckedDirectory{
+		{Path: "C:/project/app", HasScaffFile: true},
+		{Path: "C:/project", HasScaffFile: false},
+		{Path: "C:", HasScaffFile: true},
+	}
+	if !slices.Equal(result.CheckedDirectories, expectedDirectories) {
+		t.Errorf("expected the checked directories to be %v. got %v", expectedDirectories, result.CheckedDirectories)
+	}
+
+	expectedFiles := []models.ReadScaffFile{
+		{Path: "C:/project/app/scaff.json"},
+		{Path: "C:/project/app/scaff_files/child.json", ChildOf: "C:/project/app/scaff.json"},
+		{Path: "C:/scaff.json"},
+	}
+	if !slices.Equal(result.ReadFiles, expectedFiles) {
+		t.Errorf("expected the read files to be %v. got %v", expectedFiles, result.ReadFiles)
+	}
+
+	expectedDefinitions := []models.CommandDefinition{
+		{ScaffFilePath: "C:/project/app/scaff_files/child.json", FullTemplatePath: "C:/project/app/scaff_files/templates"},
+		{ScaffFilePath: "C:/scaff.json", FullTemplatePath: "C:/root_templates"},
+	}
+	if !slices.Equal(result.Definitions, expectedDefinitions) {
+		t.Errorf("expected the definitions to be %v. got %v", expectedDefinitions, result.Definitions)
+	}
+}
+
+func TestExplainWillReturnTheTraceSoFarWithAnError(t *testing.T) {
+	listBeforeEach(map[string]models.ScaffFile{
+		"C:/project/scaff.json": {Commands: []models.Command{{Name: "service"}}},
+		"C:/scaff.json":         {},
+	})
+
+	readFile := command.ReadFile
+	command.ReadFile = func(filePath string) ([]byte, error) {
+		if filePath == "C:/scaff.json" {
+			return []byte("{ invalid"), nil
+		}
+
+		return readFile(filePath)
+	}
+
+	result, err := command.Explain("service", commandFileNameAndExt, "C:/project")
+
+	var validationErr *customerrors.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected a ValidationError. got %v", err)
+	}
+
+	if len(result.ReadFiles) != 1 || len(result.Definitions) != 1 {
+		t.Errorf("expected the trace to include the first file. got %+v", result)
+	}
+}
+
+func TestFormatResolutionWillMarkTheUsedDefinition(t *testing.T) {
+	resolution := models.Resolution{
+		CommandName:        "service",
+		CheckedDirectories: []models.CheckedDirectory{{Path: "/project", HasScaffFile: true}, {Path: "/", HasScaffFile: false}},
+		ReadFiles:          []models.ReadScaffFile{{Path: "/project/scaff.json"}, {Path: "/project/child.json", ChildOf: "/project/scaff.json"}},
+		Definitions: []models.CommandDefinition{
+			{ScaffFilePath: "/project/scaff.json", FullTemplatePath: "/project/templates"},
+			{ScaffFilePath: "/project/child.json", FullTemplatePath: "/project/child_templates"},
+		},
+	}
+
+	expected := strings.Join([]string{
+		"Directories checked for a scaff file (nearest first):",
+		"  /project  (found)",
+		"  /  (not found)",
+		"",
+		"Scaff files read (in search order):",
+		"  /project/scaff.json",
+		"  /project/child.json  (child of /project/scaff.json)",
+		"",
+		"Definitions of 'service' (in precedence order):",
+		"  1. /project/scaff.json  (used)",
+		"     template directory: /project/templates",
+		"  2. /project/child.json  (shadowed)",
+		"     template directory: /project/child_templates",
+		"",
+	}, "\n")
+
+	if result := command.FormatResolution(resolution); result != expected {
+		t.Errorf("expected the trace:\n%s\ngot:\n%s", expected, result)
+	}
+
+	resolution.Definitions = []models.CommandDefinition{}
+	if result := command.FormatResolution(resolution); !strings.HasSuffix(result, "\nNo definitions of 'service' were found\n") {
+		t.Errorf("expected the trace to say that no definitions were found. got:\n%s", result)
+	}
+}
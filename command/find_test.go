@@ -546,3 +546,32 @@ func TestFindWillNotReturnValidationErrorIfNoChildrenArrayInChildScaffFile(t *te
 }
 
 // -----------------------------------------------------------------------------------
+
+// ---- Partials directories ---------------------------------------------------------
+
+func TestFindWillSetThePartialsDirectoryPathsFromTheChildAndParentScaffFiles(t *testing.T) {
+	findBeforeEach()
+
+	command.ReadFile = func(filePath string) ([]byte, error) {
+		var contents models.ScaffFile
+		if strings.HasSuffix(filePath, commandFileNameAndExt) {
+			contents = models.ScaffFile{Children: []string{"children/child.json"}, PartialsDirectoryPath: "shared"}
+		} else {
+			contents = models.ScaffFile{Commands: []models.Command{commandToFind}, PartialsDirectoryPath: "../child_partials"}
+		}
+
+		fileContents, _ := json.Marshal(contents)
+		return fileContents, nil
+	}
+
+	result, _, _, err := command.Find(commandToFind.Name, commandFileNameAndExt, "C:/my_location")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expectedPaths := []string{"C:/my_location/child_partials", "C:/my_location/shared"}
+	if strings.Join(result.PartialsDirectoryPaths, "|") != strings.Join(expectedPaths, "|") {
+		t.Errorf("expected the partials directory paths to be %v. got %v", expectedPaths, result.PartialsDirectoryPaths)
+	}
+}
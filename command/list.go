@@ -0,0 +1,75 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+// List moves up the directory tree structure (from the given "currentPath") in the same way as Find, and returns every
+// command in the files (with the given "fileNameAndExt") that it finds, in the order that they are searched. If more than
+// one command has the same name, only the first can be found, so the others are marked as shadowed by it.
+func List(fileNameAndExt, currentPath string) ([]models.CommandListing, error) {
+	listings := []models.CommandListing{}
+	firstPaths := make(map[string]string) // The scaff file path of the first command with each name
+
+	err := walkScaffFiles(fileNameAndExt, currentPath, func(file walkedScaffFile) bool {
+		for _, command := range file.scaffFile.Commands {
+			listing := models.CommandListing{
+				Name:          command.Name,
+				Description:   command.Description,
+				ScaffFilePath: file.filePath,
+			}
+
+			if firstPath, isFound := firstPaths[command.Name]; isFound {
+				listing.IsShadowed, listing.ShadowedBy = true, firstPath
+			} else {
+				firstPaths[command.Name] = file.filePath
+			}
+
+			listings = append(listings, listing)
+		}
+
+		return false
+	})
+
+	if err != nil {
+		return []models.CommandListing{}, err
+	}
+
+	return listings, nil
+}
+
+// FormatList returns the given listings as a printable table (with paths relative to the given workingDirectory)
+func FormatList(listings []models.CommandListing, workingDirectory string) string {
+	var builder strings.Builder
+	writer := tabwriter.NewWriter(&builder, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(writer, "NAME\tDESCRIPTION\tSCAFF FILE")
+	for _, listing := range listings {
+		scaffFile := relativePath(listing.ScaffFilePath, workingDirectory)
+		if listing.IsShadowed {
+			scaffFile += " (shadowed by " + relativePath(listing.ShadowedBy, workingDirectory) + ")"
+		}
+
+		// Descriptions are kept to a single line (so they fit in the table)
+		description := strings.Join(strings.Fields(listing.Description), " ")
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", listing.Name, description, scaffFile)
+	}
+
+	writer.Flush()
+	return builder.String()
+}
+
+// relativePath returns the given path relative to the workingDirectory (or the path as it is, if it can't be made relative)
+func relativePath(fullPath, workingDirectory string) string {
+	relPath, relErr := filepath.Rel(workingDirectory, fullPath)
+	if relErr != nil {
+		return fullPath
+	}
+
+	return filepath.ToSlash(relPath)
+}
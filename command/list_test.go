@@ -0,0 +1,126 @@
+package command_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/command"
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+// listBeforeEach mocks the given scaff files (keyed by their paths). Any other path doesn't exist.
+func listBeforeEach(files map[string]models.ScaffFile) {
+	command.CurrentOS = "windows"
+
+	command.FileStat = func(filePath string) (fs.FileInfo, error) {
+		if _, fileExists := files[filePath]; fileExists {
+			return nil, nil
+		}
+
+		return nil, fs.ErrNotExist
+	}
+
+	command.ReadFile = func(filePath string) ([]byte, error) {
+		contents, fileExists := files[filePath]
+		if !fileExists {
+			return nil, fmt.Errorf("An unexpected path was provided to ReadFile: %s", filePath)
+		}
+
+		return json.Marshal(contents)
+	}
+}
+
+func TestListWillReturnEveryCommandInSearchOrderWithShadowedCommandsMarked(t *testing.T) {
+	listBeforeEach(map[string]models.ScaffFile{
+		"C:/project/scaff.json": {
+			Commands: []models.Command{{Name: "service", Description: "Creates a service"}},
+			Children: []string{"scaff_files/child.json"},
+		},
+		"C:/project/scaff_files/child.json": {
+			Commands: []models.Command{{Name: "model"}, {Name: "service"}},
+		},
+		"C:/scaff.json": {
+			Commands: []models.Command{{Name: "service"}, {Name: "readme", Description: "Creates a README"}},
+		},
+	})
+
+	results, err := command.List(commandFileNameAndExt, "C:/project")
+	if err != nil {
+		t.Fatalf("expected no error. got '%s'", err.Error())
+	}
+
+	expected := []models.CommandListing{
+		{Name: "service", Description: "Creates a service", ScaffFilePath: "C:/project/scaff.json"},
+		{Name: "model", ScaffFilePath: "C:/project/scaff_files/child.json"},
+		{Name: "service", ScaffFilePath: "C:/project/scaff_files/child.json", IsShadowed: true, ShadowedBy: "C:/project/scaff.json"},
+		{Name: "service", ScaffFilePath: "C:/scaff.json", IsShadowed: true, ShadowedBy: "C:/project/scaff.json"},
+		{Name: "readme", Description: "Creates a README", ScaffFilePath: "C:/scaff.json"},
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d commands. got %d: %v", len(expected), len(results), results)
+	}
+
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("expected command %d to be %+v. got %+v", i, expected[i], results[i])
+		}
+	}
+}
+
+func TestListWillOnlyVisitEachScaffFileOnce(t *testing.T) {
+	listBeforeEach(map[string]models.ScaffFile{
+		"C:/project/scaff.json": {
+			Commands: []models.Command{{Name: "service"}},
+			Children: []string{"../scaff.json"},
+		},
+		"C:/scaff.json": {
+			Commands: []models.Command{{Name: "readme"}},
+			Children: []string{"project/scaff.json"},
+		},
+	})
+
+	results, err := command.List(commandFileNameAndExt, "C:/project")
+	if err != nil {
+		t.Fatalf("expected no error. got '%s'", err.Error())
+	}
+
+	if len(results) != 2 || results[0].Name != "service" || results[1].Name != "readme" {
+		t.Errorf("expected each command to be listed once. got %v", results)
+	}
+}
+
+func TestListWillReturnValidationErrorForInvalidScaffFiles(t *testing.T) {
+	listBeforeEach(map[string]models.ScaffFile{"C:/scaff.json": {}})
+	command.ReadFile = func(filePath string) ([]byte, error) {
+		return []byte("{ invalid"), nil
+	}
+
+	_, err := command.List(commandFileNameAndExt, "C:/project")
+
+	var validationErr *customerrors.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected a ValidationError. got %v", err)
+	}
+}
+
+func TestFormatListWillReturnTableOfCommands(t *testing.T) {
+	listings := []models.CommandListing{
+		{Name: "service", Description: "Creates a\nservice", ScaffFilePath: "/project/scaff.json"},
+		{Name: "model", ScaffFilePath: "/project/scaff_files/child.json"},
+		{Name: "service", ScaffFilePath: "/scaff.json", IsShadowed: true, ShadowedBy: "/project/scaff.json"},
+	}
+
+	expected := "NAME      DESCRIPTION         SCAFF FILE\n" +
+		"service   Creates a service   scaff.json\n" +
+		"model                         scaff_files/child.json\n" +
+		"service                       ../scaff.json (shadowed by scaff.json)\n"
+
+	if result := command.FormatList(listings, "/project"); result != expected {
+		t.Errorf("expected the table:\n%s\ngot:\n%s", expected, result)
+	}
+}
@@ -0,0 +1,339 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/models"
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+// Plan builds the list of directories/files that the given command will generate (in the order they should be created).
+// Nothing is written to the filesystem, however the file templates are read and populated. Files/directories with a
+// "forEach" are included once for each item in their list, and those with a "when" condition are only included if it is
+// true. If more than one file would be created at the same path (e.g. if a forEach list has repeated items), a
+// ValidationError is returned.
+// Populated names can contain path separators (e.g. "pkg/handlers/file.go"), in which case the intermediate directories are
+// also added to the plan. Names that are populated as absolute paths, or that contain ".." segments, cause a validation
+// error, unless allowOutsideRoot is true (in which case, they are resolved, and nothing stops the planned paths escaping the
+// workingDirectory).
+// The workingDirectory is the path to the current working directory
+// The fullTemplatesDirectoryPath is the path to the directory that contains templates for files.
+// The vars is a map of variables that may be needed to populate the directory/file names, and file contents.
+// Even if allowOutsideRoot is false, symbolic links can still lead outside of the workingDirectory (see CheckContainment).
+func Plan(command models.Command, workingDirectory, fullTemplatesDirectoryPath string, vars map[string]string, allowOutsideRoot bool) ([]models.PlanItem, error) {
+	// Files/directories are repeated for their forEach lists, and those whose "when" conditions are false aren't part of the plan
+	command, expandErr := command.Expand(vars)
+	if expandErr != nil {
+		return []models.PlanItem{}, expandErr
+	}
+
+	p := &planner{
+		fullTemplatesDirectoryPath: fullTemplatesDirectoryPath,
+		command:                    command,
+		loadPartial:                command.PartialLoader(fullTemplatesDirectoryPath),
+		vars:                       vars,
+		allowOutsideRoot:           allowOutsideRoot,
+		plannedDirectories:         make(map[string]bool),
+		plannedFiles:               make(map[string]bool),
+	}
+
+	for _, file := range command.Files {
+		if err := p.planFile(file, workingDirectory); err != nil {
+			return p.items, err
+		}
+	}
+
+	for _, directory := range command.Directories {
+		if err := p.planDirectory(directory, workingDirectory); err != nil {
+			return p.items, err
+		}
+	}
+
+	return p.items, nil
+}
+
+// planner holds the state used while building a plan
+type planner struct {
+	fullTemplatesDirectoryPath string
+	command                    models.Command         // The command being planned (its options are used to compile names and templates)
+	loadPartial                variable.PartialLoader // Used to load the partials that templates include
+	vars                       map[string]string
+	allowOutsideRoot           bool              // If false, names can't be absolute paths, or contain ".." segments
+	items                      []models.PlanItem // The plan that has been built so far
+	plannedDirectories         map[string]bool   // The paths of the directories already in the plan
+	plannedFiles               map[string]bool   // The paths of the files already in the plan
+}
+
+// planFile adds a PlanItem for a file to the plan (populating its name and contents, with its loop variables). If the file
+// is a raw copy (see FileScaffold.IsRawCopy), its contents are copied from the template as they are.
+func (p *planner) planFile(file models.FileScaffold, parentDirectoryPath string) error {
+	return variable.WithLoopVars(p.vars, file.LoopVars, func() error {
+		return p.planFileWithVars(file, parentDirectoryPath)
+	})
+}
+
+// planFileWithVars adds a PlanItem for a file to the plan, once its loop variables have been added to the vars
+func (p *planner) planFileWithVars(file models.FileScaffold, parentDirectoryPath string) error {
+	// Load template
+	fullTemplatePath := file.GetFullTemplatePath(p.fullTemplatesDirectoryPath)
+	templateBytes, templateErr := ReadFile(fullTemplatePath)
+	if templateErr != nil {
+		return templateErr
+	}
+
+	planItem := models.PlanItem{TemplatePath: fullTemplatePath}
+
+	if file.IsRawCopy(templateBytes) {
+		// Raw copies keep the template's contents and permissions
+		templateInfo, statErr := FileStat(fullTemplatePath)
+		if statErr != nil {
+			return statErr
+		}
+
+		planItem.Contents = templateBytes
+		planItem.IsRaw = true
+		planItem.Mode = templateInfo.Mode().Perm()
+	} else {
+		// Compile the template, then populate it with variable values
+		template, compileErr := variable.CompileWithOptions(p.command.TemplateOptions(&file), fullTemplatePath, string(templateBytes))
+		if compileErr != nil {
+			return compileErr
+		}
+
+		var populatedTemplate bytes.Buffer
+		if renderErr := template.Render(&populatedTemplate, p.vars, p.loadPartial); renderErr != nil {
+			return renderErr
+		}
+
+		planItem.Contents = populatedTemplate.Bytes()
+	}
+
+	// Populate file name with variable values, and create the full file path for the new file
+	fullFilePath, pathErr := p.planPath(parentDirectoryPath, file.Name, p.command.TemplateOptions(&file))
+	if pathErr != nil {
+		return pathErr
+	}
+
+	if p.plannedFiles[fullFilePath] || p.plannedDirectories[fullFilePath] {
+		return &customerrors.ValidationError{
+			Message: fmt.Sprintf("more than one file/directory would be created at the path '%s' (from the file '%s')", fullFilePath, file.Name),
+		}
+	}
+
+	p.plannedFiles[fullFilePath] = true
+	planItem.Path = fullFilePath
+	p.items = append(p.items, planItem)
+
+	return nil
+}
+
+// planDirectory adds PlanItems for a directory, and the directories/files within it, to the plan
+func (p *planner) planDirectory(directory models.DirectoryScaffold, parentDirectoryPath string) error {
+	var fullDirPath string
+	pathErr := variable.WithLoopVars(p.vars, directory.LoopVars, func() error {
+		var err error
+		fullDirPath, err = p.planPath(parentDirectoryPath, directory.Name, p.command.TemplateOptions(nil))
+		return err
+	})
+	if pathErr != nil {
+		return pathErr
+	}
+
+	if p.plannedFiles[fullDirPath] {
+		return &customerrors.ValidationError{
+			Message: fmt.Sprintf("more than one file/directory would be created at the path '%s' (from the directory '%s')", fullDirPath, directory.Name),
+		}
+	}
+	p.addDirectory(fullDirPath)
+
+	for _, file := range directory.Files {
+		if err := p.planFile(file, fullDirPath); err != nil {
+			return err
+		}
+	}
+
+	for _, innerDirectory := range directory.Directories {
+		if err := p.planDirectory(innerDirectory, fullDirPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// planPath populates the given name (compiled with the given options), and returns its full path (when joined to the parent directory path).
+// If the populated name contains path separators, the intermediate directories are added to the plan.
+// Any ".." segments are resolved, and a name that is populated as an absolute path is used as it is (see CheckContainment,
+// which is used to stop such paths escaping the output directory).
+func (p *planner) planPath(parentDirectoryPath, name string, options variable.Options) (string, error) {
+	populatedName, populateErr := variable.PopulateWithOptions(options, name, p.vars)
+	if populateErr != nil {
+		return "", populateErr
+	}
+
+	nameParts := strings.FieldsFunc(populatedName, func(r rune) bool {
+		return r == '/' || r == filepath.Separator
+	})
+
+	if !p.allowOutsideRoot {
+		if isAbsolutePath(populatedName) {
+			return "", &customerrors.ValidationError{
+				Message: fmt.Sprintf("the name '%s' is populated as an absolute path ('%s'), which is not allowed (unless '--allow-outside-root' is given)", name, populatedName),
+			}
+		}
+
+		if slices.Contains(nameParts, "..") {
+			return "", &customerrors.ValidationError{
+				Message: fmt.Sprintf("the name '%s' is populated with a '..' segment ('%s'), which is not allowed (unless '--allow-outside-root' is given)", name, populatedName),
+			}
+		}
+	}
+
+	nameParts = resolveNameParts(nameParts)
+
+	if len(nameParts) == 0 {
+		return "", &customerrors.ValidationError{
+			Message: fmt.Sprintf("the name '%s' is populated as an empty path ('%s'), which is not allowed", name, populatedName),
+		}
+	}
+
+	fullPath := parentDirectoryPath
+	if isAbsolutePath(populatedName) {
+		fullPath = filepath.VolumeName(populatedName) + "/"
+		nameParts = slices.DeleteFunc(nameParts, func(namePart string) bool {
+			return namePart == filepath.VolumeName(populatedName)
+		})
+	}
+
+	for i, namePart := range nameParts {
+		if namePart == ".." {
+			fullPath = path.Dir(fullPath)
+			continue
+		}
+
+		fullPath = path.Join(fullPath, namePart)
+
+		if i < len(nameParts)-1 {
+			p.addDirectory(fullPath)
+		}
+	}
+
+	return fullPath, nil
+}
+
+// resolveNameParts removes any "." segments from the given name parts, and resolves any ".." segments (so the only ".."
+// segments left are at the start)
+func resolveNameParts(nameParts []string) []string {
+	resolvedParts := []string{}
+	for _, namePart := range nameParts {
+		switch {
+		case namePart == ".":
+			continue
+		case namePart == ".." && len(resolvedParts) > 0 && resolvedParts[len(resolvedParts)-1] != "..":
+			resolvedParts = resolvedParts[:len(resolvedParts)-1]
+		default:
+			resolvedParts = append(resolvedParts, namePart)
+		}
+	}
+
+	return resolvedParts
+}
+
+// addDirectory adds a PlanItem for the directory to the plan (if it hasn't already been added)
+func (p *planner) addDirectory(fullDirPath string) {
+	if p.plannedDirectories[fullDirPath] {
+		return
+	}
+
+	p.plannedDirectories[fullDirPath] = true
+	p.items = append(p.items, models.PlanItem{
+		Path:        fullDirPath,
+		IsDirectory: true,
+	})
+}
+
+// isAbsolutePath identifies if the given path is absolute (on any operating system)
+func isAbsolutePath(pathToCheck string) bool {
+	return strings.HasPrefix(pathToCheck, "/") ||
+		strings.HasPrefix(pathToCheck, "\\") ||
+		filepath.IsAbs(pathToCheck) ||
+		filepath.VolumeName(pathToCheck) != ""
+}
+
+// planTreeNode is a directory/file in the tree that is output by FormatPlan
+type planTreeNode struct {
+	name     string
+	item     *models.PlanItem
+	children []*planTreeNode
+}
+
+// FormatPlan returns the given plan as a printable tree (with paths relative to the given workingDirectory).
+// Each file in the tree is followed by the path to the template it will be populated from (or copied from, for raw copies).
+func FormatPlan(items []models.PlanItem, workingDirectory string) string {
+	root := &planTreeNode{name: "."}
+
+	for i := range items {
+		relativePath, relErr := filepath.Rel(workingDirectory, items[i].Path)
+		if relErr != nil {
+			relativePath = items[i].Path
+		}
+
+		// Find (or create) each node on the way down to this item
+		node := root
+		for _, pathPart := range strings.Split(filepath.ToSlash(relativePath), "/") {
+			node = node.child(pathPart)
+		}
+
+		node.item = &items[i]
+	}
+
+	var builder strings.Builder
+	builder.WriteString(".\n")
+	root.writeChildren(&builder, "")
+
+	return builder.String()
+}
+
+// child returns the child node with the given name (creating it, if it doesn't already exist)
+func (node *planTreeNode) child(name string) *planTreeNode {
+	for _, existingChild := range node.children {
+		if existingChild.name == name {
+			return existingChild
+		}
+	}
+
+	newChild := &planTreeNode{name: name}
+	node.children = append(node.children, newChild)
+	return newChild
+}
+
+// writeChildren writes a line for each of the node's children (and their children) to the builder.
+// The indent is written before each line.
+func (node *planTreeNode) writeChildren(builder *strings.Builder, indent string) {
+	for i, child := range node.children {
+		isLast := i == len(node.children)-1
+
+		branch, childIndent := "├── ", "│   "
+		if isLast {
+			branch, childIndent = "└── ", "    "
+		}
+
+		builder.WriteString(indent + branch + child.name)
+		if child.item == nil || child.item.IsDirectory {
+			builder.WriteString("/")
+		} else if child.item.IsRaw {
+			builder.WriteString("  (copy of: " + child.item.TemplatePath + ")")
+		} else {
+			builder.WriteString("  (template: " + child.item.TemplatePath + ")")
+		}
+		builder.WriteString("\n")
+
+		child.writeChildren(builder, indent+childIndent)
+	}
+}
@@ -0,0 +1,658 @@
+package command_test
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/command"
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/mocks"
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+// setup runs any setup code that is generic across all tests for the plan func
+func planBeforeEach() {
+	command.ReadFile = mocks.GetReadFile([]byte{})
+}
+
+func TestPlanWillListTheDirectoryAndFileStructureInCreationOrder(t *testing.T) {
+	planBeforeEach()
+
+	testCommand := models.Command{
+		Name: "test",
+		Files: []models.FileScaffold{
+			{
+				Name:         "file1",
+				TemplatePath: "template1.txt",
+			},
+		},
+		Directories: []models.DirectoryScaffold{
+			{
+				Name: "mainDir",
+				Files: []models.FileScaffold{
+					{
+						Name:         "file2",
+						TemplatePath: "template2.txt",
+					},
+				},
+				Directories: []models.DirectoryScaffold{
+					{
+						Name: "dir1",
+						Files: []models.FileScaffold{
+							{
+								Name:         "file3",
+								TemplatePath: "template3.txt",
+							},
+						},
+					},
+					{
+						Name: "dir2",
+					},
+				},
+			},
+		},
+	}
+
+	parentDirPath := "C:/parent"
+
+	expectedItems := []models.PlanItem{
+		{Path: parentDirPath + "/file1", TemplatePath: "C:/templates/template1.txt"},
+		{Path: parentDirPath + "/mainDir", IsDirectory: true},
+		{Path: parentDirPath + "/mainDir/file2", TemplatePath: "C:/templates/template2.txt"},
+		{Path: parentDirPath + "/mainDir/dir1", IsDirectory: true},
+		{Path: parentDirPath + "/mainDir/dir1/file3", TemplatePath: "C:/templates/template3.txt"},
+		{Path: parentDirPath + "/mainDir/dir2", IsDirectory: true},
+	}
+
+	results, err := command.Plan(testCommand, parentDirPath, "C:/templates", map[string]string{}, false)
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if len(results) != len(expectedItems) {
+		t.Errorf("expected %d items in the plan. Got %d", len(expectedItems), len(results))
+		return
+	}
+
+	for i, expectedItem := range expectedItems {
+		result := results[i]
+
+		if result.Path != expectedItem.Path {
+			t.Errorf("expected item %d to have the path '%s'. Got '%s'", i, expectedItem.Path, result.Path)
+		}
+
+		if result.IsDirectory != expectedItem.IsDirectory {
+			t.Errorf("expected item %d to have IsDirectory set to %v. Got %v", i, expectedItem.IsDirectory, result.IsDirectory)
+		}
+
+		if result.TemplatePath != expectedItem.TemplatePath {
+			t.Errorf("expected item %d to have the template path '%s'. Got '%s'", i, expectedItem.TemplatePath, result.TemplatePath)
+		}
+	}
+}
+
+func TestPlanWillPopulateDirectoryAndFileNamesWithGivenVars(t *testing.T) {
+	planBeforeEach()
+
+	vars := map[string]string{
+		"var1": "val1",
+		"var2": "val2",
+	}
+
+	testCommand := models.Command{
+		Name: "test",
+		Directories: []models.DirectoryScaffold{
+			{
+				Name: "test {: var1 :}",
+				Files: []models.FileScaffold{
+					{
+						Name:         "My-{: var1 :}-New-{: var2 :}-File",
+						TemplatePath: "MyTemplate.txt",
+					},
+				},
+			},
+		},
+	}
+
+	expectedPaths := []string{
+		"/test val1",
+		"/test val1/My-val1-New-val2-File",
+	}
+
+	results, err := command.Plan(testCommand, "/", "/", vars, false)
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if len(results) != len(expectedPaths) {
+		t.Errorf("expected %d items in the plan. Got %d", len(expectedPaths), len(results))
+		return
+	}
+
+	for i, expectedPath := range expectedPaths {
+		if results[i].Path != expectedPath {
+			t.Errorf("expected item %d to have the path '%s'. Got '%s'", i, expectedPath, results[i].Path)
+		}
+	}
+}
+
+func TestPlanWillReadTheTemplateFileWithTheCorrectFilePath(t *testing.T) {
+	planBeforeEach()
+
+	testCommand := models.Command{
+		Name: "test",
+		Files: []models.FileScaffold{
+			{
+				Name:         "MyNewFile",
+				TemplatePath: "MyInnerTemplateDir/MyTemplate.txt",
+			},
+		},
+	}
+	expectedFilePath := "C:/myTemplatePath/MyInnerTemplateDir/MyTemplate.txt"
+
+	// We'll capture the path that ReadFile was called with
+	var resultFilePath string
+	command.ReadFile = func(filePath string) ([]byte, error) {
+		resultFilePath = filePath
+		return []byte{}, nil
+	}
+
+	command.Plan(testCommand, "C:/", "C:/myTemplatePath", map[string]string{}, false)
+
+	if resultFilePath != expectedFilePath {
+		t.Errorf("file path should have been '%s'. Got '%s'", expectedFilePath, resultFilePath)
+	}
+}
+
+func TestPlanWillPopulateFileContentsWithGivenVars(t *testing.T) {
+	planBeforeEach()
+
+	command.ReadFile = mocks.GetReadFile([]byte("{: var1 :} - {: var2 :}"))
+
+	testCommand := models.Command{
+		Name: "test",
+		Files: []models.FileScaffold{
+			{
+				Name:         "MyNewFile",
+				TemplatePath: "MyTemplate.txt",
+			},
+		},
+	}
+
+	vars := map[string]string{
+		"var1": "value1",
+		"var2": "value2",
+	}
+
+	results, err := command.Plan(testCommand, "C:/", "/", vars, false)
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	expectedFileContents := "value1 - value2"
+
+	if len(results) != 1 {
+		t.Errorf("expected 1 item in the plan. Got %d", len(results))
+		return
+	}
+
+	if string(results[0].Contents) != expectedFileContents {
+		t.Errorf("expected file contents to be '%s'. Got '%s'", expectedFileContents, string(results[0].Contents))
+	}
+}
+
+func TestPlanWillReturnErrorFromReadFileWhenReadingTheTemplateFile(t *testing.T) {
+	planBeforeEach()
+
+	expectedErrorText := "my test error 123"
+	command.ReadFile = func(filePath string) ([]byte, error) {
+		return nil, errors.New(expectedErrorText)
+	}
+
+	testCommand := models.Command{
+		Name: "test",
+		Directories: []models.DirectoryScaffold{
+			{
+				Name: "test",
+				Files: []models.FileScaffold{
+					{
+						Name:         "MyNewFile",
+						TemplatePath: "MyTemplate.txt",
+					},
+				},
+			},
+		},
+	}
+
+	_, err := command.Plan(testCommand, "", "", map[string]string{}, false)
+
+	if err == nil {
+		t.Errorf("expected an error when reading template file, but got nil")
+		return
+	}
+
+	if err.Error() != expectedErrorText {
+		t.Errorf("expected template read error to be '%s'. Got '%s'", expectedErrorText, err.Error())
+	}
+}
+
+func TestFormatPlanWillReturnTreeOfRelativePaths(t *testing.T) {
+	items := []models.PlanItem{
+		{Path: "/project/file1.txt", TemplatePath: "/templates/template1.txt"},
+		{Path: "/project/dir1", IsDirectory: true},
+		{Path: "/project/dir1/file2.txt", TemplatePath: "/templates/template2.txt"},
+		{Path: "/project/dir1/dir2", IsDirectory: true},
+		{Path: "/project/dir3", IsDirectory: true},
+		{Path: "/project/dir3/logo.png", TemplatePath: "/templates/logo.png", IsRaw: true},
+	}
+
+	expectedLines := []string{
+		".",
+		"├── file1.txt  (template: /templates/template1.txt)",
+		"├── dir1/",
+		"│   ├── file2.txt  (template: /templates/template2.txt)",
+		"│   └── dir2/",
+		"└── dir3/",
+		"    └── logo.png  (copy of: /templates/logo.png)",
+	}
+
+	result := command.FormatPlan(items, "/project")
+	resultLines := strings.Split(strings.TrimSuffix(result, "\n"), "\n")
+
+	if len(resultLines) != len(expectedLines) {
+		t.Errorf("expected %d lines. Got %d:\n%s", len(expectedLines), len(resultLines), result)
+		return
+	}
+
+	for i, expectedLine := range expectedLines {
+		if resultLines[i] != expectedLine {
+			t.Errorf("expected line %d to be '%s'. Got '%s'", i, expectedLine, resultLines[i])
+		}
+	}
+}
+
+func TestPlanWillAddIntermediateDirectoriesForNamesWithPathSeparators(t *testing.T) {
+	planBeforeEach()
+
+	vars := map[string]string{
+		"pkg":  "billing/invoices",
+		"name": "invoice",
+	}
+
+	testCommand := models.Command{
+		Name: "test",
+		Files: []models.FileScaffold{
+			{
+				Name:         "{: pkg :}/handlers/{: name :}.go",
+				TemplatePath: "handler.txt",
+			},
+			{
+				Name:         "{: pkg :}/{: name :}.go",
+				TemplatePath: "model.txt",
+			},
+		},
+		Directories: []models.DirectoryScaffold{
+			{
+				Name: "{: pkg :}/docs",
+			},
+		},
+	}
+
+	expectedItems := []models.PlanItem{
+		{Path: "/project/billing", IsDirectory: true},
+		{Path: "/project/billing/invoices", IsDirectory: true},
+		{Path: "/project/billing/invoices/handlers", IsDirectory: true},
+		{Path: "/project/billing/invoices/handlers/invoice.go"},
+		{Path: "/project/billing/invoices/invoice.go"},
+		{Path: "/project/billing/invoices/docs", IsDirectory: true},
+	}
+
+	results, err := command.Plan(testCommand, "/project", "/templates", vars, false)
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if len(results) != len(expectedItems) {
+		t.Errorf("expected %d items in the plan. Got %d: %v", len(expectedItems), len(results), results)
+		return
+	}
+
+	for i, expectedItem := range expectedItems {
+		if results[i].Path != expectedItem.Path || results[i].IsDirectory != expectedItem.IsDirectory {
+			t.Errorf(
+				"expected item %d to be '%s' (directory: %v). Got '%s' (directory: %v)",
+				i,
+				expectedItem.Path,
+				expectedItem.IsDirectory,
+				results[i].Path,
+				results[i].IsDirectory,
+			)
+		}
+	}
+}
+
+func TestPlanWillReturnValidationErrorForInvalidPopulatedNames(t *testing.T) {
+	invalidValues := []string{
+		"../outside",
+		"inner/../../outside",
+		"inner/../other",
+		"/absolute/path",
+		"",
+		"./.",
+	}
+
+	for _, invalidValue := range invalidValues {
+		planBeforeEach()
+
+		testCommand := models.Command{
+			Name: "test",
+			Directories: []models.DirectoryScaffold{
+				{
+					Name: "{: name :}",
+				},
+			},
+		}
+
+		_, err := command.Plan(testCommand, "/project", "/templates", map[string]string{"name": invalidValue}, false)
+		if err == nil {
+			t.Errorf("expected an error for the value '%s'. Got nil", invalidValue)
+			continue
+		}
+
+		var validationErr *customerrors.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("expected a ValidationError for the value '%s'. Got '%s'", invalidValue, err.Error())
+		}
+	}
+}
+
+func TestPlanWillReturnValidationErrorForEmptyPopulatedNames(t *testing.T) {
+	emptyValues := []string{
+		"",
+		"./.",
+		"inner/..",
+	}
+
+	for _, emptyValue := range emptyValues {
+		planBeforeEach()
+
+		testCommand := models.Command{
+			Name: "test",
+			Directories: []models.DirectoryScaffold{
+				{
+					Name: "{: name :}",
+				},
+			},
+		}
+
+		_, err := command.Plan(testCommand, "/project", "/templates", map[string]string{"name": emptyValue}, true)
+		if err == nil {
+			t.Errorf("expected an error for the value '%s'. Got nil", emptyValue)
+			continue
+		}
+
+		var validationErr *customerrors.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("expected a ValidationError for the value '%s'. Got '%s'", emptyValue, err.Error())
+		}
+	}
+}
+
+func TestPlanWillResolveParentSegmentsAndAbsolutePathsInPopulatedNamesIfOutsideRootIsAllowed(t *testing.T) {
+	valuesToTest := map[string]string{
+		"inner/../other":      "/project/other",
+		"../outside":          "/outside",
+		"inner/../../outside": "/outside",
+		"/absolute/path":      "/absolute/path",
+	}
+
+	for value, expectedPath := range valuesToTest {
+		planBeforeEach()
+
+		testCommand := models.Command{
+			Name: "test",
+			Files: []models.FileScaffold{
+				{
+					Name:         "{: name :}",
+					TemplatePath: "template.txt",
+				},
+			},
+		}
+
+		results, err := command.Plan(testCommand, "/project", "/templates", map[string]string{"name": value}, true)
+		if err != nil {
+			t.Errorf("expected no error for the value '%s'. Got '%s'", value, err.Error())
+			continue
+		}
+
+		if len(results) == 0 || results[len(results)-1].Path != expectedPath {
+			t.Errorf("expected the value '%s' to be planned at '%s'. Got %v", value, expectedPath, results)
+		}
+	}
+}
+
+func TestPlanWillPopulateNamesAndContentsWithTheCommandsEngine(t *testing.T) {
+	planBeforeEach()
+
+	command.ReadFile = mocks.GetReadFile([]byte("{{ .var1 | pascal }} - {: var2 :}"))
+
+	testCommand := models.Command{
+		Name:   "test",
+		Engine: "gotemplate",
+		Files: []models.FileScaffold{
+			{
+				Name:         "{{ .var1 | snake }}.txt",
+				TemplatePath: "MyTemplate.txt",
+			},
+		},
+	}
+
+	vars := map[string]string{"var1": "my value"}
+
+	results, err := command.Plan(testCommand, "C:/parent", "/", vars, false)
+	if err != nil {
+		t.Fatalf("expected no error. Got '%s'", err.Error())
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("expected 1 item in the plan. Got %d", len(results))
+	}
+
+	expectedPath := "C:/parent/my_value.txt"
+	if results[0].Path != expectedPath {
+		t.Errorf("expected path to be '%s'. Got '%s'", expectedPath, results[0].Path)
+	}
+
+	expectedFileContents := "MyValue - {: var2 :}"
+	if string(results[0].Contents) != expectedFileContents {
+		t.Errorf("expected file contents to be '%s'. Got '%s'", expectedFileContents, string(results[0].Contents))
+	}
+}
+
+func TestPlanWillUseTheCommandsDelimitersUnlessTheFileOverridesThem(t *testing.T) {
+	planBeforeEach()
+
+	command.ReadFile = mocks.GetReadFile([]byte("{: var1 :} <% var1 %> [[ var1 ]]"))
+
+	testCommand := models.Command{
+		Name:       "test",
+		Delimiters: []string{"<%", "%>"},
+		Files: []models.FileScaffold{
+			{Name: "<% var1 %>.txt", TemplatePath: "first.txt"},
+			{Name: "[[ var1 ]]_2.txt", TemplatePath: "second.txt", Delimiters: []string{"[[", "]]"}},
+		},
+		Directories: []models.DirectoryScaffold{
+			{Name: "<% var1 %>_dir"},
+		},
+	}
+
+	results, err := command.Plan(testCommand, "C:/parent", "/", map[string]string{"var1": "value"}, false)
+	if err != nil {
+		t.Fatalf("expected no error. Got '%s'", err.Error())
+	}
+
+	expectedItems := []models.PlanItem{
+		{Path: "C:/parent/value.txt", Contents: []byte("{: var1 :} value [[ var1 ]]")},
+		{Path: "C:/parent/value_2.txt", Contents: []byte("{: var1 :} <% var1 %> value")},
+		{Path: "C:/parent/value_dir", IsDirectory: true},
+	}
+
+	if len(results) != len(expectedItems) {
+		t.Fatalf("expected %d items in the plan. Got %d", len(expectedItems), len(results))
+	}
+
+	for i, expectedItem := range expectedItems {
+		if results[i].Path != expectedItem.Path || string(results[i].Contents) != string(expectedItem.Contents) {
+			t.Errorf("expected item %d to have the path '%s' and contents '%s'. Got '%s' and '%s'",
+				i, expectedItem.Path, expectedItem.Contents, results[i].Path, results[i].Contents)
+		}
+	}
+}
+
+func TestPlanWillCopyRawAndBinaryTemplatesWithoutPopulatingThem(t *testing.T) {
+	planBeforeEach()
+
+	binaryContents := []byte("\x89PNG\x00{: var1 :}\xff")
+	command.ReadFile = func(filePath string) ([]byte, error) {
+		if strings.HasSuffix(filePath, ".png") {
+			return binaryContents, nil
+		}
+
+		return []byte("{: var1 :}"), nil
+	}
+
+	command.FileStat = mocks.GetFileStat([]mocks.MockFileInfo{
+		{FilePath: "/templates/script.sh", ModeValue: 0755},
+		{FilePath: "/templates/image.png", ModeValue: fs.ModeSetuid | 0640},
+	})
+
+	testCommand := models.Command{
+		Name: "test",
+		Files: []models.FileScaffold{
+			{Name: "{: var1 :}.sh", TemplatePath: "script.sh", Raw: true},
+			{Name: "{: var1 :}.png", TemplatePath: "image.png"},
+			{Name: "{: var1 :}.txt", TemplatePath: "text.txt"},
+		},
+	}
+
+	results, err := command.Plan(testCommand, "/parent", "/templates", map[string]string{"var1": "value"}, false)
+	if err != nil {
+		t.Fatalf("expected no error. Got '%s'", err.Error())
+	}
+
+	expectedItems := []models.PlanItem{
+		{Path: "/parent/value.sh", Contents: []byte("{: var1 :}"), IsRaw: true, Mode: 0755},
+		{Path: "/parent/value.png", Contents: binaryContents, IsRaw: true, Mode: 0640},
+		{Path: "/parent/value.txt", Contents: []byte("value")},
+	}
+
+	if len(results) != len(expectedItems) {
+		t.Fatalf("expected %d items in the plan. Got %d", len(expectedItems), len(results))
+	}
+
+	for i, expectedItem := range expectedItems {
+		result := results[i]
+		if result.Path != expectedItem.Path || !bytes.Equal(result.Contents, expectedItem.Contents) ||
+			result.IsRaw != expectedItem.IsRaw || result.Mode != expectedItem.Mode {
+			t.Errorf("expected item %d to be %+v. Got %+v", i, expectedItem, result)
+		}
+	}
+}
+
+func TestPlanWillSkipFilesAndDirectoriesWhoseConditionsAreFalse(t *testing.T) {
+	planBeforeEach()
+
+	testCommand := models.Command{
+		Name: "test",
+		Files: []models.FileScaffold{
+			{Name: "README.md", TemplatePath: "README.md"},
+			{Name: "Dockerfile", TemplatePath: "Dockerfile", When: "withDocker == 'true'"},
+		},
+		Directories: []models.DirectoryScaffold{
+			{Name: "docker", When: "withDocker", Files: []models.FileScaffold{{Name: "compose.yml", TemplatePath: "compose.yml"}}},
+			{Name: "src", Files: []models.FileScaffold{{Name: "{: name :}.go", TemplatePath: "main.go", When: "not withDocker"}}},
+		},
+	}
+
+	results, err := command.Plan(testCommand, "/parent", "/templates", map[string]string{"withDocker": "false", "name": "main"}, false)
+	if err != nil {
+		t.Fatalf("expected no error. Got '%s'", err.Error())
+	}
+
+	expectedPaths := []string{"/parent/README.md", "/parent/src", "/parent/src/main.go"}
+
+	if len(results) != len(expectedPaths) {
+		t.Fatalf("expected %d items in the plan. Got %d: %v", len(expectedPaths), len(results), results)
+	}
+
+	for i, expectedPath := range expectedPaths {
+		if results[i].Path != expectedPath {
+			t.Errorf("expected item %d to have the path '%s'. Got '%s'", i, expectedPath, results[i].Path)
+		}
+	}
+}
+
+func TestPlanWillRepeatFilesAndDirectoriesForEachItemInTheirLists(t *testing.T) {
+	planBeforeEach()
+
+	command.ReadFile = mocks.GetReadFile([]byte("type {: entity | pascal :} struct{} // {: entity_index :}"))
+
+	testCommand := models.Command{
+		Name: "test",
+		Directories: []models.DirectoryScaffold{
+			{
+				Name: "models",
+				Files: []models.FileScaffold{
+					{Name: "{: entity | snake :}.go", TemplatePath: "model.go", ForEach: "entities", As: "entity"},
+				},
+			},
+		},
+	}
+
+	results, err := command.Plan(testCommand, "/parent", "/templates", map[string]string{"entities": "User,OrderItem"}, false)
+	if err != nil {
+		t.Fatalf("expected no error. Got '%s'", err.Error())
+	}
+
+	expectedItems := []models.PlanItem{
+		{Path: "/parent/models", IsDirectory: true},
+		{Path: "/parent/models/user.go", Contents: []byte("type User struct{} // 0")},
+		{Path: "/parent/models/order_item.go", Contents: []byte("type OrderItem struct{} // 1")},
+	}
+
+	if len(results) != len(expectedItems) {
+		t.Fatalf("expected %d items in the plan. Got %d: %v", len(expectedItems), len(results), results)
+	}
+
+	for i, expectedItem := range expectedItems {
+		if results[i].Path != expectedItem.Path || string(results[i].Contents) != string(expectedItem.Contents) {
+			t.Errorf("expected item %d to have the path '%s' and contents '%s'. Got '%s' and '%s'",
+				i, expectedItem.Path, expectedItem.Contents, results[i].Path, results[i].Contents)
+		}
+	}
+}
+
+func TestPlanWillReturnValidationErrorIfMoreThanOneFileHasTheSamePath(t *testing.T) {
+	planBeforeEach()
+
+	testCommand := models.Command{
+		Name: "test",
+		Files: []models.FileScaffold{
+			{Name: "{: entity | snake :}.go", TemplatePath: "model.go", ForEach: "entities", As: "entity"},
+		},
+	}
+
+	_, err := command.Plan(testCommand, "/parent", "/templates", map[string]string{"entities": "User,user"}, false)
+
+	var validationErr *customerrors.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a ValidationError. Got %v", err)
+	}
+
+	if !strings.Contains(validationErr.Message, "'/parent/user.go'") {
+		t.Errorf("expected the error to contain the repeated path. Got '%s'", validationErr.Message)
+	}
+}
@@ -1,40 +1,45 @@
 package command
 
 import (
+	"fmt"
+
 	"github.com/M-Derbyshire/scaff/create"
 	"github.com/M-Derbyshire/scaff/models"
 )
 
 var (
-	// CreateFile is used to create files in the filesystem
-	CreateFile func(file models.FileScaffold, parentDirectoryPath, fullTemplatesDirectoryPath string, vars map[string]string) error
-	// CreateDirectory is used to create directories in the filesystem
-	CreateDirectory func(directory models.DirectoryScaffold, parentDirectoryPath, fullTemplatesDirectoryPath string, vars map[string]string) error
+	// CreateFile is used to create files in the filesystem (recording them in the given transaction)
+	CreateFile func(transaction *create.Transaction, file models.PlanItem) error
+	// CreateDirectory is used to create directories in the filesystem (recording them in the given transaction)
+	CreateDirectory func(transaction *create.Transaction, directory models.PlanItem) error
 )
 
 func init() {
-	CreateFile = create.File
-	CreateDirectory = create.Directory
+	CreateFile = (*create.Transaction).File
+	CreateDirectory = (*create.Transaction).Directory
 }
 
-// Process creates directories/files from the data in the given ScaffoldCommand.
-// The workingDirectory is the path to the current working directory
-// The fullTemplatesDirectoryPath is the path to the directory that contains templates for files.
-// The vars is a map of variables that may be needed to populate the directory/file names, and file contents.
-func Process(command models.Command, workingDirectory, fullTemplatesDirectoryPath string, vars map[string]string) error {
-	for _, file := range command.Files {
-		fileCreateErr := CreateFile(file, workingDirectory, fullTemplatesDirectoryPath, vars)
-		if fileCreateErr != nil {
-			return fileCreateErr
+// Process creates the directories/files in the given plan (see the Plan function), in the order they are given.
+// Everything is created within the given transaction. If any item fails to be created, the transaction is rolled back (so
+// nothing is left behind), otherwise it is committed.
+func Process(items []models.PlanItem, transaction *create.Transaction) error {
+	for _, item := range items {
+		var createErr error
+		if item.IsDirectory {
+			createErr = CreateDirectory(transaction, item)
+		} else {
+			createErr = CreateFile(transaction, item)
 		}
-	}
 
-	for _, directory := range command.Directories {
-		dirCreateErr := CreateDirectory(directory, workingDirectory, fullTemplatesDirectoryPath, vars)
-		if dirCreateErr != nil {
-			return dirCreateErr
+		if createErr != nil {
+			if rollbackErr := transaction.Rollback(); rollbackErr != nil {
+				return fmt.Errorf("%v (and failed to remove the partially generated paths: %v)", createErr, rollbackErr)
+			}
+
+			return createErr
 		}
 	}
 
+	transaction.Commit()
 	return nil
 }
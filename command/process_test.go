@@ -5,133 +5,87 @@ import (
 	"testing"
 
 	"github.com/M-Derbyshire/scaff/command"
+	"github.com/M-Derbyshire/scaff/create"
+	"github.com/M-Derbyshire/scaff/mocks"
 	"github.com/M-Derbyshire/scaff/models"
 )
 
-// This is used to store calls to the CreateFile or CreateDirectory mocks
-type mockCreateCall struct {
-	File         models.FileScaffold
-	Directory    models.DirectoryScaffold
-	ParentDir    string
-	TemplatesDir string
-	Vars         map[string]string
-}
-
-// Command for testing process
-var processTestCommand models.Command = models.Command{
-	Name: "testId1",
-	Files: []models.FileScaffold{
-		{
-			Name:         "file1.txt",
-			TemplatePath: "Template1.txt",
-		},
-		{
-			Name:         "file2.txt",
-			TemplatePath: "Template2.txt",
-		},
-		{
-			Name:         "file3.txt",
-			TemplatePath: "Template3.txt",
-		},
+// Plan for testing process
+var processTestPlan []models.PlanItem = []models.PlanItem{
+	{
+		Path:         "C:/project/file1.txt",
+		TemplatePath: "C:/project/templates/Template1.txt",
+		Contents:     []byte("file 1"),
+	},
+	{
+		Path:        "C:/project/dir1",
+		IsDirectory: true,
 	},
-	Directories: []models.DirectoryScaffold{
-		{
-			Name:        "dir1",
-			Files:       []models.FileScaffold{},
-			Directories: []models.DirectoryScaffold{},
-		},
-		{
-			Name:        "dir2",
-			Files:       []models.FileScaffold{},
-			Directories: []models.DirectoryScaffold{},
-		},
-		{
-			Name:        "dir3",
-			Files:       []models.FileScaffold{},
-			Directories: []models.DirectoryScaffold{},
-		},
+	{
+		Path:         "C:/project/dir1/file2.txt",
+		TemplatePath: "C:/project/templates/Template2.txt",
+		Contents:     []byte("file 2"),
+	},
+	{
+		Path:        "C:/project/dir1/dir2",
+		IsDirectory: true,
 	},
 }
 
 // setup anything that's required before each individual test
 func processBeforeEach() {
-	command.CreateFile = func(_ models.FileScaffold, _, _ string, _ map[string]string) error {
+	create.Mkdir = mocks.GetMkdir()
+	create.WriteFile = mocks.GetWriteFile()
+	create.Rename = mocks.GetRename()
+	create.Remove = mocks.GetRemove()
+	create.FileStat = mocks.GetFileStat([]mocks.MockFileInfo{})
+
+	command.CreateFile = func(_ *create.Transaction, _ models.PlanItem) error {
 		return nil
 	}
 
-	command.CreateDirectory = func(_ models.DirectoryScaffold, _, _ string, _ map[string]string) error {
+	command.CreateDirectory = func(_ *create.Transaction, _ models.PlanItem) error {
 		return nil
 	}
 }
 
-func TestProcessWillCreateAllFilesInTheCommand(t *testing.T) {
+func TestProcessWillCreateAllItemsInThePlanInOrder(t *testing.T) {
 	processBeforeEach()
 
-	expectedParentDir := "C:/test123/my-work-dir"
-	expectedTemplateDir := "C:/test123/my-work-dir/templates"
-	expectedVars := map[string]string{}
-	varKey := "testKey"
-	expectedVars[varKey] = "testVal123"
+	// We're going to mock the create functions, and have them record the items they're called with
+	createdPaths := []string{}
 
-	// We're going to mock the CreateFile function, and have it populate a slice of calls with the data it's called with
-	mockCalls := make([]mockCreateCall, 0, len(processTestCommand.Files))
+	command.CreateFile = func(_ *create.Transaction, file models.PlanItem) error {
+		if file.IsDirectory {
+			t.Errorf("expected CreateFile to only be called with files. Got directory '%s'", file.Path)
+		}
 
-	command.CreateFile = func(file models.FileScaffold, parentDirectoryPath, templatesDirectoryPath string, vars map[string]string) error {
-		mockCalls = append(mockCalls, mockCreateCall{
-			File:         file,
-			ParentDir:    parentDirectoryPath,
-			TemplatesDir: templatesDirectoryPath,
-			Vars:         vars,
-		})
+		createdPaths = append(createdPaths, file.Path)
+		return nil
+	}
 
+	command.CreateDirectory = func(_ *create.Transaction, directory models.PlanItem) error {
+		if !directory.IsDirectory {
+			t.Errorf("expected CreateDirectory to only be called with directories. Got file '%s'", directory.Path)
+		}
+
+		createdPaths = append(createdPaths, directory.Path)
 		return nil
 	}
 
-	// Now run the function, and test the CreateFile is called with the correct parameters
-	givenErr := command.Process(processTestCommand, expectedParentDir, expectedTemplateDir, expectedVars)
+	givenErr := command.Process(processTestPlan, &create.Transaction{})
 	if givenErr != nil {
 		t.Errorf("expected Process to return no error. Got '%s'", givenErr.Error())
 	}
 
-	if len(mockCalls) != len(processTestCommand.Files) {
-		t.Errorf(
-			"expected Process to make the same amount of calls to CreateFile as the amount of files (%d). Got %d",
-			len(processTestCommand.Files),
-			len(mockCalls),
-		)
-
+	if len(createdPaths) != len(processTestPlan) {
+		t.Errorf("expected Process to create %d items. Got %d", len(processTestPlan), len(createdPaths))
 		return
 	}
 
-	for i, file := range processTestCommand.Files {
-		call := mockCalls[i]
-
-		if call.File.Name != file.Name {
-			t.Errorf("expected Process to call CreateFile with the given file ('%s'). Got '%s'", file.Name, call.File.Name)
-		}
-
-		if call.ParentDir != expectedParentDir {
-			t.Errorf(
-				"expected Process to call CreateFile with the given parent directory ('%s'). Got '%s'",
-				expectedParentDir,
-				call.ParentDir,
-			)
-		}
-
-		if call.TemplatesDir != expectedTemplateDir {
-			t.Errorf(
-				"expected Process to call CreateFile with the given template directory ('%s'). Got '%s'",
-				expectedTemplateDir,
-				call.TemplatesDir,
-			)
-		}
-
-		if call.Vars[varKey] != expectedVars[varKey] {
-			t.Errorf(
-				"expected Process to call CreateFile with the correct variable map (with a variable value '%s'). Got '%s'",
-				expectedVars[varKey],
-				call.Vars[varKey],
-			)
+	for i, item := range processTestPlan {
+		if createdPaths[i] != item.Path {
+			t.Errorf("expected item %d to be created at '%s'. Got '%s'", i, item.Path, createdPaths[i])
 		}
 	}
 }
@@ -140,11 +94,11 @@ func TestProcessWillReturnAnErrorIfThereWasAnErrorCreatingAFile(t *testing.T) {
 	processBeforeEach()
 
 	expectedErrorText := "my test file error"
-	command.CreateFile = func(_ models.FileScaffold, _, _ string, _ map[string]string) error {
+	command.CreateFile = func(_ *create.Transaction, _ models.PlanItem) error {
 		return errors.New(expectedErrorText)
 	}
 
-	givenErr := command.Process(processTestCommand, "/", "/templates", map[string]string{})
+	givenErr := command.Process(processTestPlan, &create.Transaction{})
 
 	if givenErr == nil {
 		t.Errorf("expected an error from Process. Got nil")
@@ -157,99 +111,105 @@ func TestProcessWillReturnAnErrorIfThereWasAnErrorCreatingAFile(t *testing.T) {
 	}
 }
 
-func TestProcessWillCreateAllDirectoriesInTheCommand(t *testing.T) {
+func TestProcessWillReturnAnErrorIfThereWasAnErrorCreatingADirectory(t *testing.T) {
 	processBeforeEach()
 
-	expectedParentDir := "C:/test123/my-work-dir"
-	expectedTemplateDir := "C:/test123/my-work-dir/templates"
-	expectedVars := map[string]string{}
-	varKey := "testKey"
-	expectedVars[varKey] = "testVal123"
+	expectedErrorText := "my test dir error"
+	command.CreateDirectory = func(_ *create.Transaction, _ models.PlanItem) error {
+		return errors.New(expectedErrorText)
+	}
 
-	// We're going to mock the CreateDirectory function, and have it populate a slice of calls with the data it's called with
-	mockCalls := make([]mockCreateCall, 0, len(processTestCommand.Directories))
+	givenErr := command.Process(processTestPlan, &create.Transaction{})
 
-	command.CreateDirectory = func(directory models.DirectoryScaffold, parentDirectoryPath, templatesDirectoryPath string, vars map[string]string) error {
-		mockCalls = append(mockCalls, mockCreateCall{
-			Directory:    directory,
-			ParentDir:    parentDirectoryPath,
-			TemplatesDir: templatesDirectoryPath,
-			Vars:         vars,
-		})
+	if givenErr == nil {
+		t.Errorf("expected an error from Process. Got nil")
+		return
+	}
 
-		return nil
+	givenErrText := givenErr.Error()
+	if givenErrText != expectedErrorText {
+		t.Errorf("Expected error text from Process to be '%s'. Got '%s'", expectedErrorText, givenErrText)
 	}
+}
 
-	// Now run the function, and test the CreateDirectory is called with the correct parameters
-	givenErr := command.Process(processTestCommand, expectedParentDir, expectedTemplateDir, expectedVars)
-	if givenErr != nil {
-		t.Errorf("expected Process to return no error. Got '%s'", givenErr.Error())
+func TestProcessWillStopAtTheFirstError(t *testing.T) {
+	processBeforeEach()
+
+	createFileCalls := 0
+	command.CreateFile = func(_ *create.Transaction, _ models.PlanItem) error {
+		createFileCalls++
+		return errors.New("my test file error")
 	}
 
-	if len(mockCalls) != len(processTestCommand.Directories) {
-		t.Errorf(
-			"expected Process to make the same amount of calls to CreateDirectory as the amount of directories (%d). Got %d",
-			len(processTestCommand.Directories),
-			len(mockCalls),
-		)
+	command.Process(processTestPlan, &create.Transaction{})
 
-		return
+	if createFileCalls != 1 {
+		t.Errorf("expected CreateFile to be called once. Was called %d times", createFileCalls)
 	}
+}
 
-	for i, dir := range processTestCommand.Directories {
-		call := mockCalls[i]
+func TestProcessWillRollBackTheTransactionIfThereWasAnError(t *testing.T) {
+	processBeforeEach()
 
-		if call.Directory.Name != dir.Name {
-			t.Errorf(
-				"expected Process to call CreateDirectory with the given directory ('%s'). Got '%s'",
-				dir.Name,
-				call.Directory.Name,
-			)
-		}
+	// The real transaction funcs will be used, so the transaction records what is created
+	command.CreateFile = (*create.Transaction).File
+	command.CreateDirectory = (*create.Transaction).Directory
 
-		if call.ParentDir != expectedParentDir {
-			t.Errorf(
-				"expected Process to call CreateDirectory with the given parent directory ('%s'). Got '%s'",
-				expectedParentDir,
-				call.ParentDir,
-			)
+	// The third item (a file) will fail to be created
+	create.Rename = func(_, newPath string) error {
+		if newPath == processTestPlan[2].Path {
+			return errors.New("my test file error")
 		}
 
-		if call.TemplatesDir != expectedTemplateDir {
-			t.Errorf(
-				"expected Process to call CreateDirectory with the given template directory ('%s'). Got '%s'",
-				expectedTemplateDir,
-				call.TemplatesDir,
-			)
-		}
+		return nil
+	}
 
-		if call.Vars[varKey] != expectedVars[varKey] {
-			t.Errorf(
-				"expected Process to call CreateDirectory with the correct variable map (with a variable value '%s'). Got '%s'",
-				expectedVars[varKey],
-				call.Vars[varKey],
-			)
+	removedPaths := []string{}
+	create.Remove = func(path string) error {
+		removedPaths = append(removedPaths, path)
+		return nil
+	}
+
+	givenErr := command.Process(processTestPlan, &create.Transaction{})
+	if givenErr == nil {
+		t.Errorf("expected an error from Process. Got nil")
+	}
+
+	// The temporary file for the failed item is removed first, then the created items (in reverse order)
+	expectedRemovedPaths := []string{processTestPlan[1].Path, processTestPlan[0].Path}
+	removedPaths = removedPaths[1:]
+
+	if len(removedPaths) != len(expectedRemovedPaths) {
+		t.Errorf("expected %d paths to be removed. Got %d", len(expectedRemovedPaths), len(removedPaths))
+		return
+	}
+
+	for i, expectedPath := range expectedRemovedPaths {
+		if removedPaths[i] != expectedPath {
+			t.Errorf("expected removed path %d to be '%s'. Got '%s'", i, expectedPath, removedPaths[i])
 		}
 	}
 }
 
-func TestProcessWillReturnAnErrorIfThereWasAnErrorCreatingADirectory(t *testing.T) {
+func TestProcessWillCommitTheTransactionIfSuccessful(t *testing.T) {
 	processBeforeEach()
 
-	expectedErrorText := "my test dir error"
-	command.CreateDirectory = func(_ models.DirectoryScaffold, _, _ string, _ map[string]string) error {
-		return errors.New(expectedErrorText)
+	removeCalls := 0
+	create.Remove = func(path string) error {
+		removeCalls++
+		return nil
 	}
 
-	givenErr := command.Process(processTestCommand, "/", "/templates", map[string]string{})
-
-	if givenErr == nil {
-		t.Errorf("expected an error from Process. Got nil")
-		return
+	transaction := &create.Transaction{}
+	givenErr := command.Process(processTestPlan, transaction)
+	if givenErr != nil {
+		t.Errorf("expected Process to return no error. Got '%s'", givenErr.Error())
 	}
 
-	givenErrText := givenErr.Error()
-	if givenErrText != expectedErrorText {
-		t.Errorf("Expected error text from Process to be '%s'. Got '%s'", expectedErrorText, givenErrText)
+	// A committed transaction should not remove anything when rolled back
+	transaction.Rollback()
+
+	if removeCalls != 0 {
+		t.Errorf("expected nothing to be removed after the transaction was committed. Remove was called %d times", removeCalls)
 	}
 }
@@ -0,0 +1,13 @@
+package command
+
+// The names of the subcommands, which are given in place of a command name
+const (
+	SubcommandList     = "list"
+	SubcommandWhich    = "which"
+	SubcommandDescribe = "describe"
+	SubcommandValidate = "validate"
+)
+
+// SubcommandNames are the names of every subcommand. A command with one of these names can only be run if "--" is given
+// before its name (otherwise, the subcommand is run instead).
+var SubcommandNames = []string{SubcommandList, SubcommandWhich, SubcommandDescribe, SubcommandValidate}
@@ -0,0 +1,225 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+// ValidateAll reads the scaff file at the given path, along with all of its child files (and their child files, and so on),
+// and validates every command in them. Unlike Find, this doesn't stop at the first problem, so every problem that is found
+// is returned in the report (in the order that the files are searched).
+// Commands whose names are already used by another command in the files are reported, as they can never be found.
+func ValidateAll(scaffFilePath string) models.ValidationReport {
+	validator := &hierarchyValidator{
+		report: models.ValidationReport{
+			ScaffFilePaths: []string{},
+			Problems:       []models.ValidationProblem{},
+		},
+		visitedPaths: make(map[string]bool),
+		firstPaths:   make(map[string]string),
+	}
+
+	if _, statErr := FileStat(scaffFilePath); statErr != nil {
+		validator.addProblem(scaffFilePath, "", "", fmt.Sprintf("unable to locate scaff file at path: '%s'", scaffFilePath))
+		return validator.report
+	}
+
+	validator.validateFile(scaffFilePath, []string{})
+	return validator.report
+}
+
+// hierarchyValidator holds the state used while validating a scaff file hierarchy (see ValidateAll)
+type hierarchyValidator struct {
+	report       models.ValidationReport
+	visitedPaths map[string]bool
+	firstPaths   map[string]string // The scaff file path of the first command with each name
+}
+
+// addProblem adds a problem to the report
+func (hv *hierarchyValidator) addProblem(scaffFilePath, commandName, location, message string) {
+	hv.report.Problems = append(hv.report.Problems, models.ValidationProblem{
+		ScaffFilePath: scaffFilePath,
+		CommandName:   commandName,
+		Location:      location,
+		Message:       message,
+	})
+}
+
+// validateFile validates the scaff file at the given path, then its child files. The parentPartialsDirectoryPaths are the
+// partials directories from the files that it is a child of.
+func (hv *hierarchyValidator) validateFile(filePath string, parentPartialsDirectoryPaths []string) {
+	if hv.visitedPaths[filePath] {
+		return
+	}
+	hv.visitedPaths[filePath] = true
+
+	containingDir, _ := path.Split(filePath)
+
+	fileBytes, fileErr := ReadFile(filePath)
+	if fileErr != nil {
+		hv.addProblem(filePath, "", "", fmt.Sprintf("unable to read the scaff file: %v", fileErr))
+		return
+	}
+	hv.report.ScaffFilePaths = append(hv.report.ScaffFilePaths, filePath)
+
+	var scaffFile models.ScaffFile
+	if unmarshalErr := json.Unmarshal(fileBytes, &scaffFile); unmarshalErr != nil {
+		hv.addProblem(filePath, "", "", fmt.Sprintf("the scaff file has an invalid structure: %v", unmarshalErr))
+		return
+	}
+
+	partialsDirectoryPaths := sharedPartialsDirectoryPaths(filePath, scaffFile, parentPartialsDirectoryPaths)
+	if strings.TrimSpace(scaffFile.PartialsDirectoryPath) != "" {
+		// The file's own partials directory is the first one
+		if _, statErr := FileStat(partialsDirectoryPaths[0]); statErr != nil {
+			hv.addProblem(filePath, "", "partialsDirectoryPath", fmt.Sprintf("unable to locate partials directory at path: '%s'", partialsDirectoryPaths[0]))
+		}
+	}
+
+	file := walkedScaffFile{filePath: filePath, scaffFile: scaffFile, partialsDirectoryPaths: partialsDirectoryPaths}
+	for i, command := range scaffFile.Commands {
+		hv.validateCommand(file, i, command)
+	}
+
+	// Child files are validated after the commands in this file (the same order they are searched in)
+	childPathsToValidate := []string{}
+	for i, childPath := range scaffFile.Children {
+		location := fmt.Sprintf("children[%d]", i)
+
+		if strings.TrimSpace(childPath) == "" {
+			hv.addProblem(filePath, "", location, "encountered an empty file path for a child scaff file")
+			continue
+		}
+
+		fullChildPath := path.Join(containingDir, childPath)
+		if _, statErr := FileStat(fullChildPath); statErr != nil {
+			hv.addProblem(filePath, "", location, fmt.Sprintf("unable to locate child scaff file at path: '%s'", fullChildPath))
+			continue
+		}
+
+		childPathsToValidate = append(childPathsToValidate, fullChildPath)
+	}
+
+	for _, childPath := range childPathsToValidate {
+		hv.validateFile(childPath, partialsDirectoryPaths)
+	}
+}
+
+// validateCommand validates the command at the given index in the given file. If the structure of the command is valid, its
+// templates are also read (so any that can't be read or compiled are reported, at the location of the file that uses them).
+func (hv *hierarchyValidator) validateCommand(file walkedScaffFile, index int, command models.Command) {
+	hv.report.CommandCount++
+	location := fmt.Sprintf("commands[%d]", index)
+
+	switch {
+	case strings.TrimSpace(command.Name) == "":
+		hv.addProblem(file.filePath, command.Name, location+".name", "command objects should have a 'name' property that is set to a non-empty value")
+	case slices.Contains(SubcommandNames, command.Name):
+		hv.addProblem(file.filePath, command.Name, location+".name", fmt.Sprintf("'%s' is the name of a subcommand, so this command can only be run with 'scaff -- %s'", command.Name, command.Name))
+	default:
+		if firstPath, isFound := hv.firstPaths[command.Name]; isFound {
+			hv.addProblem(file.filePath, command.Name, location+".name", fmt.Sprintf("a command with this name is already defined in '%s', so this command can't be used", firstPath))
+		} else {
+			hv.firstPaths[command.Name] = file.filePath
+		}
+	}
+
+	fullTemplatePath := commandTemplatePath(file, command)
+	validationErrs := command.Validate(fullTemplatePath)
+	for _, validationErr := range customerrors.PrefixLocations(validationErrs, location) {
+		hv.addProblem(file.filePath, command.Name, validationErr.Location, validationErr.Message)
+	}
+
+	if len(validationErrs) > 0 {
+		return
+	}
+
+	command.PartialsDirectoryPaths = file.partialsDirectoryPaths
+	hv.validateTemplates(file, command, fullTemplatePath, location, command.Files, command.Directories, command.TemplateTree)
+}
+
+// validateTemplates reads and compiles the templates of the given files (and those in the given template tree), then does the
+// same for each of the given directories. Any that can't be read or compiled are reported at the location of the file (or
+// template tree) that uses them, within the given location.
+func (hv *hierarchyValidator) validateTemplates(file walkedScaffFile, command models.Command, fullTemplatePath, location string, files []models.FileScaffold, directories []models.DirectoryScaffold, tree *models.TemplateTree) {
+	// Each file (and tree) is scanned on its own, in a copy of the command that only contains it
+	scan := func(scaffoldLocation string, files []models.FileScaffold, tree *models.TemplateTree) {
+		command.Files, command.Directories, command.TemplateTree = files, nil, tree
+
+		expanded, err := command.ExpandTemplateTrees(fullTemplatePath)
+		if err == nil {
+			_, err = expanded.VariableUsages(fullTemplatePath)
+		}
+
+		for _, readErr := range unwrapErrors(err) {
+			hv.addProblem(file.filePath, command.Name, scaffoldLocation, readErr.Error())
+		}
+	}
+
+	for i, scaffold := range files {
+		scan(fmt.Sprintf("%s.files[%d].templatePath", location, i), []models.FileScaffold{scaffold}, nil)
+	}
+
+	if tree != nil {
+		scan(location+".templateTree", nil, tree)
+	}
+
+	for i, directory := range directories {
+		directoryLocation := fmt.Sprintf("%s.directories[%d]", location, i)
+		hv.validateTemplates(file, command, fullTemplatePath, directoryLocation, directory.Files, directory.Directories, directory.TemplateTree)
+	}
+}
+
+// unwrapErrors returns the errors that were joined to create the given error (or just the given error, if it wasn't joined)
+func unwrapErrors(err error) []error {
+	if err == nil {
+		return []error{}
+	}
+
+	if joinedErr, isJoined := err.(interface{ Unwrap() []error }); isJoined {
+		return joinedErr.Unwrap()
+	}
+
+	return []error{err}
+}
+
+// FormatValidationReport returns the given report as printable text (with paths relative to the given workingDirectory)
+func FormatValidationReport(report models.ValidationReport, workingDirectory string) string {
+	var builder strings.Builder
+
+	for _, problem := range report.Problems {
+		parts := []string{relativePath(problem.ScaffFilePath, workingDirectory)}
+		if problem.CommandName != "" {
+			parts = append(parts, fmt.Sprintf("command '%s'", problem.CommandName))
+		}
+		if problem.Location != "" {
+			parts = append(parts, problem.Location)
+		}
+
+		fmt.Fprintf(&builder, "%s: %s\n", strings.Join(parts, ": "), problem.Message)
+	}
+
+	checked := fmt.Sprintf("checked %s in %s", pluralise(report.CommandCount, "command"), pluralise(len(report.ScaffFilePaths), "scaff file"))
+	if len(report.Problems) == 0 {
+		fmt.Fprintf(&builder, "no problems found (%s)\n", checked)
+	} else {
+		fmt.Fprintf(&builder, "\n%s found (%s)\n", pluralise(len(report.Problems), "problem"), checked)
+	}
+
+	return builder.String()
+}
+
+// pluralise returns the given count followed by the given noun (with an "s" added, if the count isn't 1)
+func pluralise(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", count, noun)
+}
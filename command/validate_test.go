@@ -0,0 +1,126 @@
+package command_test
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/command"
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+func TestValidateAllWillReportEveryProblemInTheHierarchy(t *testing.T) {
+	listBeforeEach(map[string]models.ScaffFile{
+		"C:/project/scaff.json": {
+			Commands: []models.Command{
+				{Name: "service", TemplateDirectoryPath: "templates", Directories: []models.DirectoryScaffold{{Name: "service"}}},
+				{Name: "list", TemplateDirectoryPath: "templates"},
+				{Name: "broken", TemplateDirectoryPath: "", Directories: []models.DirectoryScaffold{{Name: "ok"}, {Name: ""}}},
+			},
+			Children: []string{"scaff_files/child.json", "scaff_files/missing.json", " "},
+		},
+		"C:/project/scaff_files/child.json": {
+			Commands: []models.Command{
+				{Name: "service", TemplateDirectoryPath: "templates"},
+				{
+					Name:                  "unknownFilter",
+					TemplateDirectoryPath: "templates",
+					Files:                 []models.FileScaffold{{Name: "file.txt", TemplatePath: "file.txt"}},
+					Directories:           []models.DirectoryScaffold{{Name: "dir", Files: []models.FileScaffold{{Name: "nested.txt", TemplatePath: "nested.txt"}}}},
+				},
+			},
+		},
+	})
+
+	// Every template exists, but can't be compiled
+	models.FileStat = func(filePath string) (fs.FileInfo, error) {
+		return nil, nil
+	}
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		return []byte("{: name | nope :}"), nil
+	}
+	defer func() { models.FileStat, models.ReadFile = os.Stat, os.ReadFile }()
+
+	report := command.ValidateAll("C:/project/scaff.json")
+
+	expected := []models.ValidationProblem{
+		{ScaffFilePath: "C:/project/scaff.json", CommandName: "list", Location: "commands[1].name", Message: "'list' is the name of a subcommand, so this command can only be run with 'scaff -- list'"},
+		{ScaffFilePath: "C:/project/scaff.json", CommandName: "broken", Location: "commands[2].templateDirectoryPath", Message: "command objects should have a 'templateDirectoryPath' property that is set to a non-empty value"},
+		{ScaffFilePath: "C:/project/scaff.json", CommandName: "broken", Location: "commands[2].directories[1].name", Message: "directory scaffold objects should have a 'name' property that is set to a non-empty value"},
+		{ScaffFilePath: "C:/project/scaff.json", Location: "children[1]", Message: "unable to locate child scaff file at path: 'C:/project/scaff_files/missing.json'"},
+		{ScaffFilePath: "C:/project/scaff.json", Location: "children[2]", Message: "encountered an empty file path for a child scaff file"},
+		{ScaffFilePath: "C:/project/scaff_files/child.json", CommandName: "service", Location: "commands[0].name", Message: "a command with this name is already defined in 'C:/project/scaff.json', so this command can't be used"},
+	}
+
+	// The last problems are from compiling the unknown filter in each template (their messages come from the template engine)
+	expectedCompileLocations := []string{"commands[1].files[0].templatePath", "commands[1].directories[0].files[0].templatePath"}
+	if len(report.Problems) != len(expected)+len(expectedCompileLocations) {
+		t.Fatalf("expected %d problems. got %d: %+v", len(expected)+len(expectedCompileLocations), len(report.Problems), report.Problems)
+	}
+
+	for i := range expected {
+		if report.Problems[i] != expected[i] {
+			t.Errorf("expected problem %d to be %+v. got %+v", i, expected[i], report.Problems[i])
+		}
+	}
+
+	for i, expectedLocation := range expectedCompileLocations {
+		compileProblem := report.Problems[len(expected)+i]
+		if compileProblem.CommandName != "unknownFilter" || compileProblem.Location != expectedLocation {
+			t.Errorf("expected a problem from the command 'unknownFilter' (at '%s'). got %+v", expectedLocation, compileProblem)
+		}
+	}
+
+	if report.CommandCount != 5 {
+		t.Errorf("expected 5 commands to be validated. got %d", report.CommandCount)
+	}
+
+	if len(report.ScaffFilePaths) != 2 {
+		t.Errorf("expected 2 scaff files to be read. got %v", report.ScaffFilePaths)
+	}
+}
+
+func TestValidateAllWillReportInvalidJSONAndMissingFiles(t *testing.T) {
+	listBeforeEach(map[string]models.ScaffFile{"C:/project/scaff.json": {}})
+	command.ReadFile = func(filePath string) ([]byte, error) {
+		return []byte(`{ "commands": [ }`), nil
+	}
+
+	report := command.ValidateAll("C:/project/scaff.json")
+	if len(report.Problems) != 1 || !strings.HasPrefix(report.Problems[0].Message, "the scaff file has an invalid structure: ") {
+		t.Errorf("expected a single problem about the invalid structure. got %+v", report.Problems)
+	}
+
+	report = command.ValidateAll("C:/project/other.json")
+	if len(report.Problems) != 1 || report.Problems[0].Message != "unable to locate scaff file at path: 'C:/project/other.json'" {
+		t.Errorf("expected a single problem about the missing file. got %+v", report.Problems)
+	}
+}
+
+func TestFormatValidationReportWillListEachProblemWithItsFileCommandAndLocation(t *testing.T) {
+	report := models.ValidationReport{
+		ScaffFilePaths: []string{"/project/scaff.json", "/project/scaff_files/child.json"},
+		CommandCount:   3,
+		Problems: []models.ValidationProblem{
+			{ScaffFilePath: "/project/scaff.json", CommandName: "broken", Location: "commands[2].templateDirectoryPath", Message: "first message"},
+			{ScaffFilePath: "/project/scaff_files/child.json", Location: "children[0]", Message: "second message"},
+		},
+	}
+
+	expected := "scaff.json: command 'broken': commands[2].templateDirectoryPath: first message\n" +
+		"scaff_files/child.json: children[0]: second message\n" +
+		"\n" +
+		"2 problems found (checked 3 commands in 2 scaff files)\n"
+
+	if result := command.FormatValidationReport(report, "/project"); result != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result)
+	}
+
+	report.Problems = []models.ValidationProblem{}
+	expected = fmt.Sprintf("no problems found (checked %d commands in %d scaff files)\n", 3, 2)
+	if result := command.FormatValidationReport(report, "/project"); result != expected {
+		t.Errorf("expected '%s'. got '%s'", expected, result)
+	}
+}
@@ -3,6 +3,7 @@ package command
 import (
 	"io/fs"
 	"os"
+	"path/filepath"
 	"runtime"
 )
 
@@ -14,11 +15,15 @@ var ReadFile func(filePath string) ([]byte, error)
 // FileStat is used to get details about files in the filesystem (this can also be used to confirm a file exists)
 var FileStat func(filePath string) (fs.FileInfo, error)
 
+// EvalSymlinks is used to resolve any symbolic links in a path
+var EvalSymlinks func(path string) (string, error)
+
 // CurrentOS identifies the current operating system
 var CurrentOS string
 
 func init() {
 	ReadFile = os.ReadFile
 	FileStat = os.Stat
+	EvalSymlinks = filepath.EvalSymlinks
 	CurrentOS = runtime.GOOS
 }
@@ -0,0 +1,140 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+// walkedScaffFile is a scaff file that has been read while walking the scaff files (see walkScaffFiles)
+type walkedScaffFile struct {
+	filePath               string // The full path to the scaff file
+	parentFilePath         string // The full path to the scaff file that lists this one as a child (empty if it isn't a child file)
+	scaffFile              models.ScaffFile
+	partialsDirectoryPaths []string // The full paths to the shared partials directories its commands can use (nearest first)
+}
+
+// walkScaffFiles moves up the directory tree structure (from the given "currentPath"), reading each file with the given
+// "fileNameAndExt" that it finds. Each file is passed to the visit func, followed by its child files (and their child
+// files, and so on). This is the order in which commands are searched for. If the visit func returns true, the walk is
+// stopped.
+// Each file is only visited once (even if it is the child of more than one file).
+func walkScaffFiles(fileNameAndExt, currentPath string, visit func(file walkedScaffFile) bool) error {
+	visitedPaths := make(map[string]bool)
+
+	for _, filePathToCheck := range scaffFilePathsToCheck(fileNameAndExt, currentPath) {
+		//File exists (and can be accessed)
+		if _, statErr := FileStat(filePathToCheck); statErr == nil {
+			isStopped, err := walkScaffFile(filePathToCheck, "", []string{}, visitedPaths, visit)
+			if err != nil || isStopped {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// scaffFilePathsToCheck returns the path to a file (with the given "fileNameAndExt") in each directory from the given
+// "currentPath" up to the root directory (nearest first)
+func scaffFilePathsToCheck(fileNameAndExt, currentPath string) []string {
+	pathPrefix := "" //Used when constructing file path strings (different depending on OS)
+	if CurrentOS != "windows" {
+		pathPrefix = "/"
+	}
+
+	pathPartsRegex := regexp.MustCompile(`[\\/]`)
+	pathParts := pathPartsRegex.Split(currentPath, -1) //Slice of every directory in the current path
+
+	//Keep rebuilding the path, but losing another directory everytime (so we go up the directory structure)
+	filePaths := []string{}
+	for i := len(pathParts); i > 0; i-- {
+		dirPathToCheck := path.Join(pathParts[0:i]...)
+		filePaths = append(filePaths, path.Join(pathPrefix, dirPathToCheck, fileNameAndExt))
+	}
+
+	return filePaths
+}
+
+// walkScaffFile reads the scaff file at the given path, then visits it and its child files (see walkScaffFiles).
+// The parentFilePath is the file that lists it as a child (if any), and the parentPartialsDirectoryPaths are the partials
+// directories from the files that it is a child of.
+// Returns true if the walk has been stopped.
+func walkScaffFile(filePath, parentFilePath string, parentPartialsDirectoryPaths []string, visitedPaths map[string]bool, visit func(file walkedScaffFile) bool) (bool, error) {
+	if visitedPaths[filePath] {
+		return false, nil
+	}
+	visitedPaths[filePath] = true
+
+	containingDir, _ := path.Split(filePath)
+
+	fileBytes, fileErr := ReadFile(filePath)
+	if fileErr != nil {
+		return false, fileErr
+	}
+
+	var scaffFile models.ScaffFile
+	unmarshalErr := json.Unmarshal(fileBytes, &scaffFile)
+	if unmarshalErr != nil {
+		validationErr := &customerrors.ValidationError{
+			Message: fmt.Sprintf("encountered a scaff.json file with an invalid structure: '%s'", filePath),
+		}
+
+		return false, validationErr
+	}
+
+	partialsDirectoryPaths := sharedPartialsDirectoryPaths(filePath, scaffFile, parentPartialsDirectoryPaths)
+
+	file := walkedScaffFile{
+		filePath:               filePath,
+		parentFilePath:         parentFilePath,
+		scaffFile:              scaffFile,
+		partialsDirectoryPaths: partialsDirectoryPaths,
+	}
+
+	if visit(file) {
+		return true, nil
+	}
+
+	// Walk through any child files
+	if validationErr := scaffFile.ValidateChildrenArray(); validationErr != nil {
+		return false, validationErr
+	}
+
+	for _, childPath := range scaffFile.Children {
+		fullChildPath := path.Join(containingDir, childPath)
+
+		if _, childPathErr := FileStat(fullChildPath); childPathErr != nil {
+			childFindErr := &customerrors.ValidationError{
+				Message: fmt.Sprintf("unable to locate child scaff file at path: '%s'", fullChildPath),
+			}
+
+			return false, childFindErr
+		}
+
+		isStopped, childErr := walkScaffFile(fullChildPath, filePath, partialsDirectoryPaths, visitedPaths, visit)
+		if childErr != nil || isStopped {
+			return isStopped, childErr
+		}
+	}
+
+	return false, nil
+}
+
+// sharedPartialsDirectoryPaths returns the full paths to the partials directories that the commands in the given scaff file
+// (at the given path) can use, nearest first: the file's own partials directory (if it has one), followed by the
+// parentPartialsDirectoryPaths from the files that it is a child of. These are also shared with any child files.
+func sharedPartialsDirectoryPaths(filePath string, scaffFile models.ScaffFile, parentPartialsDirectoryPaths []string) []string {
+	partialsDirectoryPaths := []string{}
+	if strings.TrimSpace(scaffFile.PartialsDirectoryPath) != "" {
+		containingDir, _ := path.Split(filePath)
+		partialsDirectoryPaths = append(partialsDirectoryPaths, path.Join(containingDir, scaffFile.PartialsDirectoryPath))
+	}
+
+	return append(partialsDirectoryPaths, parentPartialsDirectoryPaths...)
+}
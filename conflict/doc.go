@@ -0,0 +1,2 @@
+// Package conflict handles directories/files that are to be generated, but already exist
+package conflict
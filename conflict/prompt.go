@@ -0,0 +1,84 @@
+package conflict
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/M-Derbyshire/scaff/diff"
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+// Resolution identifies how a single conflicting file should be handled
+type Resolution string
+
+const (
+	ResolutionOverwrite Resolution = "overwrite" // The existing file is replaced
+	ResolutionSkip      Resolution = "skip"      // The existing file is kept, and the new file isn't generated
+	ResolutionKeepBoth  Resolution = "keep both" // The existing file is kept, and the new file is generated with the KeepBothSuffix
+)
+
+var (
+	// Stdin is an open file, pointing to the standard input
+	Stdin = os.Stdin
+	// PrintFormatted is used to print a formatted string to standard output
+	PrintFormatted = fmt.Printf
+	// ReadFile is used to read the existing files from the filesystem
+	ReadFile = os.ReadFile
+)
+
+// Prompt shows the user the differences between an existing file and the file that is to be generated in its place, then
+// asks the user how the conflict should be resolved. The user is asked again if their answer isn't recognised.
+func Prompt(file models.PlanItem) (Resolution, error) {
+	existingContents, err := ReadFile(file.Path)
+	if err != nil {
+		return "", err
+	}
+
+	PrintFormatted("file already exists: '%s'\n", file.Path)
+
+	fileDiff := diff.Unified(file.Path, file.Path+" (generated)", string(existingContents), string(file.Contents))
+	if fileDiff == "" {
+		PrintFormatted("(the generated contents are identical to the existing contents)\n")
+	} else {
+		PrintFormatted("%s", fileDiff)
+	}
+
+	for {
+		PrintFormatted("[o]verwrite, [s]kip, or [k]eep both (writing '%s')? > ", file.Path+KeepBothSuffix)
+
+		input, err := readLine()
+		if err != nil {
+			return "", err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "o", "overwrite":
+			return ResolutionOverwrite, nil
+		case "s", "skip":
+			return ResolutionSkip, nil
+		case "k", "keep", "keep both":
+			return ResolutionKeepBoth, nil
+		}
+	}
+}
+
+// readLine reads a line of input from the Stdin (including the line ending).
+// Only one line is read from the Stdin (nothing after the line is consumed, so it can be read by the next prompt).
+func readLine() (string, error) {
+	input := []byte{}
+	nextByte := make([]byte, 1)
+	for {
+		readCount, err := Stdin.Read(nextByte)
+		if readCount > 0 {
+			input = append(input, nextByte[0])
+			if nextByte[0] == '\n' {
+				return string(input), nil
+			}
+		}
+
+		if err != nil {
+			return "", err
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package conflict_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/conflict"
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+func setupMockStdIn(inputText string) error {
+	inputBytes := []byte(inputText)
+
+	// Setup the file to act as the Stdin
+	readToFile, writeToFile, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	// Write the mock input to the file
+	_, err = writeToFile.Write(inputBytes)
+	if err != nil {
+		return err
+	}
+	writeToFile.Close()
+
+	// Set the Stdin
+	conflict.Stdin = readToFile
+	return nil
+}
+
+var promptTestFile = models.PlanItem{
+	Path:     "/project/file1.txt",
+	Contents: []byte("line 1\nnew line 2\n"),
+}
+
+// setup runs any setup code that is generic across all tests for the prompt func.
+// Returns a pointer to a string that the printed output will be written to.
+func promptBeforeEach() *string {
+	conflict.ReadFile = func(_ string) ([]byte, error) {
+		return []byte("line 1\nline 2\n"), nil
+	}
+
+	output := ""
+	conflict.PrintFormatted = func(format string, a ...any) (int, error) {
+		output += fmt.Sprintf(format, a...)
+		return 0, nil
+	}
+
+	return &output
+}
+
+func TestPromptWillReturnTheChosenResolution(t *testing.T) {
+	answersToTest := map[string]conflict.Resolution{
+		"o\n":         conflict.ResolutionOverwrite,
+		"Overwrite\n": conflict.ResolutionOverwrite,
+		"s\n":         conflict.ResolutionSkip,
+		"skip\r\n":    conflict.ResolutionSkip,
+		"k\n":         conflict.ResolutionKeepBoth,
+		"keep both\n": conflict.ResolutionKeepBoth,
+	}
+
+	for answer, expectedResolution := range answersToTest {
+		promptBeforeEach()
+		if err := setupMockStdIn(answer); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := conflict.Prompt(promptTestFile)
+		if err != nil {
+			t.Errorf("expected no error. Got '%s'", err.Error())
+		}
+
+		if result != expectedResolution {
+			t.Errorf("expected the answer '%s' to give the resolution '%s'. Got '%s'", strings.TrimSpace(answer), expectedResolution, result)
+		}
+	}
+}
+
+func TestPromptWillAskAgainIfAnswerNotRecognised(t *testing.T) {
+	output := promptBeforeEach()
+	if err := setupMockStdIn("maybe\ns\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := conflict.Prompt(promptTestFile)
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if result != conflict.ResolutionSkip {
+		t.Errorf("expected the resolution to be '%s'. Got '%s'", conflict.ResolutionSkip, result)
+	}
+
+	if strings.Count(*output, "[o]verwrite") != 2 {
+		t.Errorf("expected the question to be asked twice. Got output:\n%s", *output)
+	}
+}
+
+func TestPromptWillLeaveTheAnswersToLaterPromptsInTheStdin(t *testing.T) {
+	promptBeforeEach()
+	if err := setupMockStdIn("o\nk\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	results := []conflict.Resolution{}
+	for range 2 {
+		result, err := conflict.Prompt(promptTestFile)
+		if err != nil {
+			t.Fatalf("expected no error. Got '%s'", err.Error())
+		}
+
+		results = append(results, result)
+	}
+
+	if results[0] != conflict.ResolutionOverwrite || results[1] != conflict.ResolutionKeepBoth {
+		t.Errorf("expected the resolutions '%s' and '%s'. Got %v", conflict.ResolutionOverwrite, conflict.ResolutionKeepBoth, results)
+	}
+}
+
+func TestPromptWillPrintADiffOfTheExistingAndGeneratedContents(t *testing.T) {
+	output := promptBeforeEach()
+	if err := setupMockStdIn("s\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	conflict.Prompt(promptTestFile)
+
+	for _, expectedLine := range []string{"-line 2", "+new line 2"} {
+		if !strings.Contains(*output, expectedLine+"\n") {
+			t.Errorf("expected output to contain the diff line '%s'. Got output:\n%s", expectedLine, *output)
+		}
+	}
+}
+
+func TestPromptWillReturnErrorFromReadingInput(t *testing.T) {
+	promptBeforeEach()
+	if err := setupMockStdIn("o"); err != nil { // Not terminated with \n, so should fail
+		t.Fatal(err)
+	}
+
+	_, err := conflict.Prompt(promptTestFile)
+
+	if err == nil {
+		t.Errorf("expected an error. Got nil")
+	}
+}
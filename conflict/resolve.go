@@ -0,0 +1,97 @@
+package conflict
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+// KeepBothSuffix is added to the path of a generated file, when the user chooses to keep both it and the existing file
+const KeepBothSuffix = ".scaff-new"
+
+// Resolve applies the given policy to the items in the plan that already exist, and returns the updated plan.
+// The existingPaths are the paths (from the plan) that collide with existing paths (see command.IdentifyExistingPaths).
+// A directory in this list is one that exists as a file, so it (and its contents) can only be skipped.
+// If noInput is true, the user is never prompted (so an existing file can't be handled with the prompt policy).
+// An error is returned if an existing path cannot be handled with the policy.
+func Resolve(items []models.PlanItem, existingPaths []string, policy models.ConflictPolicy, noInput bool) ([]models.PlanItem, error) {
+	resolvedItems := []models.PlanItem{}
+	skippedDirectoryPaths := []string{}
+
+	for _, item := range items {
+		if isWithinAny(item.Path, skippedDirectoryPaths) {
+			continue
+		}
+
+		if !containsPath(existingPaths, item.Path) {
+			resolvedItems = append(resolvedItems, item)
+			continue
+		}
+
+		if item.IsDirectory {
+			if policy != models.ConflictSkip {
+				return items, fmt.Errorf("a file already exists where a directory is to be created (use '--on-conflict=skip' to skip it): %s", item.Path)
+			}
+
+			skippedDirectoryPaths = append(skippedDirectoryPaths, item.Path)
+			continue
+		}
+
+		var resolution Resolution
+		switch policy {
+		case models.ConflictSkip:
+			resolution = ResolutionSkip
+		case models.ConflictOverwrite:
+			resolution = ResolutionOverwrite
+		case models.ConflictPrompt:
+			if noInput {
+				return items, fmt.Errorf("path already exists (unable to prompt, as input is disabled): %s", item.Path)
+			}
+
+			promptResolution, err := Prompt(item)
+			if err != nil {
+				return items, err
+			}
+
+			resolution = promptResolution
+		default:
+			return items, fmt.Errorf("path already exists: %s", item.Path)
+		}
+
+		switch resolution {
+		case ResolutionOverwrite:
+			item.Overwrite = true
+			resolvedItems = append(resolvedItems, item)
+		case ResolutionKeepBoth:
+			item.Path += KeepBothSuffix
+			item.Overwrite = true // Any previous copy is out of date
+			resolvedItems = append(resolvedItems, item)
+		}
+	}
+
+	return resolvedItems, nil
+}
+
+// containsPath identifies if the given path is in the slice of paths
+func containsPath(paths []string, pathToFind string) bool {
+	return slices.ContainsFunc(paths, func(path string) bool {
+		return filepath.Clean(path) == filepath.Clean(pathToFind)
+	})
+}
+
+// isWithinAny identifies if the given path is inside any of the given directory paths
+func isWithinAny(pathToCheck string, directoryPaths []string) bool {
+	cleanPath := filepath.ToSlash(filepath.Clean(pathToCheck))
+
+	for _, directoryPath := range directoryPaths {
+		cleanDirectoryPath := filepath.ToSlash(filepath.Clean(directoryPath))
+		if strings.HasPrefix(cleanPath, strings.TrimSuffix(cleanDirectoryPath, "/")+"/") {
+			return true
+		}
+	}
+
+	return false
+}
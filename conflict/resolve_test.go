@@ -0,0 +1,147 @@
+package conflict_test
+
+import (
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/conflict"
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+var resolveTestPlan = []models.PlanItem{
+	{Path: "/project/file1.txt"},
+	{Path: "/project/file2.txt"},
+	{Path: "/project/dir1", IsDirectory: true},
+	{Path: "/project/dir1/file3.txt"},
+	{Path: "/project/dir2", IsDirectory: true},
+}
+
+// getPaths returns the paths of the given items
+func getPaths(items []models.PlanItem) []string {
+	paths := []string{}
+	for _, item := range items {
+		paths = append(paths, item.Path)
+	}
+
+	return paths
+}
+
+// comparePaths reports any differences between the paths of the given items and the expected paths
+func comparePaths(t *testing.T, items []models.PlanItem, expectedPaths []string) {
+	resultPaths := getPaths(items)
+
+	if len(resultPaths) != len(expectedPaths) {
+		t.Errorf("expected the paths %v. Got %v", expectedPaths, resultPaths)
+		return
+	}
+
+	for i, expectedPath := range expectedPaths {
+		if resultPaths[i] != expectedPath {
+			t.Errorf("expected path %d to be '%s'. Got '%s'", i, expectedPath, resultPaths[i])
+		}
+	}
+}
+
+func TestResolveWillRemoveExistingFilesWhenSkipping(t *testing.T) {
+	result, err := conflict.Resolve(resolveTestPlan, []string{"/project/file2.txt"}, models.ConflictSkip, false)
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	comparePaths(t, result, []string{"/project/file1.txt", "/project/dir1", "/project/dir1/file3.txt", "/project/dir2"})
+}
+
+func TestResolveWillRemoveDirectoriesThatExistAsFilesAndTheirContentsWhenSkipping(t *testing.T) {
+	result, err := conflict.Resolve(resolveTestPlan, []string{"/project/dir1"}, models.ConflictSkip, false)
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	comparePaths(t, result, []string{"/project/file1.txt", "/project/file2.txt", "/project/dir2"})
+}
+
+func TestResolveWillMarkExistingFilesToBeOverwrittenWhenOverwriting(t *testing.T) {
+	result, err := conflict.Resolve(resolveTestPlan, []string{"/project/file2.txt"}, models.ConflictOverwrite, false)
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	comparePaths(t, result, getPaths(resolveTestPlan))
+
+	for _, item := range result {
+		expectOverwrite := item.Path == "/project/file2.txt"
+		if item.Overwrite != expectOverwrite {
+			t.Errorf("expected Overwrite to be %v for '%s'. Got %v", expectOverwrite, item.Path, item.Overwrite)
+		}
+	}
+}
+
+func TestResolveWillReturnErrorForDirectoriesThatExistAsFilesWhenOverwriting(t *testing.T) {
+	_, err := conflict.Resolve(resolveTestPlan, []string{"/project/dir1"}, models.ConflictOverwrite, false)
+
+	if err == nil {
+		t.Errorf("expected an error. Got nil")
+	}
+}
+
+func TestResolveWillReturnErrorForExistingPathsWithErrorPolicy(t *testing.T) {
+	expectedErr := "path already exists: /project/file1.txt"
+
+	_, err := conflict.Resolve(resolveTestPlan, []string{"/project/file1.txt"}, models.ConflictError, false)
+	if err == nil {
+		t.Errorf("expected an error. Got nil")
+		return
+	}
+
+	if err.Error() != expectedErr {
+		t.Errorf("expected error to be '%s'. Got '%s'", expectedErr, err.Error())
+	}
+}
+
+func TestResolveWillUseThePromptedResolution(t *testing.T) {
+	conflict.ReadFile = func(_ string) ([]byte, error) {
+		return []byte("existing contents\n"), nil
+	}
+	conflict.PrintFormatted = func(_ string, _ ...any) (int, error) {
+		return 0, nil
+	}
+
+	existingPaths := []string{"/project/file1.txt", "/project/file2.txt", "/project/dir1/file3.txt"}
+	if err := setupMockStdIn("k\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := conflict.Resolve(resolveTestPlan[:1], existingPaths, models.ConflictPrompt, false)
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	comparePaths(t, result, []string{"/project/file1.txt" + conflict.KeepBothSuffix})
+}
+
+func TestResolveWillNotPromptIfInputIsDisabled(t *testing.T) {
+	conflict.ReadFile = func(_ string) ([]byte, error) {
+		t.Errorf("expected the existing file not to be read")
+		return []byte{}, nil
+	}
+	conflict.PrintFormatted = func(_ string, _ ...any) (int, error) {
+		t.Errorf("expected nothing to be printed")
+		return 0, nil
+	}
+
+	// An answer is available, but shouldn't be used
+	if err := setupMockStdIn("o\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedErr := "path already exists (unable to prompt, as input is disabled): /project/file1.txt"
+
+	_, err := conflict.Resolve(resolveTestPlan, []string{"/project/file1.txt"}, models.ConflictPrompt, true)
+	if err == nil {
+		t.Errorf("expected an error. Got nil")
+		return
+	}
+
+	if err.Error() != expectedErr {
+		t.Errorf("expected error to be '%s'. Got '%s'", expectedErr, err.Error())
+	}
+}
@@ -1,12 +1,13 @@
 package create
 
 import (
+	"fmt"
 	"io/fs"
+	"math/rand"
 	"os"
 	"path"
 
 	"github.com/M-Derbyshire/scaff/models"
-	"github.com/M-Derbyshire/scaff/variable"
 )
 
 // ReadFile is used to read files from the filesystem
@@ -15,43 +16,64 @@ var ReadFile func(string) ([]byte, error)
 // WriteFile is used to create files in the filesystem
 var WriteFile func(string, []byte, fs.FileMode) error
 
+// Rename is used to move files in the filesystem
+var Rename func(string, string) error
+
+// Remove is used to delete files/directories in the filesystem
+var Remove func(string) error
+
+// Chmod is used to set the permissions of files in the filesystem
+var Chmod func(string, fs.FileMode) error
+
 func init() {
 	ReadFile = os.ReadFile
 	WriteFile = os.WriteFile
+	Rename = os.Rename
+	Remove = os.Remove
+	Chmod = os.Chmod
 }
 
-// File creates a file, based on the given FileScaffold.
-// The parentDirectoryPath is the path to the directory that will contain this file.
-// The fullTemplatesDirectoryPath is the path to the directory that contains templates (may not be the full path to the specific
-// template directory for this file -- it will be joined with the FileScaffold's TemplatePath property).
-// The vars is a map of variables to populate the file and filename with.
-func File(file models.FileScaffold, parentDirectoryPath, fullTemplatesDirectoryPath string, vars map[string]string) error {
-	// Load template
-	fullTemplatePath := file.GetFullTemplatePath(fullTemplatesDirectoryPath)
-	templateBytes, templateErr := ReadFile(fullTemplatePath)
-	if templateErr != nil {
-		return templateErr
-	}
+// File creates a file, based on the given PlanItem.
+// The file's name and contents should already be populated (see command.Plan), and the parent directory should already exist.
+// The contents are written to a temporary file (in the same directory) first, which is then renamed. This ensures a file is
+// never left partly written.
+// If the PlanItem has a Mode, the file is given exactly those permissions (otherwise, the default permissions are used).
+// If the PlanItem overwrites an existing file, the file keeps the existing file's permissions instead (e.g. so an
+// executable script is still executable).
+func File(file models.PlanItem) error {
+	parentDirectoryPath, fileName := path.Split(file.Path)
+	tempFilePath := path.Join(parentDirectoryPath, fmt.Sprintf(".%s.scaff-tmp-%d", fileName, rand.Int63()))
 
-	// Populate template with variable values
-	templateStr := string(templateBytes)
-	populatedTemplate, templatePopulateErr := variable.Populate(templateStr, vars)
-	if templatePopulateErr != nil {
-		return templatePopulateErr
+	if file.Overwrite {
+		if existingInfo, statErr := FileStat(file.Path); statErr == nil && !existingInfo.IsDir() {
+			file.Mode = existingInfo.Mode().Perm()
+		}
 	}
 
-	// Populate file name with variable values, and create the full file path for the new file
-	populatedFileName, fileNameErr := variable.Populate(file.Name, vars)
-	if fileNameErr != nil {
-		return fileNameErr
+	var perms fs.FileMode = 0666
+	if file.Mode != 0 {
+		perms = file.Mode.Perm()
 	}
-	fullFilePath := path.Join(parentDirectoryPath, populatedFileName)
 
-	// Write the file
-	writeErr := WriteFile(fullFilePath, []byte(populatedTemplate), 0666)
+	writeErr := WriteFile(tempFilePath, file.Contents, perms)
 	if writeErr != nil {
+		Remove(tempFilePath)
 		return writeErr
 	}
 
+	// The permissions given to WriteFile are reduced by the umask, so they are set again to keep them exactly
+	if file.Mode != 0 {
+		if chmodErr := Chmod(tempFilePath, perms); chmodErr != nil {
+			Remove(tempFilePath)
+			return chmodErr
+		}
+	}
+
+	renameErr := Rename(tempFilePath, file.Path)
+	if renameErr != nil {
+		Remove(tempFilePath)
+		return renameErr
+	}
+
 	return nil
 }
@@ -3,6 +3,7 @@ package create_test
 import (
 	"errors"
 	"io/fs"
+	"strings"
 	"testing"
 
 	"github.com/M-Derbyshire/scaff/create"
@@ -10,74 +11,35 @@ import (
 	"github.com/M-Derbyshire/scaff/models"
 )
 
-var mockTemplateData = []byte("my mock template file.")
-var mockFileScaffold = models.FileScaffold{
-	Name:         "MyNewFile",
-	TemplatePath: "MyTemplate.txt",
+var mockFileItem = models.PlanItem{
+	Path:         "C:/myDir/MyNewFile",
+	TemplatePath: "C:/myTemplatePath/MyTemplate.txt",
+	Contents:     []byte("my mock file contents."),
 }
 
 // setup runs any setup code that is generic across all tests for the file func
-func findBeforeEach() {
-	create.ReadFile = mocks.GetReadFile(mockTemplateData)
+func fileBeforeEach() {
 	create.WriteFile = mocks.GetWriteFile()
-}
-
-func TestWillLoadTheTemplateFileWithTheCorrectFilePath(t *testing.T) {
-	findBeforeEach()
-
-	fileScaffold := models.FileScaffold{
-		Name:         "MyNewFile",
-		TemplatePath: "MyInnerTemplateDir/MyTemplate.txt",
-	}
-	templatePath := "C:/myTemplatePath"
-	expectedFilePath := "C:/myTemplatePath/MyInnerTemplateDir/MyTemplate.txt"
-
-	// We'll capture the path that ReadFile was called with
-	var resultFilePath string
-	create.ReadFile = func(filePath string) ([]byte, error) {
-		resultFilePath = filePath
-		return nil, errors.New("test error")
-	}
-
-	create.File(fileScaffold, "C:/", templatePath, map[string]string{})
-
-	if resultFilePath != expectedFilePath {
-		t.Errorf("file path should have been '%s'. Got '%s'", expectedFilePath, resultFilePath)
-	}
-}
-
-func TestWillReturnErrorFromReadFileWhenReadingTheTemplateFile(t *testing.T) {
-	findBeforeEach()
-
-	expectedErrorText := "my test error 123"
-	create.ReadFile = func(filePath string) ([]byte, error) {
-		return nil, errors.New(expectedErrorText)
-	}
-
-	err := create.File(mockFileScaffold, "", "", map[string]string{})
-
-	if err == nil {
-		t.Errorf("expected an error when reading template file, but got nil")
-	}
-
-	errorText := err.Error()
-	if errorText != expectedErrorText {
-		t.Errorf("expected template read error to be '%s'. Got '%s'", expectedErrorText, errorText)
-	}
+	create.Mkdir = mocks.GetMkdir()
+	create.Rename = mocks.GetRename()
+	create.Remove = mocks.GetRemove()
+	create.Chmod = mocks.GetChmod()
+	create.FileStat = mocks.GetFileStat([]mocks.MockFileInfo{})
 }
 
 func TestWillReturnErrorFromWriteFileWhenCreatingFile(t *testing.T) {
-	findBeforeEach()
+	fileBeforeEach()
 
 	expectedErrorText := "my test error 123"
 	create.WriteFile = func(name string, data []byte, perm fs.FileMode) error {
 		return errors.New(expectedErrorText)
 	}
 
-	err := create.File(mockFileScaffold, "", "", map[string]string{})
+	err := create.File(mockFileItem)
 
 	if err == nil {
 		t.Errorf("expected an error when creating file, but got nil")
+		return
 	}
 
 	errorText := err.Error()
@@ -87,17 +49,17 @@ func TestWillReturnErrorFromWriteFileWhenCreatingFile(t *testing.T) {
 }
 
 func TestWillNotReturnErrorIfFileCreated(t *testing.T) {
-	findBeforeEach()
+	fileBeforeEach()
 
-	err := create.File(mockFileScaffold, "C:/", "test", map[string]string{})
+	err := create.File(mockFileItem)
 
 	if err != nil {
 		t.Errorf("expected nil for error when creating file. Got '%s'", err.Error())
 	}
 }
 
-func TestWillCreateFileWithTheCorrectFilePath(t *testing.T) {
-	findBeforeEach()
+func TestWillWriteToATemporaryFileInTheSameDirectory(t *testing.T) {
+	fileBeforeEach()
 
 	var resultFilePath string
 	create.WriteFile = func(name string, data []byte, perm fs.FileMode) error {
@@ -105,30 +67,74 @@ func TestWillCreateFileWithTheCorrectFilePath(t *testing.T) {
 		return nil
 	}
 
-	fileScaffold := models.FileScaffold{
-		Name:         "My-{: var1 :}-New-{: var2 :}-File",
-		TemplatePath: "MyTemplate.txt",
+	create.File(mockFileItem)
+
+	expectedPrefix := "C:/myDir/.MyNewFile.scaff-tmp-"
+	if !strings.HasPrefix(resultFilePath, expectedPrefix) {
+		t.Errorf("expected temporary file path to start with '%s'. Got '%s'", expectedPrefix, resultFilePath)
+	}
+}
+
+func TestWillRenameTheTemporaryFileToTheCorrectFilePath(t *testing.T) {
+	fileBeforeEach()
+
+	var writtenFilePath string
+	create.WriteFile = func(name string, data []byte, perm fs.FileMode) error {
+		writtenFilePath = name
+		return nil
 	}
-	parentDir := "C:/myDir"
-	vars := map[string]string{
-		"var1": "value1",
-		"var2": "value2",
+
+	var renamedFromPath, renamedToPath string
+	create.Rename = func(oldPath, newPath string) error {
+		renamedFromPath = oldPath
+		renamedToPath = newPath
+		return nil
 	}
 
-	create.File(fileScaffold, parentDir, "/", vars)
+	create.File(mockFileItem)
 
-	expectedFilePath := "C:/myDir/My-value1-New-value2-File"
+	if renamedFromPath != writtenFilePath {
+		t.Errorf("expected the renamed file to be the temporary file ('%s'). Got '%s'", writtenFilePath, renamedFromPath)
+	}
 
-	if resultFilePath != expectedFilePath {
-		t.Errorf("expected created file path to be '%s'. Got '%s'", expectedFilePath, resultFilePath)
+	if renamedToPath != mockFileItem.Path {
+		t.Errorf("expected created file path to be '%s'. Got '%s'", mockFileItem.Path, renamedToPath)
 	}
 }
 
-func TestWillCreateFileWithTheCorrectContents(t *testing.T) {
-	findBeforeEach()
+func TestWillRemoveTheTemporaryFileIfRenameFails(t *testing.T) {
+	fileBeforeEach()
+
+	var writtenFilePath string
+	create.WriteFile = func(name string, data []byte, perm fs.FileMode) error {
+		writtenFilePath = name
+		return nil
+	}
 
-	fileContents := "{: var1 :} - {: var2 :}"
-	create.ReadFile = mocks.GetReadFile([]byte(fileContents)) // Mock the template file
+	expectedErrorText := "my test error 123"
+	create.Rename = func(_, _ string) error {
+		return errors.New(expectedErrorText)
+	}
+
+	var removedPath string
+	create.Remove = func(path string) error {
+		removedPath = path
+		return nil
+	}
+
+	err := create.File(mockFileItem)
+
+	if err == nil || err.Error() != expectedErrorText {
+		t.Errorf("expected the rename error ('%s') to be returned. Got '%v'", expectedErrorText, err)
+	}
+
+	if removedPath != writtenFilePath {
+		t.Errorf("expected the temporary file ('%s') to be removed. Got '%s'", writtenFilePath, removedPath)
+	}
+}
+
+func TestWillCreateFileWithTheCorrectContents(t *testing.T) {
+	fileBeforeEach()
 
 	var resultFileContents string
 	create.WriteFile = func(name string, data []byte, perm fs.FileMode) error {
@@ -136,14 +142,9 @@ func TestWillCreateFileWithTheCorrectContents(t *testing.T) {
 		return nil
 	}
 
-	vars := map[string]string{
-		"var1": "value1",
-		"var2": "value2",
-	}
-
-	create.File(mockFileScaffold, "C:/", "/", vars)
+	create.File(mockFileItem)
 
-	expectedFileContents := "value1 - value2"
+	expectedFileContents := string(mockFileItem.Contents)
 
 	if resultFileContents != expectedFileContents {
 		t.Errorf("expected created file contents to be '%s'. Got '%s'", expectedFileContents, resultFileContents)
@@ -151,7 +152,7 @@ func TestWillCreateFileWithTheCorrectContents(t *testing.T) {
 }
 
 func TestWillCreateFileWithTheCorrectPermissions(t *testing.T) {
-	findBeforeEach()
+	fileBeforeEach()
 
 	var resultFilePerms fs.FileMode
 	create.WriteFile = func(name string, data []byte, perm fs.FileMode) error {
@@ -159,7 +160,7 @@ func TestWillCreateFileWithTheCorrectPermissions(t *testing.T) {
 		return nil
 	}
 
-	create.File(mockFileScaffold, "C:/", "/", map[string]string{})
+	create.File(mockFileItem)
 
 	var expectedFilePerms fs.FileMode = 0666
 
@@ -167,3 +168,84 @@ func TestWillCreateFileWithTheCorrectPermissions(t *testing.T) {
 		t.Errorf("expected created file permissions to be %#o. Got %#o", expectedFilePerms, resultFilePerms)
 	}
 }
+
+func TestWillCreateFileWithTheModeOfThePlanItemIfItHasOne(t *testing.T) {
+	fileBeforeEach()
+
+	var resultFilePerms fs.FileMode
+	create.WriteFile = func(name string, data []byte, perm fs.FileMode) error {
+		resultFilePerms = perm
+		return nil
+	}
+
+	var chmodPath string
+	var chmodPerms fs.FileMode
+	create.Chmod = func(name string, mode fs.FileMode) error {
+		chmodPath = name
+		chmodPerms = mode
+		return nil
+	}
+
+	rawFileItem := mockFileItem
+	rawFileItem.Mode = 0755
+
+	if err := create.File(rawFileItem); err != nil {
+		t.Errorf("expected nil for error when creating file. Got '%s'", err.Error())
+	}
+
+	if resultFilePerms != 0755 || chmodPerms != 0755 {
+		t.Errorf("expected created file permissions to be %#o. Got %#o (and %#o from chmod)", 0755, resultFilePerms, chmodPerms)
+	}
+
+	if !strings.HasPrefix(chmodPath, "C:/myDir/.MyNewFile.scaff-tmp-") {
+		t.Errorf("expected chmod to be called on the temporary file. Got '%s'", chmodPath)
+	}
+}
+
+func TestWillNotChmodTheFileIfThePlanItemHasNoMode(t *testing.T) {
+	fileBeforeEach()
+
+	chmodCalled := false
+	create.Chmod = func(name string, mode fs.FileMode) error {
+		chmodCalled = true
+		return nil
+	}
+
+	create.File(mockFileItem)
+
+	if chmodCalled {
+		t.Errorf("expected chmod not to be called")
+	}
+}
+
+func TestWillKeepThePermissionsOfTheExistingFileWhenOverwritingIt(t *testing.T) {
+	fileBeforeEach()
+
+	existingFileInfo := mocks.CreateMockInfo(mockFileItem.Path, false)
+	existingFileInfo.ModeValue = 0750
+	create.FileStat = mocks.GetFileStat([]mocks.MockFileInfo{existingFileInfo})
+
+	var resultFilePerms fs.FileMode
+	create.WriteFile = func(name string, data []byte, perm fs.FileMode) error {
+		resultFilePerms = perm
+		return nil
+	}
+
+	var chmodPerms fs.FileMode
+	create.Chmod = func(name string, mode fs.FileMode) error {
+		chmodPerms = mode
+		return nil
+	}
+
+	overwriteItem := mockFileItem
+	overwriteItem.Overwrite = true
+	overwriteItem.Mode = 0644
+
+	if err := create.File(overwriteItem); err != nil {
+		t.Errorf("expected nil for error when creating file. Got '%s'", err.Error())
+	}
+
+	if resultFilePerms != 0750 || chmodPerms != 0750 {
+		t.Errorf("expected overwritten file permissions to be %#o. Got %#o (and %#o from chmod)", 0750, resultFilePerms, chmodPerms)
+	}
+}
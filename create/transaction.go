@@ -0,0 +1,127 @@
+package create
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+// Transaction records the directories/files that are created during a run, so they can all be removed if the run fails.
+// It is safe to call Rollback from another goroutine (e.g. when handling a signal) while items are being created.
+type Transaction struct {
+	mutex        sync.Mutex
+	createdPaths []createdPath // The paths that have been created, in the order they were created
+	isFinished   bool          // Set once the transaction has been committed or rolled back
+	isCommitted  bool          // Set once the transaction has been committed
+}
+
+// createdPath is a path that has been created within a Transaction
+type createdPath struct {
+	path         string
+	replacedFile *models.PlanItem // If an existing file was overwritten, this holds its original contents (and permissions)
+}
+
+// Directory creates a directory (see the Directory func), and records it in the transaction.
+// If the directory already exists, it is reused (and isn't recorded, so a rollback won't remove it).
+func (t *Transaction) Directory(directory models.PlanItem) error {
+	return t.create(directory, Directory)
+}
+
+// File creates a file (see the File func), and records it in the transaction.
+// If the file is allowed to overwrite an existing file, the existing file's contents and permissions are kept (so they can
+// be restored).
+func (t *Transaction) File(file models.PlanItem) error {
+	return t.create(file, File)
+}
+
+// create runs the given create function for the item, then records the item's path
+func (t *Transaction) create(item models.PlanItem, createFunc func(models.PlanItem) error) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.isFinished {
+		return fmt.Errorf("unable to create '%s', as the transaction has already finished", item.Path)
+	}
+
+	if item.IsDirectory {
+		if dirInfo, statErr := FileStat(item.Path); statErr == nil && dirInfo.IsDir() {
+			return nil
+		}
+	}
+
+	created := createdPath{path: item.Path}
+	if item.Overwrite && !item.IsDirectory {
+		existingContents, readErr := ReadFile(item.Path)
+		if readErr == nil {
+			created.replacedFile = &models.PlanItem{Path: item.Path, Contents: existingContents}
+
+			// The restored file is given the same permissions (e.g. so an executable script is still executable)
+			if fileInfo, statErr := FileStat(item.Path); statErr == nil {
+				created.replacedFile.Mode = fileInfo.Mode().Perm()
+			}
+		} else if !errors.Is(readErr, fs.ErrNotExist) {
+			return fmt.Errorf("unable to read '%s' before overwriting it: %v", item.Path, readErr)
+		}
+	}
+
+	if err := createFunc(item); err != nil {
+		return err
+	}
+
+	t.createdPaths = append(t.createdPaths, created)
+	return nil
+}
+
+// Commit finishes the transaction, keeping everything that was created within it.
+// Once committed, a call to Rollback will not remove anything.
+func (t *Transaction) Commit() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.isFinished = true
+	t.isCommitted = true
+}
+
+// IsCommitted identifies if the transaction has been committed (so everything created within it has been kept)
+func (t *Transaction) IsCommitted() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.isCommitted
+}
+
+// Rollback removes everything that was created within the transaction (in the reverse order to which it was created),
+// restores any files that were overwritten, and finishes the transaction.
+// Any errors encountered while removing/restoring paths are returned together.
+func (t *Transaction) Rollback() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.isFinished {
+		return nil
+	}
+	t.isFinished = true
+
+	var errs []error
+	for i := len(t.createdPaths) - 1; i >= 0; i-- {
+		created := t.createdPaths[i]
+
+		if created.replacedFile != nil {
+			if restoreErr := File(*created.replacedFile); restoreErr != nil {
+				errs = append(errs, fmt.Errorf("unable to restore '%s': %v", created.path, restoreErr))
+			}
+
+			continue
+		}
+
+		if removeErr := Remove(created.path); removeErr != nil {
+			errs = append(errs, fmt.Errorf("unable to remove '%s': %v", created.path, removeErr))
+		}
+	}
+
+	t.createdPaths = nil
+	return errors.Join(errs...)
+}
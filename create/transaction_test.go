@@ -0,0 +1,285 @@
+package create_test
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/create"
+	"github.com/M-Derbyshire/scaff/mocks"
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+// setup runs any setup code that is generic across all tests for the transaction
+func transactionBeforeEach() {
+	create.WriteFile = mocks.GetWriteFile()
+	create.Mkdir = mocks.GetMkdir()
+	create.Rename = mocks.GetRename()
+	create.Remove = mocks.GetRemove()
+	create.ReadFile = mocks.GetReadFile([]byte{})
+	create.FileStat = mocks.GetFileStat([]mocks.MockFileInfo{})
+	create.Chmod = mocks.GetChmod()
+}
+
+var transactionTestItems = []models.PlanItem{
+	{Path: "/project/dir1", IsDirectory: true},
+	{Path: "/project/dir1/file1.txt"},
+	{Path: "/project/dir1/dir2", IsDirectory: true},
+	{Path: "/project/dir1/dir2/file2.txt"},
+}
+
+// createTransactionTestItems creates all of the test items in the given transaction
+func createTransactionTestItems(t *testing.T, transaction *create.Transaction) {
+	for _, item := range transactionTestItems {
+		var err error
+		if item.IsDirectory {
+			err = transaction.Directory(item)
+		} else {
+			err = transaction.File(item)
+		}
+
+		if err != nil {
+			t.Fatalf("expected no error while creating '%s'. Got '%s'", item.Path, err.Error())
+		}
+	}
+}
+
+func TestTransactionRollbackWillRemoveCreatedPathsInReverseOrder(t *testing.T) {
+	transactionBeforeEach()
+
+	removedPaths := []string{}
+	create.Remove = func(path string) error {
+		removedPaths = append(removedPaths, path)
+		return nil
+	}
+
+	transaction := &create.Transaction{}
+	createTransactionTestItems(t, transaction)
+
+	if err := transaction.Rollback(); err != nil {
+		t.Errorf("expected no error from Rollback. Got '%s'", err.Error())
+	}
+
+	if len(removedPaths) != len(transactionTestItems) {
+		t.Errorf("expected %d paths to be removed. Got %d", len(transactionTestItems), len(removedPaths))
+		return
+	}
+
+	for i, removedPath := range removedPaths {
+		expectedPath := transactionTestItems[len(transactionTestItems)-1-i].Path
+		if removedPath != expectedPath {
+			t.Errorf("expected removed path %d to be '%s'. Got '%s'", i, expectedPath, removedPath)
+		}
+	}
+}
+
+func TestTransactionWillNotRecordItemsThatFailedToBeCreated(t *testing.T) {
+	transactionBeforeEach()
+
+	create.Mkdir = func(_ string, _ fs.FileMode) error {
+		return errors.New("my test error")
+	}
+
+	removeCalls := 0
+	create.Remove = func(path string) error {
+		removeCalls++
+		return nil
+	}
+
+	transaction := &create.Transaction{}
+	if err := transaction.Directory(transactionTestItems[0]); err == nil {
+		t.Errorf("expected the error from Mkdir to be returned. Got nil")
+	}
+
+	transaction.Rollback()
+
+	if removeCalls != 0 {
+		t.Errorf("expected nothing to be removed. Remove was called %d times", removeCalls)
+	}
+}
+
+func TestTransactionRollbackWillNotRemoveAnythingOnceCommitted(t *testing.T) {
+	transactionBeforeEach()
+
+	removeCalls := 0
+	create.Remove = func(path string) error {
+		removeCalls++
+		return nil
+	}
+
+	transaction := &create.Transaction{}
+	createTransactionTestItems(t, transaction)
+	transaction.Commit()
+	transaction.Rollback()
+
+	if removeCalls != 0 {
+		t.Errorf("expected nothing to be removed. Remove was called %d times", removeCalls)
+	}
+}
+
+func TestTransactionWillOnlyBeCommittedOnceCommitIsCalled(t *testing.T) {
+	transactionBeforeEach()
+
+	rolledBackTransaction := &create.Transaction{}
+	rolledBackTransaction.Rollback()
+	if rolledBackTransaction.IsCommitted() {
+		t.Errorf("expected a rolled back transaction not to be committed")
+	}
+
+	committedTransaction := &create.Transaction{}
+	committedTransaction.Commit()
+	committedTransaction.Rollback()
+	if !committedTransaction.IsCommitted() {
+		t.Errorf("expected the transaction to still be committed after a rollback")
+	}
+}
+
+func TestTransactionWillNotCreateItemsOnceRolledBack(t *testing.T) {
+	transactionBeforeEach()
+
+	mkdirCalls := 0
+	create.Mkdir = func(_ string, _ fs.FileMode) error {
+		mkdirCalls++
+		return nil
+	}
+
+	transaction := &create.Transaction{}
+	transaction.Rollback()
+
+	if err := transaction.Directory(transactionTestItems[0]); err == nil {
+		t.Errorf("expected an error when creating a directory after a rollback. Got nil")
+	}
+
+	if mkdirCalls != 0 {
+		t.Errorf("expected Mkdir not to be called. Was called %d times", mkdirCalls)
+	}
+}
+
+func TestTransactionRollbackWillReturnErrorsFromRemove(t *testing.T) {
+	transactionBeforeEach()
+
+	create.Remove = func(path string) error {
+		return errors.New("my test error")
+	}
+
+	transaction := &create.Transaction{}
+	createTransactionTestItems(t, transaction)
+
+	if err := transaction.Rollback(); err == nil {
+		t.Errorf("expected an error from Rollback. Got nil")
+	}
+}
+
+func TestTransactionRollbackWillRestoreOverwrittenFiles(t *testing.T) {
+	transactionBeforeEach()
+
+	originalContents := "my original contents"
+	create.ReadFile = mocks.GetReadFile([]byte(originalContents))
+
+	writtenContents := []string{}
+	create.WriteFile = func(_ string, data []byte, _ fs.FileMode) error {
+		writtenContents = append(writtenContents, string(data))
+		return nil
+	}
+
+	removeCalls := 0
+	create.Remove = func(path string) error {
+		removeCalls++
+		return nil
+	}
+
+	transaction := &create.Transaction{}
+	overwritingFile := models.PlanItem{
+		Path:      "/project/file1.txt",
+		Contents:  []byte("my new contents"),
+		Overwrite: true,
+	}
+
+	if err := transaction.File(overwritingFile); err != nil {
+		t.Fatalf("expected no error while creating file. Got '%s'", err.Error())
+	}
+
+	transaction.Rollback()
+
+	if len(writtenContents) != 2 || writtenContents[1] != originalContents {
+		t.Errorf("expected the original contents ('%s') to be written back. Got the writes %v", originalContents, writtenContents)
+	}
+
+	if removeCalls != 0 {
+		t.Errorf("expected the overwritten file not to be removed. Remove was called %d times", removeCalls)
+	}
+}
+
+func TestTransactionRollbackWillRestoreThePermissionsOfOverwrittenFiles(t *testing.T) {
+	transactionBeforeEach()
+
+	create.ReadFile = mocks.GetReadFile([]byte("#!/bin/sh"))
+
+	existingFileInfo := mocks.CreateMockInfo("/project/script.sh", false)
+	existingFileInfo.ModeValue = 0755
+	create.FileStat = mocks.GetFileStat([]mocks.MockFileInfo{existingFileInfo})
+
+	chmodModes := []fs.FileMode{}
+	create.Chmod = func(_ string, mode fs.FileMode) error {
+		chmodModes = append(chmodModes, mode)
+		return nil
+	}
+
+	transaction := &create.Transaction{}
+	overwritingFile := models.PlanItem{
+		Path:      "/project/script.sh",
+		Contents:  []byte("my new contents"),
+		Overwrite: true,
+	}
+
+	if err := transaction.File(overwritingFile); err != nil {
+		t.Fatalf("expected no error while creating file. Got '%s'", err.Error())
+	}
+
+	if err := transaction.Rollback(); err != nil {
+		t.Fatalf("expected no error while rolling back. Got '%s'", err.Error())
+	}
+
+	// The file keeps its permissions when it is overwritten, and again when it is restored
+	if !slices.Equal(chmodModes, []fs.FileMode{0755, 0755}) {
+		t.Errorf("expected the overwritten and restored file to be given its original permissions (0755). Got the modes %v", chmodModes)
+	}
+}
+
+func TestTransactionWillReuseExistingDirectoriesWithoutRecordingThem(t *testing.T) {
+	transactionBeforeEach()
+
+	existingDirectory := transactionTestItems[0]
+	create.FileStat = mocks.GetFileStat([]mocks.MockFileInfo{
+		mocks.CreateMockInfo(existingDirectory.Path, true),
+	})
+
+	mkdirPaths := []string{}
+	create.Mkdir = func(path string, _ fs.FileMode) error {
+		mkdirPaths = append(mkdirPaths, path)
+		return nil
+	}
+
+	removedPaths := []string{}
+	create.Remove = func(path string) error {
+		removedPaths = append(removedPaths, path)
+		return nil
+	}
+
+	transaction := &create.Transaction{}
+	createTransactionTestItems(t, transaction)
+	transaction.Rollback()
+
+	if slices.Contains(mkdirPaths, existingDirectory.Path) {
+		t.Errorf("expected the existing directory '%s' not to be created", existingDirectory.Path)
+	}
+
+	if slices.Contains(removedPaths, existingDirectory.Path) {
+		t.Errorf("expected the existing directory '%s' not to be removed by the rollback", existingDirectory.Path)
+	}
+
+	if len(removedPaths) != len(transactionTestItems)-1 {
+		t.Errorf("expected %d paths to be removed. Got %v", len(transactionTestItems)-1, removedPaths)
+	}
+}
@@ -0,0 +1,12 @@
+package customerrors
+
+import "strings"
+
+// MissingVariablesError represents variables that have no value, and couldn't be prompted for (e.g. in non-interactive mode)
+type MissingVariablesError struct {
+	Names []string
+}
+
+func (mve *MissingVariablesError) Error() string {
+	return "missing values for the variables: " + strings.Join(mve.Names, ", ")
+}
@@ -1,10 +1,36 @@
 package customerrors
 
+import (
+	"fmt"
+	"strings"
+)
+
 // ValidationError represents an error that occured when validating a model's data/structure
 type ValidationError struct {
-	Message string
+	Message  string
+	Location string // Where the invalid value is within the validated object, as a JSON path (e.g. "files[0].templatePath"), if known
 }
 
 func (ve *ValidationError) Error() string {
 	return ve.Message
 }
+
+// PrefixLocations returns copies of the given errors, with the given prefix (e.g. "files[0]") added to the start of their
+// locations. This is used when the errors come from validating an object within another object.
+func PrefixLocations(errs []ValidationError, prefix string) []ValidationError {
+	prefixed := make([]ValidationError, 0, len(errs))
+	for _, err := range errs {
+		switch {
+		case err.Location == "":
+			err.Location = prefix
+		case strings.HasPrefix(err.Location, "["):
+			err.Location = prefix + err.Location
+		default:
+			err.Location = fmt.Sprintf("%s.%s", prefix, err.Location)
+		}
+
+		prefixed = append(prefixed, err)
+	}
+
+	return prefixed
+}
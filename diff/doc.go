@@ -0,0 +1,2 @@
+// Package diff provides line-based comparisons of text (used when showing the user how a file would change)
+package diff
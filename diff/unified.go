@@ -0,0 +1,211 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+// noNewlineMarker is written after a line that isn't followed by a newline (the last line of a text without a trailing
+// newline)
+const noNewlineMarker = "\\ No newline at end of file\n"
+
+// edit is a single line in an edit script (a line that is kept, removed or added)
+type edit struct {
+	operation byte   // ' ' (kept), '-' (removed) or '+' (added)
+	text      string // The line, including its newline (if it has one)
+}
+
+// Unified returns a unified diff of the changes needed to turn the oldText into the newText.
+// The oldName and newName are used in the diff's header lines. If the texts are the same, an empty string is returned.
+func Unified(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	edits := editScript(splitLines(oldText), splitLines(newText))
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "--- %s\n+++ %s\n", oldName, newName)
+
+	// Walk through the edits, writing a hunk for each group of changes (and their surrounding context)
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(edits); {
+		if edits[i].operation == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// Find the start of the hunk (including the context before the change)
+		start := max(i-contextLines, 0)
+		hunkOldStart, hunkNewStart := oldLine-(i-start), newLine-(i-start)
+
+		// Find the end of the hunk (changes that are close enough together share a hunk)
+		end := i
+		unchangedCount := 0
+		for ; end < len(edits); end++ {
+			if edits[end].operation != ' ' {
+				unchangedCount = 0
+				continue
+			}
+
+			if unchangedCount == contextLines*2 {
+				break
+			}
+			unchangedCount++
+		}
+		if unchangedCount > contextLines {
+			end -= unchangedCount - contextLines
+		}
+
+		// Count the lines in the hunk, and write it
+		oldCount, newCount := 0, 0
+		for _, hunkEdit := range edits[start:end] {
+			if hunkEdit.operation != '+' {
+				oldCount++
+			}
+			if hunkEdit.operation != '-' {
+				newCount++
+			}
+		}
+
+		fmt.Fprintf(&builder, "@@ -%s +%s @@\n", hunkRange(hunkOldStart, oldCount), hunkRange(hunkNewStart, newCount))
+		for _, hunkEdit := range edits[start:end] {
+			builder.WriteByte(hunkEdit.operation)
+			builder.WriteString(strings.TrimSuffix(hunkEdit.text, "\n"))
+			builder.WriteByte('\n')
+
+			if !strings.HasSuffix(hunkEdit.text, "\n") {
+				builder.WriteString(noNewlineMarker)
+			}
+		}
+
+		// Move past the hunk
+		for _, hunkEdit := range edits[i:end] {
+			if hunkEdit.operation != '+' {
+				oldLine++
+			}
+			if hunkEdit.operation != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+
+	return builder.String()
+}
+
+// hunkRange formats the start line and line count of one side of a hunk header
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines splits the text into lines, which keep their newlines (a trailing newline does not create an extra, empty
+// line). This means a last line without a newline is different to the same line with one.
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// editScript returns the shortest list of edits that turn the oldLines into the newLines (using Myers' algorithm)
+func editScript(oldLines, newLines []string) []edit {
+	oldLen, newLen := len(oldLines), len(newLines)
+	maxSteps := oldLen + newLen
+	offset := maxSteps + 1
+
+	// furthest[k+offset] holds the furthest position in oldLines reached on diagonal k.
+	// A copy is kept after each step, so the path can be traced back afterwards.
+	furthest := make([]int, 2*maxSteps+3)
+	trace := [][]int{}
+
+	for steps := 0; steps <= maxSteps; steps++ {
+		trace = append(trace, append([]int(nil), furthest...))
+
+		for k := -steps; k <= steps; k += 2 {
+			var x int
+			if k == -steps || (k != steps && furthest[k-1+offset] < furthest[k+1+offset]) {
+				x = furthest[k+1+offset] // Move down (an added line)
+			} else {
+				x = furthest[k-1+offset] + 1 // Move right (a removed line)
+			}
+
+			y := x - k
+			for x < oldLen && y < newLen && oldLines[x] == newLines[y] {
+				x++
+				y++
+			}
+
+			furthest[k+offset] = x
+
+			if x >= oldLen && y >= newLen {
+				return backtrack(trace, oldLines, newLines, offset)
+			}
+		}
+	}
+
+	return nil
+}
+
+// backtrack walks back through the trace from editScript, building the list of edits
+func backtrack(trace [][]int, oldLines, newLines []string, offset int) []edit {
+	edits := []edit{}
+	x, y := len(oldLines), len(newLines)
+
+	for steps := len(trace) - 1; steps >= 0; steps-- {
+		furthest := trace[steps]
+		k := x - y
+
+		var previousK int
+		if k == -steps || (k != steps && furthest[k-1+offset] < furthest[k+1+offset]) {
+			previousK = k + 1
+		} else {
+			previousK = k - 1
+		}
+
+		previousX := furthest[previousK+offset]
+		previousY := previousX - previousK
+
+		for x > previousX && y > previousY {
+			x--
+			y--
+			edits = append(edits, edit{' ', oldLines[x]})
+		}
+
+		if steps > 0 {
+			if x == previousX {
+				edits = append(edits, edit{'+', newLines[previousY]})
+			} else {
+				edits = append(edits, edit{'-', oldLines[previousX]})
+			}
+		}
+
+		x, y = previousX, previousY
+	}
+
+	// The edits were built from the end, so reverse them
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+
+	return edits
+}
@@ -0,0 +1,100 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/diff"
+)
+
+func TestUnifiedWillReturnEmptyStringIfTextsAreTheSame(t *testing.T) {
+	result := diff.Unified("old", "new", "line 1\nline 2\n", "line 1\nline 2\n")
+
+	if result != "" {
+		t.Errorf("expected an empty string. Got '%s'", result)
+	}
+}
+
+func TestUnifiedWillShowChangedLinesWithContext(t *testing.T) {
+	oldText := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	newText := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n"
+
+	expected := `--- old.txt
++++ new.txt
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`
+
+	result := diff.Unified("old.txt", "new.txt", oldText, newText)
+
+	if result != expected {
+		t.Errorf("expected diff to be:\n%s\nGot:\n%s", expected, result)
+	}
+}
+
+func TestUnifiedWillSplitChangesThatAreFarApartIntoSeparateHunks(t *testing.T) {
+	oldText := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	newText := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\nthirteen\n"
+
+	expected := `--- old.txt
++++ new.txt
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++thirteen
+`
+
+	result := diff.Unified("old.txt", "new.txt", oldText, newText)
+
+	if result != expected {
+		t.Errorf("expected diff to be:\n%s\nGot:\n%s", expected, result)
+	}
+}
+
+func TestUnifiedWillShowAllLinesAsAddedIfOldTextIsEmpty(t *testing.T) {
+	expected := `--- old.txt
++++ new.txt
+@@ -0,0 +1,2 @@
++line 1
++line 2
+`
+
+	result := diff.Unified("old.txt", "new.txt", "", "line 1\nline 2\n")
+
+	if result != expected {
+		t.Errorf("expected diff to be:\n%s\nGot:\n%s", expected, result)
+	}
+}
+
+func TestUnifiedWillShowAChangeToTheTrailingNewline(t *testing.T) {
+	oldText := "line 1\nline 2\n"
+	newText := "line 1\nline 2"
+
+	expected := `--- old.txt
++++ new.txt
+@@ -1,2 +1,2 @@
+ line 1
+-line 2
++line 2
+\ No newline at end of file
+`
+
+	result := diff.Unified("old.txt", "new.txt", oldText, newText)
+
+	if result != expected {
+		t.Errorf("expected diff to be:\n%s\nGot:\n%s", expected, result)
+	}
+}
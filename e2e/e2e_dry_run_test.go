@@ -0,0 +1,51 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDryRunWillPrintPlanWithoutCreatingAnything(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	commandName := "command1"
+	expectedLines := []string{
+		".",
+		"├── myFile1.txt  (template: ",
+		"├── empty_dir/",
+		"└── my_val3_dir/",
+		"    ├── my_val1_file.txt  (template: ",
+		"    └── my_val2_file.txt  (template: ",
+	}
+
+	output, errOutput, err := runShellCmd(scaffoldRunPath, "./scaff", []string{}, commandName, "var1=val1", "var2=val2", "var3=val3", "--dry-run")
+	if err != nil {
+		t.Errorf("error while running command: %v", err.Error())
+		return
+	}
+
+	if len(errOutput) > 0 {
+		t.Errorf("expected nothing to be output on Stderr. got '%v'", errOutput)
+	}
+
+	outputLines := strings.Split(strings.TrimSpace(strings.ReplaceAll(output, "\r\n", "\n")), "\n")
+	if len(outputLines) != len(expectedLines) {
+		t.Errorf("expected %d lines of output. got %d: %s", len(expectedLines), len(outputLines), output)
+		return
+	}
+
+	for i, expectedLine := range expectedLines {
+		if !strings.HasPrefix(outputLines[i], expectedLine) {
+			t.Errorf("expected line %d to start with '%s'. got '%s'", i, expectedLine, outputLines[i])
+		}
+	}
+
+	// Confirm nothing was created
+	for _, name := range []string{"myFile1.txt", "empty_dir", "my_val3_dir"} {
+		if _, statErr := os.Stat(filepath.Join(scaffoldRunPath, name)); statErr == nil {
+			t.Errorf("expected '%s' not to be created during a dry run", name)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package e2e
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
 	"slices"
@@ -148,3 +150,73 @@ func TestWillPrintValidationErrorsIfInvalidCommand(t *testing.T) {
 		}
 	}
 }
+
+func TestWillPrintErrorAndCreateNothingIfPathWouldEscapeWorkingDirectory(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	output, errOutput, err := runShellCmd(scaffoldRunPath, "./scaff", []string{}, "command1", "var1=val1", "var2=val2", "var3=/../../../escaped")
+	if err != nil {
+		t.Errorf("error while running command: %v", err.Error())
+		return
+	}
+
+	if len(output) > 0 {
+		t.Errorf("expected no output on stdout. got '%v'", output)
+	}
+
+	expectedErrText := "is populated with a '..' segment"
+	if !strings.Contains(errOutput, expectedErrText) {
+		t.Errorf("expected error output to contain '%v'. got '%v'", expectedErrText, errOutput)
+	}
+
+	// Confirm nothing was created (in the working directory, or outside of it)
+	for _, name := range []string{"myFile1.txt", "empty_dir", "../../escaped_dir"} {
+		if _, statErr := os.Stat(filepath.Join(scaffoldRunPath, name)); statErr == nil {
+			t.Errorf("expected '%s' not to be created", name)
+		}
+	}
+}
+
+func TestWillPrintErrorIfDeclaredVarGivenInvalidValue(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	output, errOutput, err := runShellCmd(scaffoldRunPath, "./scaff", []string{}, "declaredVars", "var1=ten", "var2=val2")
+	if err != nil {
+		t.Errorf("error while running command: %v", err.Error())
+		return
+	}
+
+	if len(output) > 0 {
+		t.Errorf("expected no output on stdout. got '%v'", output)
+	}
+
+	expectedErrText := "invalid value for the variable 'var1': 'ten' is not a whole number"
+	if strings.TrimSpace(errOutput) != expectedErrText {
+		t.Errorf("expected error output to be '%v'. got '%v'", expectedErrText, errOutput)
+	}
+}
+
+func TestWillPrintValidationErrorForInvalidTemplate(t *testing.T) {
+	// This directory has a scaff file with a command whose template has an unclosed tag
+	cmd := exec.Command("../../scaff", "invalidTemplate", "--no-input", "name=test")
+	cmd.Dir = filepath.Join(scaffoldRunPath, "scaff_files", "invalid_templates")
+
+	var errOutput strings.Builder
+	cmd.Stderr = &errOutput
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 5 {
+		t.Errorf("expected the exit code to be 5. got '%v'", err)
+	}
+
+	expectedErrText := "greeting.txt:1:7: the tag is missing its closing ':}'"
+	if !strings.HasSuffix(strings.TrimSpace(errOutput.String()), expectedErrText) {
+		t.Errorf("expected error output to end with '%s'. got '%s'", expectedErrText, errOutput.String())
+	}
+
+	if strings.Contains(errOutput.String(), "error while reading templates") {
+		t.Errorf("expected the error not to be reported as a read error. got '%s'", errOutput.String())
+	}
+}
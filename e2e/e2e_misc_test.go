@@ -1,6 +1,12 @@
 package e2e
 
 import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -8,7 +14,11 @@ import (
 func TestWillDisplayHelpText(t *testing.T) {
 	expectedOutText := `Creates directories and files in the current working directory, based on the structures defined in a scaff.json file (using the given variables).
 
-SCAFF [commandname] [variablename]=[variablevalue]
+SCAFF [commandname] [variablename]=[variablevalue] [flags]
+SCAFF list [--json]
+SCAFF which [commandname] [--json]
+SCAFF describe [commandname] [--json]
+SCAFF validate [path] [--json]
 
 SCAFF will work its way up the directory-tree, from the current working directory, searching for a scaff.json file that contains the requested command (if multiple commands are found with the same name, the first one in the array is used).
 For full instructions on how to structure commands in a scaff.json file, visit https://github.com/M-Derbyshire/scaff
@@ -21,6 +31,21 @@ var2="my longer value"
 
 You can provide multiple variables in this way. If a variable is needed, but not provided, SCAFF will prompt you to provide it.
 
+Subcommands (a command with one of these names can only be run by giving "--" before its name, e.g. "SCAFF -- list"):
+list - Lists every command that can be found from the current working directory, along with its description and the scaff file it is defined in. Commands that can't be used, because a command with the same name is found first, are marked as shadowed.
+which - Explains how the given command name is resolved: every directory that is checked for a scaff.json file, every scaff file (and child file) that is read, and every definition of the command (in precedence order), along with which one is used and its template directory.
+describe - Describes the given command without running it: its description, every variable that it declares or uses in its names/templates (along with any declared details), and the tree of directories/files it creates (with any tags in their names left as placeholders).
+validate - Validates the given scaff file (or the scaff file in the given directory, or the current directory by default), along with all of its child files, and every command in them. Every problem is reported (with its file, command and location), and the exit code is non-zero if any are found.
+
+Flags:
+--dry-run - Prints the directories/files that the command would create (and the templates they would use), without creating them.
+--on-conflict=[policy] - How to handle files that already exist (existing directories are reused). The policy can be "error" (the default -- nothing is created), "skip" (existing files are left as they are), "overwrite" (existing files are replaced), or "prompt" (you are shown the differences for each existing file, and asked whether to overwrite it, skip it, or keep both).
+--vars-file=[path] - Loads variables from a JSON, YAML or dotenv (".env") file. This can be given more than once (later files take precedence over earlier ones). Variables given as arguments take precedence over those in files.
+--no-input - Never prompts for variable values (this is also the case if the standard input is not a terminal). Any variables that are missing, and don't have a default value, are listed, and SCAFF exits without creating anything.
+--allow-outside-root - Allows the command to create directories/files outside of the current working directory (by default, a name that is populated with ".." segments, an absolute path, or a path through a symbolic link that leads outside of the current working directory, is rejected).
+--json - Outputs JSON, rather than text (for the "list", "which", "describe" and "validate" subcommands).
+--explain - Before running the command, outputs (to the standard error) the same explanation of how the command name is resolved as the "which" subcommand.
+
 For full instructions on the use of SCAFF, visit https://github.com/M-Derbyshire/scaff`
 
 	helpFlags := []string{
@@ -84,3 +109,233 @@ func TestWillDisplayVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestListWillPrintEveryReachableCommand(t *testing.T) {
+	expectedLines := []string{
+		"command1                       Creates a file, and a directory of files               scaff.json",
+		"childCommand1                                                                         scaff_files/child1.json",
+		"commandInParentDir                                                                    ../scaff.json",
+		"command1                       Shadowed by the command1 in the grandchild directory   ../scaff.json (shadowed by scaff.json)",
+		"childCommandInGrandparentDir                                                          ../../scaff_files/child1.json",
+	}
+
+	output, errOutput, err := runShellCmd(scaffoldRunPath, "./scaff", []string{}, "list")
+	if err != nil {
+		t.Errorf("error while running command: %v", err.Error())
+		return
+	}
+
+	if len(errOutput) > 0 {
+		t.Errorf("expected nothing to be output on Stderr. got '%v'", errOutput)
+	}
+
+	outputLines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	for _, expectedLine := range expectedLines {
+		if !slices.Contains(outputLines, expectedLine) {
+			t.Errorf("expected the output to contain the line '%s'. got:\n%s", expectedLine, output)
+		}
+	}
+}
+
+func TestListWillPrintCommandsAsJSON(t *testing.T) {
+	output, _, err := runShellCmd(scaffoldRunPath, "./scaff", []string{}, "list", "--json")
+	if err != nil {
+		t.Errorf("error while running command: %v", err.Error())
+		return
+	}
+
+	var listings []struct {
+		Name       string `json:"name"`
+		ScaffFile  string `json:"scaffFile"`
+		Shadowed   bool   `json:"shadowed"`
+		ShadowedBy string `json:"shadowedBy"`
+	}
+
+	if err := json.Unmarshal([]byte(output), &listings); err != nil {
+		t.Errorf("expected the output to be valid JSON. got '%s' (%v)", output, err)
+		return
+	}
+
+	shadowedCount := 0
+	for _, listing := range listings {
+		if listing.Name != "command1" {
+			continue
+		}
+
+		if listing.Shadowed {
+			shadowedCount++
+			if !strings.HasSuffix(listing.ScaffFile, "/child_dir/scaff.json") || !strings.HasSuffix(listing.ShadowedBy, "/grandchild_dir/scaff.json") {
+				t.Errorf("expected the command1 in child_dir to be shadowed by the one in grandchild_dir. got %+v", listing)
+			}
+		}
+	}
+
+	if shadowedCount != 1 {
+		t.Errorf("expected 1 shadowed command1. got %d", shadowedCount)
+	}
+}
+
+func TestWhichWillExplainHowTheCommandIsResolved(t *testing.T) {
+	output, errOutput, err := runShellCmd(scaffoldRunPath, "./scaff", []string{}, "which", "command1")
+	if err != nil {
+		t.Errorf("error while running command: %v", err.Error())
+		return
+	}
+
+	if len(errOutput) > 0 {
+		t.Errorf("expected nothing to be output on Stderr. got '%v'", errOutput)
+	}
+
+	expectedSuffixes := []string{
+		"/child_dir/grandchild_dir  (found)",
+		"/child_dir/grandchild_dir/scaff_files/child1.json  (child of ",
+		"/child_dir/grandchild_dir/scaff.json  (used)",
+		"template directory: ",
+		"/child_dir/scaff.json  (shadowed)",
+	}
+
+	for _, expectedSuffix := range expectedSuffixes {
+		if !strings.Contains(output, expectedSuffix) {
+			t.Errorf("expected the output to contain '%s'. got:\n%s", expectedSuffix, output)
+		}
+	}
+
+	if strings.Index(output, "(used)") > strings.Index(output, "(shadowed)") {
+		t.Errorf("expected the used definition to be listed first. got:\n%s", output)
+	}
+}
+
+func TestExplainFlagWillOutputTheResolutionBeforeRunningTheCommand(t *testing.T) {
+	output, errOutput, err := runShellCmd(scaffoldRunPath, "./scaff", []string{}, "command1", "var1=val1", "var2=val2", "var3=val3", "--dry-run", "--explain")
+	if err != nil {
+		t.Errorf("error while running command: %v", err.Error())
+		return
+	}
+
+	if !strings.Contains(errOutput, "Definitions of 'command1' (in precedence order):") {
+		t.Errorf("expected the explanation to be output on Stderr. got '%v'", errOutput)
+	}
+
+	if !strings.HasPrefix(output, ".\n") {
+		t.Errorf("expected the dry run to still be output. got '%v'", output)
+	}
+}
+
+func TestDescribeWillPrintTheVariablesAndTreeOfACommand(t *testing.T) {
+	output, errOutput, err := runShellCmd(scaffoldRunPath, "./scaff", []string{}, "describe", "declaredVars")
+	if err != nil {
+		t.Errorf("error while running command: %v", err.Error())
+		return
+	}
+
+	if len(errOutput) > 0 {
+		t.Errorf("expected nothing to be output on Stderr. got '%v'", errOutput)
+	}
+
+	expectedLines := []string{
+		"Command: declaredVars",
+		"Variables:",
+		"  var1 (int)",
+		"    The first variable",
+		"  var2 (string)",
+		"    default: defaultVal2",
+		"      - the file name 'declared_{: var1 :}_{: var2 :}.txt'",
+		"Creates:",
+		"└── declared_{: var1 :}_{: var2 :}.txt  (template: my_var2_file.txt)",
+	}
+
+	outputLines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	for _, expectedLine := range expectedLines {
+		if !slices.Contains(outputLines, expectedLine) {
+			t.Errorf("expected the output to contain the line '%s'. got:\n%s", expectedLine, output)
+		}
+	}
+}
+
+func TestValidateWillReportEveryProblemInTheScaffFiles(t *testing.T) {
+	cmd := exec.Command("./scaff", "validate")
+	cmd.Dir = scaffoldRunPath
+
+	var output strings.Builder
+	cmd.Stdout = &output
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 5 {
+		t.Errorf("expected the exit code to be 5. got '%v'", err)
+	}
+
+	// Every problem in the invalid command is reported (not just the first)
+	expectedLines := []string{
+		"scaff.json: command 'invalid_command': commands[12].templateDirectoryPath: command objects should have a 'templateDirectoryPath' property that is set to a non-empty value",
+		"scaff.json: command 'invalid_command': commands[12].files[0].name: file scaffold objects should have a 'name' property that is set to a non-empty value",
+		"scaff.json: command 'invalid_command': commands[12].files[0].templatePath: file scaffold objects should have a 'templatePath' property that is set to a non-empty value",
+		"scaff.json: command 'invalid_command': commands[12].directories[0].name: directory scaffold objects should have a 'name' property that is set to a non-empty value",
+		"4 problems found (checked 15 commands in 3 scaff files)",
+	}
+
+	outputLines := strings.Split(strings.ReplaceAll(output.String(), "\r\n", "\n"), "\n")
+	for _, expectedLine := range expectedLines {
+		if !slices.Contains(outputLines, expectedLine) {
+			t.Errorf("expected the output to contain the line '%s'. got:\n%s", expectedLine, output.String())
+		}
+	}
+}
+
+func TestValidateWillReportNoProblemsForAValidScaffFile(t *testing.T) {
+	output, errOutput, err := runShellCmd(scaffoldRunPath, "./scaff", []string{}, "validate", "scaff_files/child1.json")
+	if err != nil {
+		t.Errorf("error while running command: %v", err.Error())
+		return
+	}
+
+	if len(errOutput) > 0 {
+		t.Errorf("expected nothing to be output on Stderr. got '%v'", errOutput)
+	}
+
+	expectedOutput := "no problems found (checked 1 command in 1 scaff file)"
+	if strings.TrimSpace(output) != expectedOutput {
+		t.Errorf("expected the output to be '%s'. got '%s'", expectedOutput, output)
+	}
+}
+
+// This directory has a scaff file that defines a command with the same name as a subcommand
+var subcommandNamesRunPath = filepath.Join(scaffoldRunPath, "scaff_files", "subcommand_names")
+
+func TestSubcommandWillWarnIfACommandHasTheSameName(t *testing.T) {
+	output, errOutput, err := runShellCmd(subcommandNamesRunPath, "../../scaff", []string{}, "list")
+	if err != nil {
+		t.Errorf("error while running command: %v", err.Error())
+		return
+	}
+
+	expectedErrText := "warning: a scaff file defines a command named 'list', but this is the name of a subcommand (use 'scaff -- list' to run the command)"
+	if strings.TrimSpace(errOutput) != expectedErrText {
+		t.Errorf("expected error output to be '%s'. got '%s'", expectedErrText, errOutput)
+	}
+
+	// The subcommand is still run
+	if !strings.HasPrefix(output, "NAME") {
+		t.Errorf("expected the commands to be listed. got '%s'", output)
+	}
+}
+
+func TestDoubleDashWillRunACommandWithTheSameNameAsASubcommand(t *testing.T) {
+	createdPath := filepath.Join(subcommandNamesRunPath, "listed.txt")
+	defer os.Remove(createdPath)
+
+	_, errOutput, err := runShellCmd(subcommandNamesRunPath, "../../scaff", []string{}, "--", "list", "--no-input", "var1=val1", "var2=val2", "var3=val3")
+	if err != nil {
+		t.Errorf("error while running command: %v", err.Error())
+		return
+	}
+
+	if len(errOutput) > 0 {
+		t.Errorf("expected nothing to be output on Stderr. got '%v'", errOutput)
+	}
+
+	if _, statErr := os.Stat(createdPath); statErr != nil {
+		t.Errorf("expected the command to create '%s'. got '%v'", createdPath, statErr)
+	}
+}
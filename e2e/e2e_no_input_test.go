@@ -0,0 +1,103 @@
+package e2e
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNoInputWillListMissingVariablesAndCreateNothing(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	expectedErrLines := []string{
+		"unable to prompt for variables (input is disabled), so these variables must be provided:",
+		"  var2 (used in the template '",
+		"  var3 (used in the template '",
+	}
+
+	_, errOutput, err := runShellCmd(scaffoldRunPath, "./scaff", []string{}, "command1", "var1=val1", "--no-input")
+	if err != nil {
+		t.Errorf("error while running command: %v", err.Error())
+		return
+	}
+
+	errLines := strings.Split(strings.TrimSpace(strings.ReplaceAll(errOutput, "\r\n", "\n")), "\n")
+	if len(errLines) != len(expectedErrLines) {
+		t.Errorf("expected %d lines of error output. got %d: %s", len(expectedErrLines), len(errLines), errOutput)
+		return
+	}
+
+	for i, expectedErrLine := range expectedErrLines {
+		if !strings.HasPrefix(errLines[i], expectedErrLine) {
+			t.Errorf("expected error line %d to start with '%s'. got '%s'", i, expectedErrLine, errLines[i])
+		}
+	}
+
+	if _, statErr := os.Stat(filepath.Join(scaffoldRunPath, "myFile1.txt")); statErr == nil {
+		t.Errorf("expected nothing to be created")
+	}
+}
+
+func TestWillNotPromptIfStdinIsNotATerminal(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	// The Stdin is a pipe (rather than a terminal) in this case
+	cmd := exec.Command("./scaff", "command2")
+	cmd.Dir = scaffoldRunPath
+	cmd.Stdin = strings.NewReader("val1\nval2\nval3\n")
+
+	var errOutput strings.Builder
+	cmd.Stderr = &errOutput
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 8 {
+		t.Errorf("expected the exit code to be 8. got '%v'", err)
+	}
+
+	expectedErrText := "  var1 (used in the template '"
+	if !strings.Contains(errOutput.String(), expectedErrText) {
+		t.Errorf("expected error output to contain '%s'. got '%s'", expectedErrText, errOutput.String())
+	}
+}
+
+func TestWillNotPromptIfStdinIsDevNull(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	// The null device is a character device, but it isn't a terminal
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("unable to open the null device: %v", err)
+	}
+	defer devNull.Close()
+
+	cmd := exec.Command("./scaff", "command1", "var1=val1")
+	cmd.Dir = scaffoldRunPath
+	cmd.Stdin = devNull
+
+	var errOutput strings.Builder
+	cmd.Stderr = &errOutput
+
+	err = cmd.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 8 {
+		t.Errorf("expected the exit code to be 8. got '%v' (%s)", err, errOutput.String())
+	}
+
+	expectedErrTexts := []string{
+		"unable to prompt for variables (input is disabled), so these variables must be provided:",
+		"  var2 (used in ",
+		"  var3 (used in ",
+	}
+
+	for _, expectedErrText := range expectedErrTexts {
+		if !strings.Contains(errOutput.String(), expectedErrText) {
+			t.Errorf("expected error output to contain '%s'. got '%s'", expectedErrText, errOutput.String())
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package e2e
 
 import (
+	"errors"
 	"fmt"
+	"os/exec"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -32,13 +34,13 @@ func TestWillNotMakeChangesIfFilesOrDirectoriesAlreadyExist(t *testing.T) {
 
 	// This command will try to create files/directories that already exist in the environment
 	// directory. It will also try to create some that don't (those shouldn't get created, and
-	// any existing files should not be modified)
+	// any existing files should not be modified). Only the existing files should be reported,
+	// as existing directories are reused.
 	commandName := "preexistingPaths"
 
 	expectedErrTexts := []string{
 		fmt.Sprintf("path already exists: %s", filepath.Join(workingDir, "existing_file_1.txt")),
-		fmt.Sprintf("path already exists: %s", filepath.Join(workingDir, "existing_dir_1")),
-		fmt.Sprintf("path already exists: %s", filepath.Join(workingDir, "existing_dir_2")),
+		fmt.Sprintf("path already exists: %s", filepath.Join(workingDir, "existing_dir_2", "existing_file_2.txt")),
 	}
 
 	err = runScaffoldCommand(commandName, []string{}, "var1=val1", "var2=val2", "var3=val3", "file=file")
@@ -72,3 +74,89 @@ func TestWillNotMakeChangesIfFilesOrDirectoriesAlreadyExist(t *testing.T) {
 		t.Errorf("%s", diff)
 	}
 }
+
+func TestWillSkipExistingPathsWithSkipConflictPolicy(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+	setupPreexistingPathsEnvironment(t)
+
+	err := runScaffoldCommand("preexistingPaths", []string{}, "var1=val1", "var2=val2", "var3=val3", "file=file", "--on-conflict=skip")
+	if err != nil {
+		t.Errorf("error while running scaff command: %v", err.Error())
+		return
+	}
+
+	// The existing paths should be unchanged, and the others created
+	diffs, err := diffScaffoldCommand("preexistingPathsSkip")
+	if err != nil {
+		t.Errorf("error while diffing results of scaff command: %v", err.Error())
+		return
+	}
+
+	for _, diff := range diffs {
+		t.Errorf("%s", diff)
+	}
+}
+
+func TestWillOverwriteExistingFilesWithOverwriteConflictPolicy(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+	setupPreexistingPathsEnvironment(t)
+
+	err := runScaffoldCommand("preexistingPaths", []string{}, "var1=val1", "var2=val2", "var3=val3", "file=file", "--on-conflict=overwrite")
+	if err != nil {
+		t.Errorf("error while running scaff command: %v", err.Error())
+		return
+	}
+
+	// The existing directories should be reused, and the existing files replaced
+	diffs, err := diffScaffoldCommand("preexistingPathsOverwrite")
+	if err != nil {
+		t.Errorf("error while diffing results of scaff command: %v", err.Error())
+		return
+	}
+
+	for _, diff := range diffs {
+		t.Errorf("%s", diff)
+	}
+}
+
+func TestWillNotPromptAboutExistingFilesIfInputIsDisabled(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+	setupPreexistingPathsEnvironment(t)
+
+	// An answer is piped in, but it shouldn't be used (the existing files are listed instead)
+	cmd := exec.Command("./scaff", "preexistingPaths", "var1=val1", "var2=val2", "var3=val3", "file=file", "--on-conflict=prompt", "--no-input")
+	cmd.Dir = scaffoldRunPath
+	cmd.Stdin = strings.NewReader("o\no\n")
+
+	var errOutput strings.Builder
+	cmd.Stderr = &errOutput
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 6 {
+		t.Errorf("expected the exit code to be 6. got '%v'", err)
+	}
+
+	expectedErrTexts := []string{
+		"unable to prompt about existing files (input is disabled), so nothing has been created:",
+		"path already exists: ",
+	}
+
+	for _, expectedErrText := range expectedErrTexts {
+		if !strings.Contains(errOutput.String(), expectedErrText) {
+			t.Errorf("expected error output to contain '%s'. got '%s'", expectedErrText, errOutput.String())
+		}
+	}
+
+	// Nothing should have been overwritten
+	diffs, err := diffScaffoldCommand("preexistingPaths")
+	if err != nil {
+		t.Errorf("error while diffing results of scaff command: %v", err.Error())
+		return
+	}
+
+	for _, diff := range diffs {
+		t.Errorf("%s", diff)
+	}
+}
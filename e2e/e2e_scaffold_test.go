@@ -1,6 +1,9 @@
 package e2e
 
 import (
+	"os"
+	"path"
+	"runtime"
 	"testing"
 )
 
@@ -114,3 +117,248 @@ func TestScaffoldCommandsWithoutUserVars(t *testing.T) {
 		})
 	}
 }
+
+func TestScaffoldFromCommandWillPromptForDeclaredVarsUntilValid(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	commandName := "declaredVars"
+	userVariableValues := []string{"ten", "10", ""} // The first value is invalid, and the last should be replaced with the default
+
+	err := runScaffoldCommand(commandName, userVariableValues)
+	if err != nil {
+		t.Errorf("error while running scaff command: %v", err.Error())
+		return
+	}
+
+	diffs, err := diffScaffoldCommand(commandName)
+	if err != nil {
+		t.Errorf("error while diffing results of scaff command: %v", err.Error())
+		return
+	}
+
+	for _, diff := range diffs {
+		t.Errorf("%s", diff)
+	}
+}
+
+func TestWillCreateScaffoldFromCommandWithVarsFiles(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	commandName := "command1"
+
+	err := runScaffoldCommand(
+		commandName,
+		[]string{},
+		"var1=val1",
+		"--vars-file", "scaff_files/vars_files/command1.yaml",
+		"--vars-file=scaff_files/vars_files/command1.env",
+	)
+	if err != nil {
+		t.Errorf("error while running scaff command: %v", err.Error())
+		return
+	}
+
+	diffs, err := diffScaffoldCommand(commandName)
+	if err != nil {
+		t.Errorf("error while diffing results of scaff command: %v", err.Error())
+		return
+	}
+
+	for _, diff := range diffs {
+		t.Errorf("%s", diff)
+	}
+}
+
+func TestWillCreateScaffoldFromCommandWithConditionalAndRangeBlocks(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	commandName := "blocks"
+
+	err := runScaffoldCommand(commandName, []string{}, "--vars-file=scaff_files/vars_files/blocks.json")
+	if err != nil {
+		t.Errorf("error while running scaff command: %v", err.Error())
+		return
+	}
+
+	diffs, err := diffScaffoldCommand(commandName)
+	if err != nil {
+		t.Errorf("error while diffing results of scaff command: %v", err.Error())
+		return
+	}
+
+	for _, diff := range diffs {
+		t.Errorf("%s", diff)
+	}
+}
+
+func TestWillCreateScaffoldFromCommandWithIncludedPartials(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	commandName := "partials"
+
+	err := runScaffoldCommand(commandName, []string{}, "name=things", "owner=Me")
+	if err != nil {
+		t.Errorf("error while running scaff command: %v", err.Error())
+		return
+	}
+
+	diffs, err := diffScaffoldCommand(commandName)
+	if err != nil {
+		t.Errorf("error while diffing results of scaff command: %v", err.Error())
+		return
+	}
+
+	for _, diff := range diffs {
+		t.Errorf("%s", diff)
+	}
+}
+
+func TestWillCreateScaffoldFromCommandThatUsesTheGoTemplateEngine(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	commandName := "goTemplate"
+
+	err := runScaffoldCommand(commandName, []string{}, "name=user-profile", "fields=first-name,last_name")
+	if err != nil {
+		t.Errorf("error while running scaff command: %v", err.Error())
+		return
+	}
+
+	diffs, err := diffScaffoldCommand(commandName)
+	if err != nil {
+		t.Errorf("error while diffing results of scaff command: %v", err.Error())
+		return
+	}
+
+	for _, diff := range diffs {
+		t.Errorf("%s", diff)
+	}
+}
+
+func TestWillCreateScaffoldFromCommandWithCustomDelimiters(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	commandName := "customDelimiters"
+
+	err := runScaffoldCommand(commandName, []string{}, "name=myService")
+	if err != nil {
+		t.Errorf("error while running scaff command: %v", err.Error())
+		return
+	}
+
+	diffs, err := diffScaffoldCommand(commandName)
+	if err != nil {
+		t.Errorf("error while diffing results of scaff command: %v", err.Error())
+		return
+	}
+
+	for _, diff := range diffs {
+		t.Errorf("%s", diff)
+	}
+}
+
+func TestWillCopyRawAndBinaryFilesAsTheyAre(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	commandName := "rawFiles"
+
+	err := runScaffoldCommand(commandName, []string{}, "--no-input", "name=logo")
+	if err != nil {
+		t.Errorf("error while running scaff command: %v", err.Error())
+		return
+	}
+
+	diffs, err := diffScaffoldCommand(commandName)
+	if err != nil {
+		t.Errorf("error while diffing results of scaff command: %v", err.Error())
+		return
+	}
+
+	for _, diff := range diffs {
+		t.Errorf("%s", diff)
+	}
+
+	scriptInfo, err := os.Stat(path.Join(scaffoldRunPath, "logo.sh"))
+	if err != nil {
+		t.Errorf("unable to check the copied script: %v", err.Error())
+		return
+	}
+
+	if runtime.GOOS != "windows" && scriptInfo.Mode().Perm()&0111 == 0 {
+		t.Errorf("expected the copied script to keep its executable permissions. Got %v", scriptInfo.Mode().Perm())
+	}
+}
+
+func TestWillCreateScaffoldFromCommandWithTemplateTrees(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	commandName := "templateTree"
+
+	err := runScaffoldCommand(commandName, []string{}, "name=myService")
+	if err != nil {
+		t.Errorf("error while running scaff command: %v", err.Error())
+		return
+	}
+
+	diffs, err := diffScaffoldCommand(commandName)
+	if err != nil {
+		t.Errorf("error while diffing results of scaff command: %v", err.Error())
+		return
+	}
+
+	for _, diff := range diffs {
+		t.Errorf("%s", diff)
+	}
+}
+
+func TestWillOnlyCreateFilesAndDirectoriesWhoseConditionsAreTrue(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	commandName := "conditionalFiles"
+
+	// The "cliName" variable is only used in the skipped "cli" directory, so it isn't needed
+	err := runScaffoldCommand(commandName, []string{}, "--no-input", "name=orderService", "kind=api", "withDocker=true")
+	if err != nil {
+		t.Errorf("error while running scaff command: %v", err.Error())
+		return
+	}
+
+	diffs, err := diffScaffoldCommand(commandName)
+	if err != nil {
+		t.Errorf("error while diffing results of scaff command: %v", err.Error())
+		return
+	}
+
+	for _, diff := range diffs {
+		t.Errorf("%s", diff)
+	}
+}
+
+func TestWillRepeatFilesAndDirectoriesForEachItemInTheirLists(t *testing.T) {
+	e2eScaffoldBeforeEach(t)
+
+	commandName := "forEachFiles"
+
+	// The modules are given on the command line, and the entities in a vars file
+	err := runScaffoldCommand(
+		commandName,
+		[]string{},
+		"--no-input",
+		"modules=billing,shop",
+		"--vars-file=scaff_files/vars_files/forEachFiles.json",
+	)
+	if err != nil {
+		t.Errorf("error while running scaff command: %v", err.Error())
+		return
+	}
+
+	diffs, err := diffScaffoldCommand(commandName)
+	if err != nil {
+		t.Errorf("error while diffing results of scaff command: %v", err.Error())
+		return
+	}
+
+	for _, diff := range diffs {
+		t.Errorf("%s", diff)
+	}
+}
@@ -0,0 +1,4 @@
+package billing
+
+// OrderItem is model 1 of the billing module
+type OrderItem struct{}
@@ -0,0 +1,4 @@
+package billing
+
+// User is model 0 of the billing module
+type User struct{}
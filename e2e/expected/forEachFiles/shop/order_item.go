@@ -0,0 +1,4 @@
+package shop
+
+// OrderItem is model 1 of the shop module
+type OrderItem struct{}
@@ -0,0 +1,4 @@
+package shop
+
+// User is model 0 of the shop module
+type User struct{}
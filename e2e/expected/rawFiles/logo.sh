@@ -0,0 +1,3 @@
+#!/bin/sh
+# This is copied as it is: {: name :} {: if :}
+echo "done"
@@ -5,6 +5,10 @@ scriptDir=$(dirname "$(realpath $0)")
 mkdir -p $scriptDir/expected/command1/empty_dir
 mkdir -p $scriptDir/expected/childCommand1/empty_dir
 mkdir -p $scriptDir/expected/preexistingPaths/existing_dir_1
+mkdir -p $scriptDir/expected/preexistingPathsSkip/existing_dir_1
+mkdir -p $scriptDir/expected/preexistingPathsSkip/non_existing_dir_1
+mkdir -p $scriptDir/expected/preexistingPathsOverwrite/existing_dir_1
+mkdir -p $scriptDir/expected/preexistingPathsOverwrite/non_existing_dir_1
 
 
 
@@ -2,4 +2,9 @@ module github.com/M-Derbyshire/scaff
 
 go 1.25
 
-require github.com/creack/pty v1.1.24
+require (
+	github.com/creack/pty v1.1.24
+	golang.org/x/term v0.36.0
+)
+
+require golang.org/x/sys v0.37.0 // indirect
@@ -4,7 +4,11 @@ package help
 func Text() string {
 	return `Creates directories and files in the current working directory, based on the structures defined in a scaff.json file (using the given variables).
 
-SCAFF [commandname] [variablename]=[variablevalue]
+SCAFF [commandname] [variablename]=[variablevalue] [flags]
+SCAFF list [--json]
+SCAFF which [commandname] [--json]
+SCAFF describe [commandname] [--json]
+SCAFF validate [path] [--json]
 
 SCAFF will work its way up the directory-tree, from the current working directory, searching for a scaff.json file that contains the requested command (if multiple commands are found with the same name, the first one in the array is used).
 For full instructions on how to structure commands in a scaff.json file, visit https://github.com/M-Derbyshire/scaff
@@ -17,5 +21,20 @@ var2="my longer value"
 
 You can provide multiple variables in this way. If a variable is needed, but not provided, SCAFF will prompt you to provide it.
 
+Subcommands (a command with one of these names can only be run by giving "--" before its name, e.g. "SCAFF -- list"):
+list - Lists every command that can be found from the current working directory, along with its description and the scaff file it is defined in. Commands that can't be used, because a command with the same name is found first, are marked as shadowed.
+which - Explains how the given command name is resolved: every directory that is checked for a scaff.json file, every scaff file (and child file) that is read, and every definition of the command (in precedence order), along with which one is used and its template directory.
+describe - Describes the given command without running it: its description, every variable that it declares or uses in its names/templates (along with any declared details), and the tree of directories/files it creates (with any tags in their names left as placeholders).
+validate - Validates the given scaff file (or the scaff file in the given directory, or the current directory by default), along with all of its child files, and every command in them. Every problem is reported (with its file, command and location), and the exit code is non-zero if any are found.
+
+Flags:
+--dry-run - Prints the directories/files that the command would create (and the templates they would use), without creating them.
+--on-conflict=[policy] - How to handle files that already exist (existing directories are reused). The policy can be "error" (the default -- nothing is created), "skip" (existing files are left as they are), "overwrite" (existing files are replaced), or "prompt" (you are shown the differences for each existing file, and asked whether to overwrite it, skip it, or keep both).
+--vars-file=[path] - Loads variables from a JSON, YAML or dotenv (".env") file. This can be given more than once (later files take precedence over earlier ones). Variables given as arguments take precedence over those in files.
+--no-input - Never prompts for variable values (this is also the case if the standard input is not a terminal). Any variables that are missing, and don't have a default value, are listed, and SCAFF exits without creating anything.
+--allow-outside-root - Allows the command to create directories/files outside of the current working directory (by default, a name that is populated with ".." segments, an absolute path, or a path through a symbolic link that leads outside of the current working directory, is rejected).
+--json - Outputs JSON, rather than text (for the "list", "which", "describe" and "validate" subcommands).
+--explain - Before running the command, outputs (to the standard error) the same explanation of how the command name is resolved as the "which" subcommand.
+
 For full instructions on the use of SCAFF, visit https://github.com/M-Derbyshire/scaff`
 }
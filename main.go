@@ -3,12 +3,20 @@ package main
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
 
 	"github.com/M-Derbyshire/scaff/command"
+	"github.com/M-Derbyshire/scaff/conflict"
+	"github.com/M-Derbyshire/scaff/create"
 	"github.com/M-Derbyshire/scaff/customerrors"
 	"github.com/M-Derbyshire/scaff/help"
+	"github.com/M-Derbyshire/scaff/models"
+	"github.com/M-Derbyshire/scaff/options"
 	"github.com/M-Derbyshire/scaff/variable"
 )
 
@@ -44,16 +52,48 @@ func main() {
 		return
 	}
 
-	//Get the variables from the args
-	var varMap map[string]string
+	//Separate the flags from the command name and variables
+	opts, args, err := options.Parse(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "please provide the name of the command to process (or use '--help')")
+		os.Exit(1)
+	}
+
+	// Run any subcommand (these are given in place of a command name, unless "--" is given)
+	if runSubcommand, isSubcommand := subcommands[args[0]]; isSubcommand && !opts.NotSubcommand {
+		warnIfCommandIsHidden(args[0], scaffFileNameAndExt, workingDir)
+		runSubcommand(opts, args[1:], scaffFileNameAndExt, workingDir)
+		return
+	}
+
+	//Get the variables from any vars files (later files take precedence), then from the args (which take precedence over the files)
+	varMap := make(map[string]string)
+	for _, varsFilePath := range opts.VarsFiles {
+		fileVars, err := variable.LoadFile(varsFilePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error while loading vars file '%s': %v\n", varsFilePath, err)
+			os.Exit(1)
+		}
+
+		maps.Copy(varMap, fileVars)
+	}
+
 	if len(args) > 1 { //first is the command name
-		varMap = variable.Map(args[1:])
-	} else {
-		varMap = make(map[string]string)
+		maps.Copy(varMap, variable.Map(args[1:]))
 	}
 
 	//Look for the command
 	commandName := args[0]
+	if opts.Explain {
+		// The trace is output to the Stderr, so it isn't mixed with any other output (e.g. from a dry run)
+		resolution, _ := command.Explain(commandName, scaffFileNameAndExt, workingDir)
+		fmt.Fprintln(os.Stderr, command.FormatResolution(resolution))
+	}
+
 	commandToProcess, fullTemplatePath, isFound, err := command.Find(commandName, scaffFileNameAndExt, workingDir)
 	if err != nil {
 		var validationErr *customerrors.ValidationError
@@ -79,22 +119,202 @@ func main() {
 		os.Exit(5)
 	}
 
-	// Confirm that no files/directories in the command already exist
-	existingPaths, err := command.IdentifyExistingPaths(commandToProcess, workingDir, varMap)
+	// Add the contents of any template trees to the command's directories/files
+	commandToProcess, err = commandToProcess.ExpandTemplateTrees(fullTemplatePath)
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, "error while reading templates:", err.Error())
+		os.Exit(7)
+	}
+
+	// Find every variable the command uses, so any that are missing can be prompted for before anything is generated
+	// (declared variables are prompted for first, in the order they are declared)
+	usages, err := commandToProcess.VariableUsages(fullTemplatePath)
+	if err != nil {
+		exitOnTemplateError(err)
+	}
+
+	// Prompting is only possible if the Stdin is a terminal (e.g. not in a CI pipeline)
+	noInput := opts.NoInput || !variable.IsTerminal(variable.Stdin)
+	commandToProcess.NoInput = noInput
+
+	// The variables used in "forEach" lists and "when" conditions are resolved first, so the files/directories can be
+	// repeated, and those that won't be created (and any variables that are only used within them) can be skipped
+	definitions := commandToProcess.VariableDefinitions(usages)
+	structureNames := commandToProcess.StructureNames()
+	structureDefinitions := slices.DeleteFunc(slices.Clone(definitions), func(definition variable.Definition) bool {
+		return !slices.Contains(structureNames, definition.Name)
+	})
+
+	if err := variable.Resolve(structureDefinitions, varMap, noInput); err != nil {
+		// If any are missing, the rest of the missing variables are also listed
+		var missingErr *customerrors.MissingVariablesError
+		if errors.As(err, &missingErr) {
+			err = variable.Resolve(definitions, varMap, true)
+		}
+
+		exitOnResolveError(err, usages)
+	}
+
+	commandToProcess, err = commandToProcess.Expand(varMap)
+	if err != nil {
+		exitOnMissingVariables(err, usages)
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(5)
+	}
+
+	usages, err = commandToProcess.VariableUsages(fullTemplatePath)
+	if err != nil {
+		exitOnTemplateError(err)
+	}
+
+	if err := variable.Resolve(commandToProcess.VariableDefinitions(usages), varMap, noInput); err != nil {
+		exitOnResolveError(err, usages)
+	}
+
+	//Work out everything the command will generate
+	plan, err := command.Plan(commandToProcess, workingDir, fullTemplatePath, varMap, opts.AllowOutsideRoot)
+	if err != nil {
+		exitOnMissingVariables(err, usages)
+
+		var validationErr *customerrors.ValidationError
+		if errors.As(err, &validationErr) {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(5)
+		}
+
+		fmt.Fprintln(os.Stderr, "error while generating:", err.Error())
+		os.Exit(7)
 	}
+
+	if !opts.AllowOutsideRoot {
+		if containmentErr := command.CheckContainment(plan, workingDir); containmentErr != nil {
+			fmt.Fprintln(os.Stderr, containmentErr.Error())
+			os.Exit(5)
+		}
+	}
+
+	if opts.DryRun {
+		fmt.Print(command.FormatPlan(plan, workingDir))
+		return
+	}
+
+	// Handle any files in the command that already exist (using the conflict policy). Existing directories are reused
+	existingPaths := command.IdentifyExistingPaths(plan)
 	if len(existingPaths) > 0 {
-		for _, path := range existingPaths {
-			fmt.Fprintln(os.Stderr, "path already exists:", path)
+		// If input is disabled, the user can't be prompted, so the existing files are listed (as with the error policy)
+		if opts.OnConflict == models.ConflictError || (opts.OnConflict == models.ConflictPrompt && noInput) {
+			if opts.OnConflict == models.ConflictPrompt {
+				fmt.Fprintln(os.Stderr, "unable to prompt about existing files (input is disabled), so nothing has been created:")
+			}
+
+			for _, path := range existingPaths {
+				fmt.Fprintln(os.Stderr, "path already exists:", path)
+			}
+
+			os.Exit(6)
 		}
 
-		os.Exit(6)
+		plan, err = conflict.Resolve(plan, existingPaths, opts.OnConflict, noInput)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(6)
+		}
 	}
 
-	//Process command
-	err = command.Process(commandToProcess, workingDir, fullTemplatePath, varMap)
+	//Process command. If it fails (or is interrupted), anything that was created is removed
+	transaction := &create.Transaction{}
+	stopRollbackOnSignal := rollbackOnSignal(transaction)
+
+	err = command.Process(plan, transaction)
+	stopRollbackOnSignal()
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, "error while generating:", err.Error())
+		os.Exit(7)
+	}
+}
+
+// rollbackOnSignal rolls back the given transaction (and exits) if the application is interrupted or terminated, until the
+// returned stop func is called
+func rollbackOnSignal(transaction *create.Transaction) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		if _, isOpen := <-signals; !isOpen {
+			return
+		}
+
+		err := transaction.Rollback()
+
+		// If the transaction was committed before the signal was handled, everything has been generated (so nothing is cancelled)
+		if transaction.IsCommitted() {
+			return
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "generation cancelled, but failed to remove the partially generated paths:", err.Error())
+		} else {
+			fmt.Fprintln(os.Stderr, "generation cancelled. no files/directories have been left behind")
+		}
+
+		os.Exit(130)
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(signals)
+	}
+}
+
+// exitOnTemplateError outputs an error from reading the templates, and exits with the matching exit code (a problem in a
+// template, such as an unclosed tag, is a ValidationError)
+func exitOnTemplateError(err error) {
+	var validationErr *customerrors.ValidationError
+	if errors.As(err, &validationErr) {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(5)
+	}
+
+	fmt.Fprintln(os.Stderr, "error while reading templates:", err.Error())
+	os.Exit(7)
+}
+
+// exitOnResolveError outputs an error from variable.Resolve, and exits with the matching exit code
+func exitOnResolveError(err error, usages []models.VariableUsage) {
+	var validationErr *customerrors.ValidationError
+	if errors.As(err, &validationErr) {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(5)
+	}
+
+	exitOnMissingVariables(err, usages)
+
+	fmt.Fprintln(os.Stderr, "error while reading variables:", err.Error())
+	os.Exit(7)
+}
+
+// exitOnMissingVariables lists the missing variables and exits, if the given error is a MissingVariablesError (these can
+// also be found while rendering, if input is disabled and a variable wasn't found when the templates were scanned)
+func exitOnMissingVariables(err error, usages []models.VariableUsage) {
+	var missingErr *customerrors.MissingVariablesError
+	if errors.As(err, &missingErr) {
+		printMissingVariables(missingErr.Names, usages)
+		os.Exit(8)
+	}
+}
+
+// printMissingVariables prints each of the missing variables, along with where it is used
+func printMissingVariables(missingNames []string, usages []models.VariableUsage) {
+	fmt.Fprintln(os.Stderr, "unable to prompt for variables (input is disabled), so these variables must be provided:")
+
+	for _, name := range missingNames {
+		location := "declared in the command's 'vars'"
+		for _, usage := range usages {
+			if usage.Name == name {
+				location = "used in " + strings.Join(usage.Locations, ", ")
+			}
+		}
+
+		fmt.Fprintf(os.Stderr, "  %s (%s)\n", name, location)
 	}
 }
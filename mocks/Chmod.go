@@ -0,0 +1,10 @@
+package mocks
+
+import "io/fs"
+
+// GetChmod will create and return a mock function for os.Chmod
+func GetChmod() func(string, fs.FileMode) error {
+	return func(name string, mode fs.FileMode) error {
+		return nil
+	}
+}
@@ -29,6 +29,7 @@ func CreateMockInfo(path string, isDir bool) MockFileInfo {
 type MockFileInfo struct {
 	fs.FileInfo
 	FilePath   string
+	ModeValue  fs.FileMode // The value returned by the Mode method
 	isDirValue bool
 }
 
@@ -36,3 +37,8 @@ type MockFileInfo struct {
 func (mfi MockFileInfo) IsDir() bool {
 	return mfi.isDirValue
 }
+
+// Mode returns a mocked value for the file's mode
+func (mfi MockFileInfo) Mode() fs.FileMode {
+	return mfi.ModeValue
+}
@@ -0,0 +1,8 @@
+package mocks
+
+// GetRemove will create and return a mock function for os.Remove
+func GetRemove() func(string) error {
+	return func(path string) error {
+		return nil
+	}
+}
@@ -0,0 +1,8 @@
+package mocks
+
+// GetRename will create and return a mock function for os.Rename
+func GetRename() func(string, string) error {
+	return func(oldPath, newPath string) error {
+		return nil
+	}
+}
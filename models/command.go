@@ -1,17 +1,36 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
 	"strings"
 
 	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/variable"
 )
 
 // Command represents a user-defined command that can be executed
 type Command struct {
-	Name                  string              `json:"name"`
-	TemplateDirectoryPath string              `json:"templateDirectoryPath"` // This path is relative to the containing scaff-file (or child file)
-	Files                 []FileScaffold      `json:"files"`
-	Directories           []DirectoryScaffold `json:"directories"`
+	Name                  string                `json:"name"`
+	Description           string                `json:"description"`           // Describes what the command creates (shown when listing commands)
+	TemplateDirectoryPath string                `json:"templateDirectoryPath"` // This path is relative to the containing scaff-file (or child file)
+	Files                 []FileScaffold        `json:"files"`
+	Directories           []DirectoryScaffold   `json:"directories"`
+	Vars                  []variable.Definition `json:"vars"`         // The variables used by the command (if any are declared, all of them must be)
+	Engine                string                `json:"engine"`       // The template engine used for names and templates ("scaff" by default, or "gotemplate")
+	Delimiters            []string              `json:"delimiters"`   // The opening and closing delimiters of tags (e.g. ["<%", "%>"]), if not the engine's defaults
+	TemplateTree          *TemplateTree         `json:"templateTree"` // A directory of templates, whose contents are also created (in the working directory)
+
+	// The full paths to the shared partials directories that the command's templates can include partials from (nearest
+	// first). These aren't part of the command object, and are set from the scaff-files when the command is found.
+	PartialsDirectoryPaths []string `json:"-"`
+
+	// If true, the user is never prompted for missing variables while the command is processed (a MissingVariablesError is
+	// returned instead). This isn't part of the command object, and is set from the --no-input option.
+	NoInput bool `json:"-"`
 }
 
 // Validate validates the properties in the Command, and returns any validation errors
@@ -23,21 +42,257 @@ func (c *Command) Validate(absoluteTemplateDirPath string) []customerrors.Valida
 
 	if len(trimmedTemplatePath) == 0 {
 		newErr := customerrors.ValidationError{
-			Message: "command objects should have a 'templateDirectoryPath' property that is set to a non-empty value",
+			Message:  "command objects should have a 'templateDirectoryPath' property that is set to a non-empty value",
+			Location: "templateDirectoryPath",
 		}
 
 		errs = append(errs, newErr)
 	}
 
-	for _, file := range c.Files {
+	if c.Engine != "" && !slices.Contains(variable.Engines, c.Engine) {
+		errs = append(errs, customerrors.ValidationError{
+			Message:  fmt.Sprintf("the 'engine' property should be one of: %s (got '%s')", strings.Join(variable.Engines, ", "), c.Engine),
+			Location: "engine",
+		})
+	}
+
+	errs = append(errs, validateDelimiters(c.Delimiters, "command")...)
+
+	for i, file := range c.Files {
 		fileErrs := file.Validate(absoluteTemplateDirPath)
-		errs = append(errs, fileErrs...)
+		errs = append(errs, customerrors.PrefixLocations(fileErrs, fmt.Sprintf("files[%d]", i))...)
 	}
 
-	for _, directory := range c.Directories {
+	for i, directory := range c.Directories {
 		dirErrs := directory.Validate(absoluteTemplateDirPath)
-		errs = append(errs, dirErrs...)
+		errs = append(errs, customerrors.PrefixLocations(dirErrs, fmt.Sprintf("directories[%d]", i))...)
+	}
+
+	if c.TemplateTree != nil {
+		errs = append(errs, customerrors.PrefixLocations(c.TemplateTree.Validate(absoluteTemplateDirPath), "templateTree")...)
+	}
+
+	if len(c.Vars) > 0 {
+		errs = append(errs, c.validateVars(absoluteTemplateDirPath)...)
 	}
 
 	return errs
 }
+
+// ExpandTemplateTrees walks the template trees of the command (and its directories), and returns a copy of the command with
+// the directories/files within them added to its directories/files (after any that are already listed). This should be
+// done once the command has been validated, and before it is used (the command itself isn't changed).
+// The absoluteTemplateDirPath is the root template directory for the command
+func (c *Command) ExpandTemplateTrees(absoluteTemplateDirPath string) (Command, error) {
+	expanded := *c
+
+	files, directories, err := expandScaffolds(c.Files, c.Directories, c.TemplateTree, absoluteTemplateDirPath)
+	if err != nil {
+		return *c, err
+	}
+
+	expanded.Files, expanded.Directories, expanded.TemplateTree = files, directories, nil
+	return expanded, nil
+}
+
+// validateDelimiters validates the delimiters of a command/file scaffold object (the objectType is used in the message)
+func validateDelimiters(delimiters []string, objectType string) []customerrors.ValidationError {
+	if _, err := variable.ParseDelimiters(delimiters); err != nil {
+		return []customerrors.ValidationError{{
+			Message:  fmt.Sprintf("%s objects should have a valid 'delimiters' property: %s", objectType, err.Error()),
+			Location: "delimiters",
+		}}
+	}
+
+	return []customerrors.ValidationError{}
+}
+
+// TemplateOptions returns the options used to compile the command's names and templates. If a file is given, its
+// delimiters are used for its name and template (if it has any), rather than the command's.
+// Invalid delimiters are ignored (these are reported by Validate).
+func (c *Command) TemplateOptions(file *FileScaffold) variable.Options {
+	delimiters := c.Delimiters
+	if file != nil && len(file.Delimiters) > 0 {
+		delimiters = file.Delimiters
+	}
+
+	parsedDelimiters, _ := variable.ParseDelimiters(delimiters)
+	return variable.Options{Engine: c.Engine, Delimiters: parsedDelimiters, NoInput: c.NoInput}
+}
+
+// validateVars validates the declared variables, and confirms that every variable tag used in the command's names and
+// templates has been declared
+func (c *Command) validateVars(absoluteTemplateDirPath string) []customerrors.ValidationError {
+	errs := []customerrors.ValidationError{}
+
+	declaredNames := make(map[string]bool)
+	for i, definition := range c.Vars {
+		errs = append(errs, customerrors.PrefixLocations(definition.Validate(), fmt.Sprintf("vars[%d]", i))...)
+
+		if declaredNames[definition.Name] {
+			errs = append(errs, customerrors.ValidationError{
+				Message:  fmt.Sprintf("the variable '%s' is declared more than once", definition.Name),
+				Location: fmt.Sprintf("vars[%d].name", i),
+			})
+		}
+		declaredNames[definition.Name] = true
+	}
+
+	// Templates that can't be read are reported by the FileScaffold's own validation (and template trees that can't be read
+	// by the TemplateTree's), so read errors are ignored here
+	expanded, _ := c.ExpandTemplateTrees(absoluteTemplateDirPath)
+	usages, _ := expanded.VariableUsages(absoluteTemplateDirPath)
+	for _, usage := range usages {
+		if declaredNames[usage.Name] {
+			continue
+		}
+
+		errs = append(errs, customerrors.ValidationError{
+			Message:  fmt.Sprintf("the variable '%s' is used in %s, but is not declared in the command's 'vars'", usage.Name, usage.Locations[0]),
+			Location: "vars",
+		})
+	}
+
+	return errs
+}
+
+// VariableUsages statically scans the command's file/directory names and templates (other than those that are copied as
+// they are), and returns every variable that is used in them (in the order they are first used), along with where each one
+// is used. Variables used in the partials that a template includes are treated as being used in that template. The lists
+// used in "forEach" properties are included, but the loop variables they give to their files/directories aren't.
+// The absoluteTemplateDirPath is the root template directory for the command.
+// If any templates (or partials) can't be read or compiled, the usages from everything else are still returned (along with
+// the errors).
+func (c *Command) VariableUsages(absoluteTemplateDirPath string) ([]VariableUsage, error) {
+	usages := []VariableUsage{}
+	usageIndexes := make(map[string]int)
+	var readErrs []error
+
+	loadPartial := c.PartialLoader(absoluteTemplateDirPath)
+
+	addUsages := func(tags []variable.Tag, location string, hiddenNames []string) {
+		for _, tag := range tags {
+			if slices.Contains(hiddenNames, tag.Name) {
+				continue
+			}
+
+			i, isUsed := usageIndexes[tag.Name]
+			if !isUsed {
+				i = len(usages)
+				usageIndexes[tag.Name] = i
+				usages = append(usages, VariableUsage{Name: tag.Name, HasDefault: true})
+			}
+
+			if !slices.Contains(usages[i].Locations, location) {
+				usages[i].Locations = append(usages[i].Locations, location)
+			}
+
+			usages[i].HasDefault = usages[i].HasDefault && tag.HasDefault()
+			usages[i].IsList = usages[i].IsList || tag.IsList
+		}
+	}
+
+	var scan func(files []FileScaffold, directories []DirectoryScaffold, hiddenNames []string)
+	scan = func(files []FileScaffold, directories []DirectoryScaffold, hiddenNames []string) {
+		for _, file := range files {
+			if file.ForEach != "" {
+				addUsages([]variable.Tag{{Name: file.ForEach, IsList: true}}, fmt.Sprintf("the 'forEach' of the file '%s'", file.Name), hiddenNames)
+			}
+
+			fileHiddenNames := loopNames(hiddenNames, file.ForEach, file.As, file.LoopVars)
+			addUsages(conditionTags(file.When), fmt.Sprintf("the 'when' condition of the file '%s'", file.Name), fileHiddenNames)
+			addUsages(variable.TagsWithOptions(c.TemplateOptions(&file), file.Name), fmt.Sprintf("the file name '%s'", file.Name), fileHiddenNames)
+
+			fullTemplatePath := file.GetFullTemplatePath(absoluteTemplateDirPath)
+			templateBytes, readErr := ReadFile(fullTemplatePath)
+			if readErr != nil {
+				readErrs = append(readErrs, readErr)
+				continue
+			}
+
+			// Raw copies aren't populated, so any tags in them aren't variables
+			if file.IsRawCopy(templateBytes) {
+				continue
+			}
+
+			template, compileErr := variable.CompileWithOptions(c.TemplateOptions(&file), fullTemplatePath, string(templateBytes))
+			if compileErr != nil {
+				readErrs = append(readErrs, compileErr)
+				continue
+			}
+
+			templateTags, partialErr := template.Tags(loadPartial)
+			if partialErr != nil {
+				readErrs = append(readErrs, fmt.Errorf("unable to include a partial in the template '%s': %w", fullTemplatePath, partialErr))
+			}
+
+			addUsages(templateTags, fmt.Sprintf("the template '%s'", fullTemplatePath), fileHiddenNames)
+		}
+
+		for _, directory := range directories {
+			if directory.ForEach != "" {
+				addUsages([]variable.Tag{{Name: directory.ForEach, IsList: true}}, fmt.Sprintf("the 'forEach' of the directory '%s'", directory.Name), hiddenNames)
+			}
+
+			directoryHiddenNames := loopNames(hiddenNames, directory.ForEach, directory.As, directory.LoopVars)
+			addUsages(conditionTags(directory.When), fmt.Sprintf("the 'when' condition of the directory '%s'", directory.Name), directoryHiddenNames)
+			addUsages(variable.TagsWithOptions(c.TemplateOptions(nil), directory.Name), fmt.Sprintf("the directory name '%s'", directory.Name), directoryHiddenNames)
+			scan(directory.Files, directory.Directories, directoryHiddenNames)
+		}
+	}
+	scan(c.Files, c.Directories, []string{})
+
+	return usages, errors.Join(readErrs...)
+}
+
+// VariableDefinitions returns the definitions of every variable the command needs: the declared variables (in the order
+// they are declared), followed by a plain definition for each of the given usages that isn't declared (unless every use of
+// it has a default value). Undeclared variables that are used as the list in a range block are given the list type.
+func (c *Command) VariableDefinitions(usages []VariableUsage) []variable.Definition {
+	definitions := slices.Clone(c.Vars)
+
+	for _, usage := range usages {
+		if usage.HasDefault {
+			continue
+		}
+
+		isDeclared := slices.ContainsFunc(c.Vars, func(definition variable.Definition) bool {
+			return definition.Name == usage.Name
+		})
+
+		if !isDeclared && usage.IsList {
+			definitions = append(definitions, variable.Definition{Name: usage.Name, Type: variable.TypeList})
+		} else if !isDeclared {
+			definitions = append(definitions, variable.Definition{Name: usage.Name})
+		}
+	}
+
+	return definitions
+}
+
+// PartialLoader returns a variable.PartialLoader that finds partials in the command's template directory (the
+// absoluteTemplateDirPath), then in each of the command's shared partials directories (in order)
+func (c *Command) PartialLoader(absoluteTemplateDirPath string) variable.PartialLoader {
+	directoryPaths := append([]string{absoluteTemplateDirPath}, c.PartialsDirectoryPaths...)
+
+	return func(partialPath string) (string, string, error) {
+		for _, directoryPath := range directoryPaths {
+			fullPartialPath := path.Join(directoryPath, partialPath)
+
+			partialBytes, readErr := ReadFile(fullPartialPath)
+			if errors.Is(readErr, fs.ErrNotExist) {
+				continue
+			}
+
+			if readErr != nil {
+				return "", "", readErr
+			}
+
+			return string(partialBytes), fullPartialPath, nil
+		}
+
+		return "", "", &customerrors.ValidationError{
+			Message: fmt.Sprintf("unable to find the partial '%s' (looked in: %s)", partialPath, strings.Join(directoryPaths, ", ")),
+		}
+	}
+}
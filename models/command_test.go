@@ -1,12 +1,16 @@
 package models_test
 
 import (
+	"errors"
 	"io/fs"
+	"maps"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/M-Derbyshire/scaff/customerrors"
 	"github.com/M-Derbyshire/scaff/models"
+	"github.com/M-Derbyshire/scaff/variable"
 )
 
 func TestCommandValidateShouldReturnNoErrorsIfValid(t *testing.T) {
@@ -198,10 +202,12 @@ func TestCommandValidateShouldCallValidateOnAllFiles(t *testing.T) {
 
 	expectedErrs := []customerrors.ValidationError{
 		{
-			Message: "file scaffold objects should have a 'name' property that is set to a non-empty value",
+			Message:  "file scaffold objects should have a 'name' property that is set to a non-empty value",
+			Location: "files[0].name",
 		},
 		{
-			Message: "file scaffold objects should have a 'templatePath' property that is set to a non-empty value",
+			Message:  "file scaffold objects should have a 'templatePath' property that is set to a non-empty value",
+			Location: "files[1].templatePath",
 		},
 	}
 
@@ -246,10 +252,12 @@ func TestCommandValidateShouldCallValidateOnAllDirectories(t *testing.T) {
 
 	expectedErrs := []customerrors.ValidationError{
 		{
-			Message: "directory scaffold objects should have a 'name' property that is set to a non-empty value",
+			Message:  "directory scaffold objects should have a 'name' property that is set to a non-empty value",
+			Location: "directories[0].name",
 		},
 		{
-			Message: "file scaffold objects should have a 'name' property that is set to a non-empty value",
+			Message:  "file scaffold objects should have a 'name' property that is set to a non-empty value",
+			Location: "directories[1].files[0].name",
 		},
 	}
 
@@ -299,13 +307,16 @@ func TestCommandValidateShouldReturnMultipleErrors(t *testing.T) {
 
 	expectedErrs := []customerrors.ValidationError{
 		{
-			Message: "command objects should have a 'templateDirectoryPath' property that is set to a non-empty value",
+			Message:  "command objects should have a 'templateDirectoryPath' property that is set to a non-empty value",
+			Location: "templateDirectoryPath",
 		},
 		{
-			Message: "file scaffold objects should have a 'name' property that is set to a non-empty value",
+			Message:  "file scaffold objects should have a 'name' property that is set to a non-empty value",
+			Location: "files[0].name",
 		},
 		{
-			Message: "directory scaffold objects should have a 'name' property that is set to a non-empty value",
+			Message:  "directory scaffold objects should have a 'name' property that is set to a non-empty value",
+			Location: "directories[0].name",
 		},
 	}
 
@@ -338,3 +349,764 @@ func TestCommandValidateShouldReturnMultipleErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestCommandValidateShouldReturnErrorForEachUndeclaredVariableIfAnyVariablesAreDeclared(t *testing.T) {
+	models.FileStat = func(filepath string) (fs.FileInfo, error) {
+		return nil, nil
+	}
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		return []byte("{: declared :} {: inTemplate :} {\\: escaped :}"), nil
+	}
+
+	expectedErrs := []string{
+		"the variable 'inFileName' is used in the file name '{: inFileName :}.txt', but is not declared in the command's 'vars'",
+		"the variable 'inTemplate' is used in the template 'C:/test/template1.txt', but is not declared in the command's 'vars'",
+		"the variable 'inDirName' is used in the directory name '{: declared :}_{: inDirName :}', but is not declared in the command's 'vars'",
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Files: []models.FileScaffold{
+			{
+				Name:         "{: inFileName :}.txt",
+				TemplatePath: "template1.txt",
+			},
+		},
+		Directories: []models.DirectoryScaffold{
+			{
+				Name: "{: declared :}_{: inDirName :}",
+			},
+		},
+		Vars: []variable.Definition{
+			{Name: "declared"},
+		},
+	}
+
+	results := command.Validate("C:/test")
+
+	if len(results) != len(expectedErrs) {
+		t.Errorf("expected %d errors. got %d: %v", len(expectedErrs), len(results), results)
+		return
+	}
+
+	for i, expectedErr := range expectedErrs {
+		if results[i].Message != expectedErr {
+			t.Errorf("expected error %d to be '%s'. got '%s'", i, expectedErr, results[i].Message)
+		}
+	}
+}
+
+func TestCommandValidateShouldNotCheckForUndeclaredVariablesIfNoneAreDeclared(t *testing.T) {
+	models.FileStat = func(filepath string) (fs.FileInfo, error) {
+		return nil, nil
+	}
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		return []byte("{: inTemplate :}"), nil
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Files: []models.FileScaffold{
+			{
+				Name:         "{: inFileName :}.txt",
+				TemplatePath: "template1.txt",
+			},
+		},
+	}
+
+	results := command.Validate("C:/test")
+
+	if len(results) > 0 {
+		t.Errorf("expected no errors. got %d: %v", len(results), results)
+	}
+}
+
+func TestCommandValidateShouldReturnErrorsForInvalidVariableDeclarations(t *testing.T) {
+	models.FileStat = func(filepath string) (fs.FileInfo, error) {
+		return nil, nil
+	}
+
+	expectedErrs := []string{
+		"the variable 'var1' has an invalid 'type' ('number'). Expected one of: [string int bool enum list]",
+		"the variable 'var1' is declared more than once",
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Vars: []variable.Definition{
+			{Name: "var1"},
+			{Name: "var1", Type: "number"},
+		},
+	}
+
+	results := command.Validate("C:/test")
+
+	if len(results) != len(expectedErrs) {
+		t.Errorf("expected %d errors. got %d: %v", len(expectedErrs), len(results), results)
+		return
+	}
+
+	for i, expectedErr := range expectedErrs {
+		if results[i].Message != expectedErr {
+			t.Errorf("expected error %d to be '%s'. got '%s'", i, expectedErr, results[i].Message)
+		}
+	}
+}
+
+func TestCommandVariableUsagesShouldReturnEveryVariableWithWhereItIsUsed(t *testing.T) {
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		if filePath == "C:/test/template2.txt" {
+			return []byte("{: var3 :}"), nil
+		}
+
+		return []byte("{: var1 :} {: var2 :}"), nil
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Files: []models.FileScaffold{
+			{
+				Name:         "{: var2 :}.txt",
+				TemplatePath: "template1.txt",
+			},
+		},
+		Directories: []models.DirectoryScaffold{
+			{
+				Name: "{: var4 :}",
+				Files: []models.FileScaffold{
+					{
+						Name:         "inner.txt",
+						TemplatePath: "template2.txt",
+					},
+				},
+			},
+		},
+	}
+
+	expectedUsages := []models.VariableUsage{
+		{Name: "var2", Locations: []string{"the file name '{: var2 :}.txt'", "the template 'C:/test/template1.txt'"}},
+		{Name: "var1", Locations: []string{"the template 'C:/test/template1.txt'"}},
+		{Name: "var4", Locations: []string{"the directory name '{: var4 :}'"}},
+		{Name: "var3", Locations: []string{"the template 'C:/test/template2.txt'"}},
+	}
+
+	results, err := command.VariableUsages("C:/test")
+	if err != nil {
+		t.Errorf("expected no error. got '%s'", err.Error())
+	}
+
+	if len(results) != len(expectedUsages) {
+		t.Errorf("expected %d usages. got %d: %v", len(expectedUsages), len(results), results)
+		return
+	}
+
+	for i, expectedUsage := range expectedUsages {
+		if results[i].Name != expectedUsage.Name || !slices.Equal(results[i].Locations, expectedUsage.Locations) {
+			t.Errorf("expected usage %d to be %v. got %v", i, expectedUsage, results[i])
+		}
+	}
+}
+
+func TestCommandVariableUsagesShouldReturnTemplateReadErrors(t *testing.T) {
+	expectedErr := "my read error"
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		return nil, errors.New(expectedErr)
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Files: []models.FileScaffold{
+			{
+				Name:         "{: var1 :}.txt",
+				TemplatePath: "template1.txt",
+			},
+		},
+	}
+
+	results, err := command.VariableUsages("C:/test")
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("expected error to be '%s'. got '%v'", expectedErr, err)
+	}
+
+	if len(results) != 1 || results[0].Name != "var1" {
+		t.Errorf("expected the usage from the file name to still be returned. got %v", results)
+	}
+}
+
+func TestCommandVariableDefinitionsShouldReturnDeclaredVariablesFollowedByUndeclaredOnes(t *testing.T) {
+	command := models.Command{
+		Name: "test1",
+		Vars: []variable.Definition{
+			{Name: "declared2", Description: "my description"},
+			{Name: "declared1"},
+		},
+	}
+
+	usages := []models.VariableUsage{
+		{Name: "undeclared1"},
+		{Name: "declared1"},
+		{Name: "undeclared2"},
+	}
+
+	expectedNames := []string{"declared2", "declared1", "undeclared1", "undeclared2"}
+
+	results := command.VariableDefinitions(usages)
+
+	resultNames := []string{}
+	for _, result := range results {
+		resultNames = append(resultNames, result.Name)
+	}
+
+	if !slices.Equal(resultNames, expectedNames) {
+		t.Errorf("expected definitions for %v. got %v", expectedNames, resultNames)
+	}
+
+	if results[0].Description != "my description" {
+		t.Errorf("expected declared definitions to be kept as they are. got %v", results[0])
+	}
+}
+
+func TestCommandVariableDefinitionsShouldSkipUndeclaredVariablesThatAlwaysHaveDefaults(t *testing.T) {
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		return []byte(`{: optional | default "x" :} {: sometimes | default "y" :} {: sometimes | upper :}`), nil
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Files: []models.FileScaffold{
+			{
+				Name:         "file.txt",
+				TemplatePath: "template1.txt",
+			},
+		},
+	}
+
+	usages, err := command.VariableUsages("C:/test")
+	if err != nil {
+		t.Errorf("expected no error. got '%s'", err.Error())
+	}
+
+	results := command.VariableDefinitions(usages)
+
+	if len(results) != 1 || results[0].Name != "sometimes" {
+		t.Errorf("expected only a definition for 'sometimes'. got %v", results)
+	}
+}
+
+func TestCommandVariableDefinitionsShouldGiveUndeclaredRangeListsTheListType(t *testing.T) {
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		return []byte(`{: range field in fields :}{: field :}{: field_index :}{: end :}{: name :}`), nil
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Files: []models.FileScaffold{
+			{
+				Name:         "file.txt",
+				TemplatePath: "template1.txt",
+			},
+		},
+	}
+
+	usages, err := command.VariableUsages("C:/test")
+	if err != nil {
+		t.Errorf("expected no error. got '%s'", err.Error())
+	}
+
+	results := command.VariableDefinitions(usages)
+
+	if len(results) != 2 || results[0].Name != "fields" || results[1].Name != "name" {
+		t.Fatalf("expected definitions for 'fields' and 'name' only. got %v", results)
+	}
+
+	if results[0].GetType() != variable.TypeList || results[1].GetType() != variable.TypeString {
+		t.Errorf("expected only 'fields' to have the list type. got %v", results)
+	}
+}
+
+func TestCommandPartialLoaderShouldLookInTheTemplateDirectoryThenThePartialsDirectories(t *testing.T) {
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		switch filePath {
+		case "/templates/partials/local.txt", "/shared/partials/local.txt", "/shared/partials/shared.txt":
+			return []byte(filePath), nil
+		case "/other/partials/unreadable.txt":
+			return nil, fs.ErrPermission
+		default:
+			return nil, fs.ErrNotExist
+		}
+	}
+
+	command := models.Command{PartialsDirectoryPaths: []string{"/other", "/shared"}}
+	loadPartial := command.PartialLoader("/templates")
+
+	expectedFullPaths := map[string]string{
+		"partials/local.txt":  "/templates/partials/local.txt",
+		"partials/shared.txt": "/shared/partials/shared.txt",
+	}
+
+	for partialPath, expectedFullPath := range expectedFullPaths {
+		contents, fullPath, err := loadPartial(partialPath)
+		if err != nil {
+			t.Errorf("expected no error for '%s'. got '%s'", partialPath, err.Error())
+		}
+
+		if fullPath != expectedFullPath || contents != expectedFullPath {
+			t.Errorf("expected '%s' to be loaded from '%s'. got '%s' (with the contents '%s')", partialPath, expectedFullPath, fullPath, contents)
+		}
+	}
+
+	if _, _, err := loadPartial("partials/unreadable.txt"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("expected read errors to be returned. got %v", err)
+	}
+
+	var validationErr *customerrors.ValidationError
+	if _, _, err := loadPartial("partials/missing.txt"); !errors.As(err, &validationErr) {
+		t.Errorf("expected a validation error for a missing partial. got %v", err)
+	}
+}
+
+func TestCommandVariableUsagesShouldIncludeVariablesFromIncludedPartials(t *testing.T) {
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		switch filePath {
+		case "C:/test/template1.txt":
+			return []byte(`{: a :}{: range item in items :}{: include "partial.txt" :}{: end :}`), nil
+		case "C:/test/partial.txt":
+			return []byte(`{: item :}{: b :}`), nil
+		default:
+			return nil, fs.ErrNotExist
+		}
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Files: []models.FileScaffold{
+			{
+				Name:         "file.txt",
+				TemplatePath: "template1.txt",
+			},
+		},
+	}
+
+	usages, err := command.VariableUsages("C:/test")
+	if err != nil {
+		t.Errorf("expected no error. got '%s'", err.Error())
+	}
+
+	names := []string{}
+	for _, usage := range usages {
+		names = append(names, usage.Name)
+	}
+
+	if !slices.Equal(names, []string{"a", "items", "b"}) {
+		t.Errorf("expected usages of 'a', 'items' and 'b'. got %v", names)
+	}
+}
+
+func TestCommandValidateShouldReturnErrorIfEngineIsUnknown(t *testing.T) {
+	models.FileStat = func(filepath string) (fs.FileInfo, error) {
+		return nil, nil
+	}
+
+	for _, engine := range []string{"", "scaff", "gotemplate"} {
+		command := models.Command{Name: "test1", TemplateDirectoryPath: "/test", Engine: engine}
+		if results := command.Validate("C:/test"); len(results) > 0 {
+			t.Errorf("expected no errors for the engine '%s'. got %v", engine, results)
+		}
+	}
+
+	command := models.Command{Name: "test1", TemplateDirectoryPath: "/test", Engine: "handlebars"}
+	results := command.Validate("C:/test")
+
+	expectedMessage := "the 'engine' property should be one of: scaff, gotemplate (got 'handlebars')"
+	if len(results) != 1 || results[0].Message != expectedMessage {
+		t.Errorf("expected the error '%s'. got %v", expectedMessage, results)
+	}
+}
+
+func TestCommandVariableUsagesShouldUseTheCommandsEngine(t *testing.T) {
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		return []byte("{{ .var1 | upper }} {: notAVariable :}"), nil
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Engine:                "gotemplate",
+		Files: []models.FileScaffold{
+			{
+				Name:         "{{ .var2 | snake }}.txt",
+				TemplatePath: "template1.txt",
+			},
+		},
+	}
+
+	expectedUsages := []models.VariableUsage{
+		{Name: "var2", Locations: []string{"the file name '{{ .var2 | snake }}.txt'"}},
+		{Name: "var1", Locations: []string{"the template 'C:/test/template1.txt'"}},
+	}
+
+	results, err := command.VariableUsages("C:/test")
+	if err != nil {
+		t.Errorf("expected no error. got '%s'", err.Error())
+	}
+
+	if len(results) != len(expectedUsages) {
+		t.Fatalf("expected %d usages. got %d: %v", len(expectedUsages), len(results), results)
+	}
+
+	for i, expectedUsage := range expectedUsages {
+		if results[i].Name != expectedUsage.Name || !slices.Equal(results[i].Locations, expectedUsage.Locations) {
+			t.Errorf("expected usage %d to be %v. got %v", i, expectedUsage, results[i])
+		}
+	}
+}
+
+func TestCommandValidateShouldReturnErrorsForInvalidDelimiters(t *testing.T) {
+	models.FileStat = func(filepath string) (fs.FileInfo, error) {
+		return nil, nil
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Delimiters:            []string{"<%"},
+		Files: []models.FileScaffold{
+			{Name: "test", TemplatePath: "template1.txt", Delimiters: []string{"$", "$"}},
+			{Name: "test2", TemplatePath: "template2.txt", Delimiters: []string{"[[", "]]"}},
+		},
+	}
+
+	results := command.Validate("C:/test")
+
+	expectedPrefixes := []string{
+		"command objects should have a valid 'delimiters' property: ",
+		"file scaffold objects should have a valid 'delimiters' property: ",
+	}
+
+	if len(results) != len(expectedPrefixes) {
+		t.Fatalf("expected %d errors. got %d: %v", len(expectedPrefixes), len(results), results)
+	}
+
+	for i, expectedPrefix := range expectedPrefixes {
+		if !strings.HasPrefix(results[i].Message, expectedPrefix) {
+			t.Errorf("expected error %d to start with '%s'. got '%s'", i, expectedPrefix, results[i].Message)
+		}
+	}
+}
+
+func TestCommandTemplateOptionsShouldUseTheFilesDelimitersIfItHasAny(t *testing.T) {
+	command := models.Command{Engine: "scaff", Delimiters: []string{"<%", "%>"}}
+
+	cases := []struct {
+		file     *models.FileScaffold
+		expected variable.Delimiters
+	}{
+		{nil, variable.Delimiters{Left: "<%", Right: "%>"}},
+		{&models.FileScaffold{}, variable.Delimiters{Left: "<%", Right: "%>"}},
+		{&models.FileScaffold{Delimiters: []string{"[[", "]]"}}, variable.Delimiters{Left: "[[", Right: "]]"}},
+	}
+
+	for _, testCase := range cases {
+		result := command.TemplateOptions(testCase.file)
+		if result.Engine != "scaff" || result.Delimiters != testCase.expected {
+			t.Errorf("expected the options to use the engine 'scaff' and the delimiters %v. got %v", testCase.expected, result)
+		}
+	}
+}
+
+func TestCommandVariableUsagesShouldSkipTheTemplatesOfRawCopies(t *testing.T) {
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		if filePath == "C:/test/image.png" {
+			return []byte("\x00{: var3 :}"), nil
+		}
+
+		return []byte("{: var2 :}"), nil
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Files: []models.FileScaffold{
+			{Name: "{: var1 :}.txt", TemplatePath: "raw.txt", Raw: true},
+			{Name: "image.png", TemplatePath: "image.png"},
+		},
+	}
+
+	results, err := command.VariableUsages("C:/test")
+	if err != nil {
+		t.Errorf("expected no error. got '%s'", err.Error())
+	}
+
+	if len(results) != 1 || results[0].Name != "var1" {
+		t.Errorf("expected only the usage of 'var1' in the file name. got %v", results)
+	}
+}
+
+func TestCommandValidateShouldReturnErrorsForInvalidWhenConditions(t *testing.T) {
+	models.FileStat = func(filepath string) (fs.FileInfo, error) {
+		return nil, nil
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Files: []models.FileScaffold{
+			{Name: "test", TemplatePath: "template1.txt", When: "withDocker == 'true'"},
+			{Name: "test2", TemplatePath: "template2.txt", When: "a < b"},
+		},
+		Directories: []models.DirectoryScaffold{
+			{Name: "dir", When: "kind =="},
+		},
+	}
+
+	results := command.Validate("C:/test")
+
+	expectedPrefixes := []string{
+		"file scaffold objects should have a valid 'when' property: invalid condition 'a < b'",
+		"directory scaffold objects should have a valid 'when' property: invalid condition 'kind =='",
+	}
+
+	if len(results) != len(expectedPrefixes) {
+		t.Fatalf("expected %d errors. got %d: %v", len(expectedPrefixes), len(results), results)
+	}
+
+	for i, expectedPrefix := range expectedPrefixes {
+		if !strings.HasPrefix(results[i].Message, expectedPrefix) {
+			t.Errorf("expected error %d to start with '%s'. got '%s'", i, expectedPrefix, results[i].Message)
+		}
+	}
+}
+
+func TestCommandExpandShouldRemoveFilesAndDirectoriesWhoseConditionsAreFalse(t *testing.T) {
+	command := models.Command{
+		Name: "test1",
+		Files: []models.FileScaffold{
+			{Name: "always.txt"},
+			{Name: "Dockerfile", When: "withDocker"},
+		},
+		Directories: []models.DirectoryScaffold{
+			{Name: "docker", When: "withDocker == 'true'"},
+			{
+				Name: "src",
+				Files: []models.FileScaffold{
+					{Name: "api.go", When: "kind == 'api'"},
+					{Name: "cli.go", When: "not kind == 'api'"},
+				},
+			},
+		},
+	}
+
+	result, err := command.Expand(map[string]string{"withDocker": "false", "kind": "api"})
+	if err != nil {
+		t.Fatalf("expected no error. got '%s'", err.Error())
+	}
+
+	if len(result.Files) != 1 || result.Files[0].Name != "always.txt" {
+		t.Errorf("expected only the file 'always.txt'. got %v", result.Files)
+	}
+
+	if len(result.Directories) != 1 || result.Directories[0].Name != "src" {
+		t.Fatalf("expected only the directory 'src'. got %v", result.Directories)
+	}
+
+	if srcFiles := result.Directories[0].Files; len(srcFiles) != 1 || srcFiles[0].Name != "api.go" {
+		t.Errorf("expected only the file 'api.go' in 'src'. got %v", srcFiles)
+	}
+
+	if len(command.Files) != 2 || len(command.Directories[1].Files) != 2 {
+		t.Errorf("expected the original command to be unchanged")
+	}
+}
+
+func TestCommandStructureNamesShouldReturnTheVariablesUsedInConditions(t *testing.T) {
+	command := models.Command{
+		Files: []models.FileScaffold{
+			{Name: "Dockerfile", When: "withDocker"},
+		},
+		Directories: []models.DirectoryScaffold{
+			{
+				Name:  "src",
+				When:  "kind != otherKind",
+				Files: []models.FileScaffold{{Name: "api.go", When: "withDocker"}},
+			},
+		},
+	}
+
+	expected := []string{"withDocker", "kind", "otherKind"}
+	if result := command.StructureNames(); !slices.Equal(result, expected) {
+		t.Errorf("expected %v. got %v", expected, result)
+	}
+}
+
+func TestCommandVariableUsagesShouldIncludeVariablesFromWhenConditions(t *testing.T) {
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		return []byte(""), nil
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Files: []models.FileScaffold{
+			{Name: "Dockerfile", TemplatePath: "Dockerfile", When: "withDocker"},
+		},
+		Directories: []models.DirectoryScaffold{
+			{Name: "docker", When: "withDocker == 'true'"},
+		},
+	}
+
+	results, err := command.VariableUsages("C:/test")
+	if err != nil {
+		t.Errorf("expected no error. got '%s'", err.Error())
+	}
+
+	expectedLocations := []string{
+		"the 'when' condition of the file 'Dockerfile'",
+		"the 'when' condition of the directory 'docker'",
+	}
+
+	if len(results) != 1 || results[0].Name != "withDocker" || !slices.Equal(results[0].Locations, expectedLocations) {
+		t.Errorf("expected a usage of 'withDocker' in %v. got %v", expectedLocations, results)
+	}
+}
+
+func TestCommandValidateShouldReturnErrorsForInvalidForEachProperties(t *testing.T) {
+	models.FileStat = func(filepath string) (fs.FileInfo, error) {
+		return nil, nil
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Files: []models.FileScaffold{
+			{Name: "{: entity :}.go", TemplatePath: "model.go", ForEach: "entities", As: "entity"},
+			{Name: "test2", TemplatePath: "template2.txt", ForEach: "entities"},
+			{Name: "test3", TemplatePath: "template3.txt", ForEach: "entities", As: "entities"},
+		},
+		Directories: []models.DirectoryScaffold{
+			{Name: "dir", ForEach: "my list", As: "if"},
+		},
+	}
+
+	results := command.Validate("C:/test")
+
+	expectedMessages := []string{
+		"file scaffold objects should have both a 'forEach' and an 'as' property, if either is set",
+		"file scaffold objects should have an 'as' property that is different to their 'forEach' property (got 'entities')",
+		"directory scaffold objects should have a 'forEach' property that is a valid variable name (got 'my list')",
+		"directory scaffold objects should have an 'as' property that is a valid variable name (got 'if')",
+	}
+
+	if len(results) != len(expectedMessages) {
+		t.Fatalf("expected %d errors. got %d: %v", len(expectedMessages), len(results), results)
+	}
+
+	for i, expectedMessage := range expectedMessages {
+		if results[i].Message != expectedMessage {
+			t.Errorf("expected error %d to be '%s'. got '%s'", i, expectedMessage, results[i].Message)
+		}
+	}
+}
+
+func TestCommandExpandShouldRepeatFilesAndDirectoriesForEachItemInTheirLists(t *testing.T) {
+	command := models.Command{
+		Name: "test1",
+		Files: []models.FileScaffold{
+			{Name: "{: entity :}.go", ForEach: "entities", As: "entity", When: "entity != 'skip'"},
+		},
+		Directories: []models.DirectoryScaffold{
+			{
+				Name:    "{: module :}",
+				ForEach: "modules",
+				As:      "module",
+				Files: []models.FileScaffold{
+					{Name: "{: module :}_{: entity :}.go", ForEach: "entities", As: "entity"},
+				},
+			},
+		},
+	}
+
+	vars := map[string]string{"entities": "user,skip", "modules": `["api"]`, "entity": "original"}
+	result, err := command.Expand(vars)
+	if err != nil {
+		t.Fatalf("expected no error. got '%s'", err.Error())
+	}
+
+	if len(result.Files) != 1 || result.Files[0].LoopVars["entity"] != "user" || result.Files[0].ForEach != "" {
+		t.Errorf("expected a single copy of the file for 'user'. got %v", result.Files)
+	}
+
+	if len(result.Directories) != 1 || result.Directories[0].LoopVars["module"] != "api" {
+		t.Fatalf("expected a single copy of the directory for 'api'. got %v", result.Directories)
+	}
+
+	innerFiles := result.Directories[0].Files
+	expectedLoopVars := []map[string]string{
+		{"module": "api", "module_index": "0", "module_first": "true", "module_last": "true",
+			"entity": "user", "entity_index": "0", "entity_first": "true", "entity_last": "false"},
+		{"module": "api", "module_index": "0", "module_first": "true", "module_last": "true",
+			"entity": "skip", "entity_index": "1", "entity_first": "false", "entity_last": "true"},
+	}
+
+	if len(innerFiles) != len(expectedLoopVars) {
+		t.Fatalf("expected %d files in the directory. got %v", len(expectedLoopVars), innerFiles)
+	}
+
+	for i, expected := range expectedLoopVars {
+		if !maps.Equal(innerFiles[i].LoopVars, expected) {
+			t.Errorf("expected file %d to have the loop variables %v. got %v", i, expected, innerFiles[i].LoopVars)
+		}
+	}
+
+	if vars["entity"] != "original" || len(vars) != 3 {
+		t.Errorf("expected the loop variables to be removed from the vars afterwards. got %v", vars)
+	}
+}
+
+func TestCommandVariableUsagesShouldIncludeForEachListsButNotTheirLoopVariables(t *testing.T) {
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		return []byte("{: entity | pascal :} {: entity_index :} {: package :}"), nil
+	}
+
+	command := models.Command{
+		Name:                  "test1",
+		TemplateDirectoryPath: "/test",
+		Directories: []models.DirectoryScaffold{
+			{
+				Name:    "{: entity :}",
+				ForEach: "entities",
+				As:      "entity",
+				When:    "entity != package",
+				Files:   []models.FileScaffold{{Name: "model.go", TemplatePath: "model.go"}},
+			},
+		},
+	}
+
+	results, err := command.VariableUsages("C:/test")
+	if err != nil {
+		t.Errorf("expected no error. got '%s'", err.Error())
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 usages. got %v", results)
+	}
+
+	if results[0].Name != "entities" || !results[0].IsList || results[0].Locations[0] != "the 'forEach' of the directory '{: entity :}'" {
+		t.Errorf("expected the first usage to be the 'entities' list. got %v", results[0])
+	}
+
+	if results[1].Name != "package" {
+		t.Errorf("expected the second usage to be 'package'. got %v", results[1])
+	}
+
+	if names := command.StructureNames(); !slices.Equal(names, []string{"entities", "package"}) {
+		t.Errorf("expected the structure names to be 'entities' and 'package'. got %v", names)
+	}
+}
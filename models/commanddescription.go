@@ -0,0 +1,33 @@
+package models
+
+import "github.com/M-Derbyshire/scaff/variable"
+
+// CommandDescription describes what a command needs, and what it creates (see command.Describe)
+type CommandDescription struct {
+	Name              string              `json:"name"`
+	Description       string              `json:"description"`
+	TemplateDirectory string              `json:"templateDirectory"` // The full path to the command's template directory
+	Variables         []DescribedVariable `json:"variables"`         // The declared variables (in order), followed by any others that are used
+	Tree              []DescribedScaffold `json:"tree"`              // The directories/files the command creates
+}
+
+// DescribedVariable is a variable that a command declares, or uses in its names/templates
+type DescribedVariable struct {
+	variable.Definition          // The declaration (only the name and type are set for variables that aren't declared)
+	IsDeclared          bool     `json:"declared"`
+	HasDefault          bool     `json:"hasDefault"` // True if every use of the variable has a default value (so a value doesn't need to be provided)
+	Locations           []string `json:"usedIn"`     // Describes each place the variable is used
+}
+
+// DescribedScaffold is a directory/file that a command creates. Names are as they are written in the command (so any
+// tags are placeholders for the values).
+type DescribedScaffold struct {
+	Name         string              `json:"name"`
+	IsDirectory  bool                `json:"isDirectory"`
+	TemplatePath string              `json:"templatePath,omitempty"` // The path to a file's template (relative to the template directory)
+	IsRaw        bool                `json:"raw,omitempty"`
+	When         string              `json:"when,omitempty"`
+	ForEach      string              `json:"forEach,omitempty"`
+	As           string              `json:"as,omitempty"`
+	Children     []DescribedScaffold `json:"children,omitempty"` // The directories/files within a directory
+}
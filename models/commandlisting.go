@@ -0,0 +1,10 @@
+package models
+
+// CommandListing is a command that can be reached from a directory, along with where it is defined
+type CommandListing struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	ScaffFilePath string `json:"scaffFile"`            // The full path to the scaff file that defines the command
+	IsShadowed    bool   `json:"shadowed"`             // Whether another command with the same name is found first (so this one can't be used)
+	ShadowedBy    string `json:"shadowedBy,omitempty"` // The full path to the scaff file that defines the command that is found first (if shadowed)
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+// validateWhen validates the "when" condition of a file/directory scaffold object (the objectType is used in the message)
+func validateWhen(when string, objectType string) []customerrors.ValidationError {
+	if when == "" {
+		return []customerrors.ValidationError{}
+	}
+
+	if _, err := variable.CompileCondition(when); err != nil {
+		return []customerrors.ValidationError{{
+			Message:  fmt.Sprintf("%s objects should have a valid 'when' property: %s", objectType, err.Error()),
+			Location: "when",
+		}}
+	}
+
+	return []customerrors.ValidationError{}
+}
+
+// isIncluded identifies if a file/directory with the given "when" condition should be created (using the values in the
+// given map, along with the file/directory's loop variables). If there is no condition, it is always created. If noInput is
+// true, a MissingVariablesError is returned for missing variables (rather than prompting for them).
+func isIncluded(when string, vars, loopVars map[string]string, noInput bool) (bool, error) {
+	if when == "" {
+		return true, nil
+	}
+
+	condition, err := variable.CompileCondition(when)
+	if err != nil {
+		return false, err
+	}
+
+	result := false
+	err = variable.WithLoopVars(vars, loopVars, func() error {
+		var evaluateErr error
+		result, evaluateErr = condition.Evaluate(vars, noInput)
+		return evaluateErr
+	})
+
+	return result, err
+}
+
+// conditionTags returns a tag (without filters) for each of the variables used in the given "when" condition. Invalid
+// conditions are ignored (these are reported by Validate).
+func conditionTags(when string) []variable.Tag {
+	tags := []variable.Tag{}
+	if when == "" {
+		return tags
+	}
+
+	condition, err := variable.CompileCondition(when)
+	if err != nil {
+		return tags
+	}
+
+	for _, name := range condition.Names() {
+		tags = append(tags, variable.Tag{Name: name})
+	}
+
+	return tags
+}
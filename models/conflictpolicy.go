@@ -0,0 +1,14 @@
+package models
+
+// ConflictPolicy identifies how to handle a path that is to be generated, but already exists
+type ConflictPolicy string
+
+const (
+	ConflictError     ConflictPolicy = "error"     // Nothing is generated, and the existing paths are reported
+	ConflictSkip      ConflictPolicy = "skip"      // The existing paths are kept, and everything else is generated
+	ConflictOverwrite ConflictPolicy = "overwrite" // The existing files are replaced
+	ConflictPrompt    ConflictPolicy = "prompt"    // The user is asked how to handle each existing file
+)
+
+// ConflictPolicies is every valid ConflictPolicy
+var ConflictPolicies = []ConflictPolicy{ConflictError, ConflictSkip, ConflictOverwrite, ConflictPrompt}
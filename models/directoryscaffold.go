@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/M-Derbyshire/scaff/customerrors"
@@ -8,9 +9,17 @@ import (
 
 // DirectoryScaffold represents a directory to be created
 type DirectoryScaffold struct {
-	Name        string              `json:"name"`
-	Files       []FileScaffold      `json:"files"`
-	Directories []DirectoryScaffold `json:"directories"`
+	Name         string              `json:"name"`
+	Files        []FileScaffold      `json:"files"`
+	Directories  []DirectoryScaffold `json:"directories"`
+	TemplateTree *TemplateTree       `json:"templateTree"` // A directory of templates, whose contents are also created in this directory
+	When         string              `json:"when"`         // A condition (e.g. "withDocker == 'true'"). If set, the directory (and its contents) is only created if this is true
+	ForEach      string              `json:"forEach"`      // The name of a list variable. If set, the directory (and its contents) is created once for each item in the list
+	As           string              `json:"as"`           // The name given to the current item of the forEach list (in the directory's name and condition, and its contents)
+
+	// The values of the loop variables from the forEach of the directory (or of the directories it is in). These aren't
+	// part of the directory object, and are set when the command is expanded (see Command.Expand).
+	LoopVars map[string]string `json:"-"`
 }
 
 // Validate validates the properties in the DirectoryScaffold, and returns any validation errors
@@ -21,19 +30,27 @@ func (ds *DirectoryScaffold) Validate(templateDirectoryPath string) []customerro
 	trimmedName := strings.TrimSpace(ds.Name)
 	if len(trimmedName) == 0 {
 		newErr := customerrors.ValidationError{
-			Message: "directory scaffold objects should have a 'name' property that is set to a non-empty value",
+			Message:  "directory scaffold objects should have a 'name' property that is set to a non-empty value",
+			Location: "name",
 		}
 		errs = append(errs, newErr)
 	}
 
-	for _, file := range ds.Files {
+	for i, file := range ds.Files {
 		fileErrs := file.Validate(templateDirectoryPath)
-		errs = append(errs, fileErrs...)
+		errs = append(errs, customerrors.PrefixLocations(fileErrs, fmt.Sprintf("files[%d]", i))...)
 	}
 
-	for _, directory := range ds.Directories {
+	for i, directory := range ds.Directories {
 		dirErrs := directory.Validate(templateDirectoryPath)
-		errs = append(errs, dirErrs...)
+		errs = append(errs, customerrors.PrefixLocations(dirErrs, fmt.Sprintf("directories[%d]", i))...)
+	}
+
+	errs = append(errs, validateWhen(ds.When, "directory scaffold")...)
+	errs = append(errs, validateForEach(ds.ForEach, ds.As, "directory scaffold")...)
+
+	if ds.TemplateTree != nil {
+		errs = append(errs, customerrors.PrefixLocations(ds.TemplateTree.Validate(templateDirectoryPath), "templateTree")...)
 	}
 
 	return errs
@@ -0,0 +1,226 @@
+package models
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+// validateForEach validates the "forEach" and "as" properties of a file/directory scaffold object (the objectType is used
+// in the messages)
+func validateForEach(forEach, as, objectType string) []customerrors.ValidationError {
+	errs := []customerrors.ValidationError{}
+	if forEach == "" && as == "" {
+		return errs
+	}
+
+	if forEach == "" || as == "" {
+		missingProperty := "forEach"
+		if as == "" {
+			missingProperty = "as"
+		}
+
+		return append(errs, customerrors.ValidationError{
+			Message:  fmt.Sprintf("%s objects should have both a 'forEach' and an 'as' property, if either is set", objectType),
+			Location: missingProperty,
+		})
+	}
+
+	if !variable.IsValidName(forEach) {
+		errs = append(errs, customerrors.ValidationError{
+			Message:  fmt.Sprintf("%s objects should have a 'forEach' property that is a valid variable name (got '%s')", objectType, forEach),
+			Location: "forEach",
+		})
+	}
+
+	if !variable.IsValidName(as) {
+		errs = append(errs, customerrors.ValidationError{
+			Message:  fmt.Sprintf("%s objects should have an 'as' property that is a valid variable name (got '%s')", objectType, as),
+			Location: "as",
+		})
+	} else if as == forEach {
+		errs = append(errs, customerrors.ValidationError{
+			Message:  fmt.Sprintf("%s objects should have an 'as' property that is different to their 'forEach' property (got '%s')", objectType, as),
+			Location: "as",
+		})
+	}
+
+	return errs
+}
+
+// loopNames returns the names of the loop variables that are available to a file/directory (and its contents): those
+// from the directories it is in (the parentNames), its own loop variables, and those from its forEach (if it has one)
+func loopNames(parentNames []string, forEach, as string, loopVars map[string]string) []string {
+	names := slices.Clone(parentNames)
+	names = append(names, slices.Sorted(maps.Keys(loopVars))...)
+
+	if forEach != "" {
+		names = append(names, variable.LoopNames(as)...)
+	}
+
+	return names
+}
+
+// StructureNames returns the names of the variables that decide which files/directories the command creates: the lists
+// used in "forEach" properties, and the variables used in "when" conditions (in the order they are first used). Loop
+// variables aren't included.
+func (c *Command) StructureNames() []string {
+	names := []string{}
+
+	addNames := func(newNames []string, hiddenNames []string) {
+		for _, name := range newNames {
+			if !slices.Contains(hiddenNames, name) && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	conditionNames := func(when string) []string {
+		whenNames := []string{}
+		for _, tag := range conditionTags(when) {
+			whenNames = append(whenNames, tag.Name)
+		}
+
+		return whenNames
+	}
+
+	var scan func(files []FileScaffold, directories []DirectoryScaffold, hiddenNames []string)
+	scan = func(files []FileScaffold, directories []DirectoryScaffold, hiddenNames []string) {
+		for _, file := range files {
+			if file.ForEach != "" {
+				addNames([]string{file.ForEach}, hiddenNames)
+			}
+
+			addNames(conditionNames(file.When), loopNames(hiddenNames, file.ForEach, file.As, file.LoopVars))
+		}
+
+		for _, directory := range directories {
+			if directory.ForEach != "" {
+				addNames([]string{directory.ForEach}, hiddenNames)
+			}
+
+			directoryHiddenNames := loopNames(hiddenNames, directory.ForEach, directory.As, directory.LoopVars)
+			addNames(conditionNames(directory.When), directoryHiddenNames)
+			scan(directory.Files, directory.Directories, directoryHiddenNames)
+		}
+	}
+	scan(c.Files, c.Directories, []string{})
+
+	return names
+}
+
+// Expand returns a copy of the command for the given variables. Files/directories with a "forEach" are repeated for each
+// item in their list (each copy has no forEach, and is given the values of its loop variables), and those whose "when"
+// conditions are false are removed. The command itself isn't changed.
+// If a variable used in a forEach or condition doesn't exist in the map, the user is prompted to provide it (unless the
+// command has NoInput set, in which case a MissingVariablesError is returned).
+func (c *Command) Expand(vars map[string]string) (Command, error) {
+	expanded := *c
+
+	files, directories, err := expandForVars(c.Files, c.Directories, nil, vars, c.NoInput)
+	if err != nil {
+		return *c, err
+	}
+
+	expanded.Files, expanded.Directories = files, directories
+	return expanded, nil
+}
+
+// expandForVars returns expanded copies of the given files and directories (see Command.Expand). The parentLoopVars are
+// the loop variables from the directories they are in.
+func expandForVars(files []FileScaffold, directories []DirectoryScaffold, parentLoopVars, vars map[string]string, noInput bool) ([]FileScaffold, []DirectoryScaffold, error) {
+	expandedFiles := []FileScaffold{}
+	for _, file := range files {
+		repeats, err := repeatLoopVars(file.ForEach, file.As, mergeLoopVars(parentLoopVars, file.LoopVars), vars, noInput)
+		if err != nil {
+			return files, directories, err
+		}
+
+		for _, loopVars := range repeats {
+			isFileIncluded, err := isIncluded(file.When, vars, loopVars, noInput)
+			if err != nil {
+				return files, directories, err
+			}
+
+			if isFileIncluded {
+				expandedFile := file
+				expandedFile.ForEach, expandedFile.As, expandedFile.LoopVars = "", "", loopVars
+				expandedFiles = append(expandedFiles, expandedFile)
+			}
+		}
+	}
+
+	expandedDirectories := []DirectoryScaffold{}
+	for _, directory := range directories {
+		repeats, err := repeatLoopVars(directory.ForEach, directory.As, mergeLoopVars(parentLoopVars, directory.LoopVars), vars, noInput)
+		if err != nil {
+			return files, directories, err
+		}
+
+		for _, loopVars := range repeats {
+			isDirectoryIncluded, err := isIncluded(directory.When, vars, loopVars, noInput)
+			if err != nil {
+				return files, directories, err
+			}
+
+			if !isDirectoryIncluded {
+				continue
+			}
+
+			expandedDirectory := directory
+			expandedDirectory.ForEach, expandedDirectory.As, expandedDirectory.LoopVars = "", "", loopVars
+			expandedDirectory.Files, expandedDirectory.Directories, err = expandForVars(directory.Files, directory.Directories, loopVars, vars, noInput)
+			if err != nil {
+				return files, directories, err
+			}
+
+			expandedDirectories = append(expandedDirectories, expandedDirectory)
+		}
+	}
+
+	return expandedFiles, expandedDirectories, nil
+}
+
+// repeatLoopVars returns the loop variables for each copy of a file/directory with the given forEach/as (each one includes
+// the given loopVars, which the file/directory already has). If there is no forEach, there is just one copy.
+func repeatLoopVars(forEach, as string, loopVars, vars map[string]string, noInput bool) ([]map[string]string, error) {
+	if forEach == "" {
+		return []map[string]string{loopVars}, nil
+	}
+
+	var itemLoopVars []map[string]string
+	err := variable.WithLoopVars(vars, loopVars, func() error {
+		var loopErr error
+		itemLoopVars, loopErr = variable.LoopVars(forEach, as, vars, noInput)
+		return loopErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	repeats := []map[string]string{}
+	for _, itemVars := range itemLoopVars {
+		repeats = append(repeats, mergeLoopVars(loopVars, itemVars))
+	}
+
+	return repeats, nil
+}
+
+// mergeLoopVars returns a map of the given loop variables (those in the second map replace any with the same names in the
+// first). If there are none, nil is returned.
+func mergeLoopVars(first, second map[string]string) map[string]string {
+	if len(first) == 0 && len(second) == 0 {
+		return nil
+	}
+
+	merged := maps.Clone(first)
+	if merged == nil {
+		merged = make(map[string]string)
+	}
+	maps.Copy(merged, second)
+
+	return merged
+}
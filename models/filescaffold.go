@@ -1,6 +1,7 @@
 package models
 
 import (
+	"bytes"
 	"fmt"
 	"path"
 	"strings"
@@ -10,15 +11,35 @@ import (
 
 // FileScaffold represents a file to be created
 type FileScaffold struct {
-	Name         string `json:"name"`         // The filename (including extension)
-	TemplatePath string `json:"templatePath"` // Path to the file's template (path relative to the template directory)
+	Name         string   `json:"name"`         // The filename (including extension)
+	TemplatePath string   `json:"templatePath"` // Path to the file's template (path relative to the template directory)
+	Delimiters   []string `json:"delimiters"`   // The opening and closing delimiters of tags in the file's name and template (overrides the command's)
+	Raw          bool     `json:"raw"`          // If true, the template is copied as it is (only the name is populated)
+	When         string   `json:"when"`         // A condition (e.g. "withDocker == 'true'"). If set, the file is only created if this is true
+	ForEach      string   `json:"forEach"`      // The name of a list variable. If set, the file is created once for each item in the list
+	As           string   `json:"as"`           // The name given to the current item of the forEach list (in the file's name, template and condition)
+
+	// The values of the loop variables from the forEach of the file (or of the directories it is in). These aren't part of
+	// the file object, and are set when the command is expanded (see Command.Expand).
+	LoopVars map[string]string `json:"-"`
 }
 
+// binaryCheckLength is the number of bytes at the start of a template that are checked for a NUL byte (see IsRawCopy)
+const binaryCheckLength = 8000
+
 // GetFullTemplatePath returns the full path to the correct template (when given the path to the template directory)
 func (fs *FileScaffold) GetFullTemplatePath(templateDirectoryPath string) string {
 	return path.Join(templateDirectoryPath, fs.TemplatePath)
 }
 
+// IsRawCopy identifies if the file should be a byte-for-byte copy of its template (with the given contents), rather than
+// being populated. This is the case if the file is marked as raw, or if the template is binary (like git, a template is
+// treated as binary if there is a NUL byte within its first 8000 bytes).
+func (fs *FileScaffold) IsRawCopy(templateContents []byte) bool {
+	checkedContents := templateContents[:min(len(templateContents), binaryCheckLength)]
+	return fs.Raw || bytes.IndexByte(checkedContents, 0) >= 0
+}
+
 // Validate validates the properties in the FileScaffold, and returns any validation errors
 // The templateDirectoryPath is the root template directory for the command
 func (fs *FileScaffold) Validate(templateDirectoryPath string) []customerrors.ValidationError {
@@ -29,7 +50,8 @@ func (fs *FileScaffold) Validate(templateDirectoryPath string) []customerrors.Va
 
 	if len(trimmedName) == 0 {
 		newErr := customerrors.ValidationError{
-			Message: "file scaffold objects should have a 'name' property that is set to a non-empty value",
+			Message:  "file scaffold objects should have a 'name' property that is set to a non-empty value",
+			Location: "name",
 		}
 		errs = append(errs, newErr)
 	}
@@ -37,20 +59,26 @@ func (fs *FileScaffold) Validate(templateDirectoryPath string) []customerrors.Va
 	templatePathIsValid := true
 	if len(trimmedTemplatePath) == 0 {
 		newErr := customerrors.ValidationError{
-			Message: "file scaffold objects should have a 'templatePath' property that is set to a non-empty value",
+			Message:  "file scaffold objects should have a 'templatePath' property that is set to a non-empty value",
+			Location: "templatePath",
 		}
 		errs = append(errs, newErr)
 
 		templatePathIsValid = false
 	}
 
+	errs = append(errs, validateDelimiters(fs.Delimiters, "file scaffold")...)
+	errs = append(errs, validateWhen(fs.When, "file scaffold")...)
+	errs = append(errs, validateForEach(fs.ForEach, fs.As, "file scaffold")...)
+
 	if templatePathIsValid {
 		// We want to confirm that the template file exists
 		fullTemplatePath := fs.GetFullTemplatePath(templateDirectoryPath)
 
 		if _, pathErr := FileStat(fullTemplatePath); pathErr != nil {
 			newErr := customerrors.ValidationError{
-				Message: fmt.Sprintf("unable to locate template file at path: '%s'", fullTemplatePath),
+				Message:  fmt.Sprintf("unable to locate template file at path: '%s'", fullTemplatePath),
+				Location: "templatePath",
 			}
 
 			errs = append(errs, newErr)
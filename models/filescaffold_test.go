@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/fs"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/M-Derbyshire/scaff/models"
@@ -269,3 +270,24 @@ func TestFileScaffoldValidateWillNotReturnErrorsIfValid(t *testing.T) {
 		}
 	}
 }
+
+func TestFileScaffoldIsRawCopyWillIdentifyRawAndBinaryFiles(t *testing.T) {
+	cases := []struct {
+		scaffold models.FileScaffold
+		contents []byte
+		expected bool
+	}{
+		{models.FileScaffold{}, []byte("plain {: text :}"), false},
+		{models.FileScaffold{Raw: true}, []byte("plain {: text :}"), true},
+		{models.FileScaffold{}, []byte("\x89PNG\r\n\x1a\n\x00\x00"), true},
+		{models.FileScaffold{}, append([]byte(strings.Repeat("a", 7999)), 0), true},
+		{models.FileScaffold{}, append([]byte(strings.Repeat("a", 8000)), 0), false},
+		{models.FileScaffold{}, []byte{}, false},
+	}
+
+	for i, testCase := range cases {
+		if result := testCase.scaffold.IsRawCopy(testCase.contents); result != testCase.expected {
+			t.Errorf("expected case %d to give %t. got %t", i, testCase.expected, result)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package models
+
+import "io/fs"
+
+// PlanItem represents a single directory/file that will be generated by a command
+type PlanItem struct {
+	Path         string      // The full path to the directory/file that will be created
+	IsDirectory  bool        // Whether this item is a directory (if not, it is a file)
+	TemplatePath string      // The full path to the template used to populate the file (empty for directories)
+	Contents     []byte      // The populated contents of the file (empty for directories)
+	Overwrite    bool        // Whether the file is allowed to replace an existing file at the same path
+	IsRaw        bool        // Whether the file's contents are copied from the template as they are (rather than populated)
+	Mode         fs.FileMode // The permissions the file is created with (if 0, the default permissions are used)
+}
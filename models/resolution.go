@@ -0,0 +1,27 @@
+package models
+
+// Resolution describes how a command name is resolved to a command (see command.Explain)
+type Resolution struct {
+	CommandName        string              `json:"commandName"`
+	CheckedDirectories []CheckedDirectory  `json:"checkedDirectories"` // The directories that are checked for a scaff file (nearest first)
+	ReadFiles          []ReadScaffFile     `json:"readFiles"`          // The scaff files (and child files) that are read, in the order they are searched
+	Definitions        []CommandDefinition `json:"definitions"`        // Every definition of the command, in precedence order (the first is the one that is used)
+}
+
+// CheckedDirectory is a directory that is checked for a scaff file
+type CheckedDirectory struct {
+	Path         string `json:"path"`
+	HasScaffFile bool   `json:"hasScaffFile"`
+}
+
+// ReadScaffFile is a scaff file that is read while searching for a command
+type ReadScaffFile struct {
+	Path    string `json:"path"`
+	ChildOf string `json:"childOf,omitempty"` // The path to the scaff file that lists this one as a child (if it is a child file)
+}
+
+// CommandDefinition is a scaff file that defines a command (with the name being resolved)
+type CommandDefinition struct {
+	ScaffFilePath    string `json:"scaffFile"`
+	FullTemplatePath string `json:"fullTemplatePath"` // The full path to the command's template directory
+}
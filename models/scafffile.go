@@ -10,6 +10,10 @@ import (
 type ScaffFile struct {
 	Commands []Command `json:"commands"` // The defined commands
 	Children []string  `json:"children"` // A list of filepaths to child scaff-files (each path is relative to this scaff-file)
+
+	// The path to a directory of shared partials, which can be included in the templates of this file's commands (and the
+	// commands in its child files). This path is relative to this scaff-file.
+	PartialsDirectoryPath string `json:"partialsDirectoryPath"`
 }
 
 // ValidateChildrenArray validates that the "Children" are valid strings (for their purpose)
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+)
+
+// templateSuffix is removed from the names of files in a template tree (e.g. "main.go.tmpl" creates "main.go")
+const templateSuffix = ".tmpl"
+
+// TemplateTree is a directory of templates that is copied as a whole (rather than listing each file/directory). The names
+// of the directories/files within it can contain variable tags.
+type TemplateTree struct {
+	Path    string   `json:"path"`    // The path to the directory (relative to the command's template directory)
+	Include []string `json:"include"` // If any are given, only the files that match one of these glob patterns are copied
+	Exclude []string `json:"exclude"` // Directories/files that match any of these glob patterns aren't copied
+}
+
+// UnmarshalJSON allows a template tree to be given as just its path (e.g. `"templateTree": "service"`), or as an object
+func (t *TemplateTree) UnmarshalJSON(data []byte) error {
+	var treePath string
+	if err := json.Unmarshal(data, &treePath); err == nil {
+		*t = TemplateTree{Path: treePath}
+		return nil
+	}
+
+	// A separate type is used, so this method isn't called again
+	type templateTreeObject TemplateTree
+	return json.Unmarshal(data, (*templateTreeObject)(t))
+}
+
+// Validate validates the properties in the TemplateTree, and returns any validation errors
+// The templateDirectoryPath is the root template directory for the command
+func (t *TemplateTree) Validate(templateDirectoryPath string) []customerrors.ValidationError {
+	errs := []customerrors.ValidationError{}
+
+	if len(strings.TrimSpace(t.Path)) == 0 {
+		errs = append(errs, customerrors.ValidationError{
+			Message:  "template tree objects should have a 'path' property that is set to a non-empty value",
+			Location: "path",
+		})
+	} else {
+		fullTreePath := path.Join(templateDirectoryPath, t.Path)
+		if treeInfo, statErr := FileStat(fullTreePath); statErr != nil || !treeInfo.IsDir() {
+			errs = append(errs, customerrors.ValidationError{
+				Message:  fmt.Sprintf("unable to locate template tree directory at path: '%s'", fullTreePath),
+				Location: "path",
+			})
+		}
+	}
+
+	for propertyName, patterns := range map[string][]string{"include": t.Include, "exclude": t.Exclude} {
+		for i, pattern := range patterns {
+			if _, matchErr := path.Match(pattern, ""); matchErr != nil || pattern == "" {
+				errs = append(errs, customerrors.ValidationError{
+					Message:  fmt.Sprintf("the template tree pattern '%s' is not a valid glob pattern", pattern),
+					Location: fmt.Sprintf("%s[%d]", propertyName, i),
+				})
+			}
+		}
+	}
+
+	return errs
+}
+
+// expandScaffolds returns copies of the given files and directories, with the contents of the template tree (if there is
+// one) added to them, and the template trees of the directories expanded (see Command.ExpandTemplateTrees)
+func expandScaffolds(files []FileScaffold, directories []DirectoryScaffold, tree *TemplateTree, templateDirectoryPath string) ([]FileScaffold, []DirectoryScaffold, error) {
+	expandedFiles := slices.Clone(files)
+	expandedDirectories := make([]DirectoryScaffold, 0, len(directories))
+
+	for _, directory := range directories {
+		innerFiles, innerDirectories, err := expandScaffolds(directory.Files, directory.Directories, directory.TemplateTree, templateDirectoryPath)
+		if err != nil {
+			return files, directories, err
+		}
+
+		directory.Files, directory.Directories, directory.TemplateTree = innerFiles, innerDirectories, nil
+		expandedDirectories = append(expandedDirectories, directory)
+	}
+
+	if tree == nil {
+		return expandedFiles, expandedDirectories, nil
+	}
+
+	treeFiles, treeDirectories, err := tree.expand(templateDirectoryPath)
+	if err != nil {
+		return files, directories, err
+	}
+
+	return append(expandedFiles, treeFiles...), append(expandedDirectories, treeDirectories...), nil
+}
+
+// expand walks the template tree, and returns the files and directories within it (as scaffolds).
+// Files named with the ".tmpl" suffix have it removed. If there are include patterns, directories that don't contain any
+// included files are skipped.
+// The templateDirectoryPath is the root template directory for the command
+func (t *TemplateTree) expand(templateDirectoryPath string) ([]FileScaffold, []DirectoryScaffold, error) {
+	return t.expandDirectory(templateDirectoryPath, "")
+}
+
+// expandDirectory returns the files and directories within the directory at the given path (relative to the tree)
+func (t *TemplateTree) expandDirectory(templateDirectoryPath, relativeDirPath string) ([]FileScaffold, []DirectoryScaffold, error) {
+	files := []FileScaffold{}
+	directories := []DirectoryScaffold{}
+
+	entries, readErr := ReadDir(path.Join(templateDirectoryPath, t.Path, relativeDirPath))
+	if readErr != nil {
+		return files, directories, readErr
+	}
+
+	for _, entry := range entries {
+		relativePath := path.Join(relativeDirPath, entry.Name())
+		if matchesAnyPattern(t.Exclude, relativePath) {
+			continue
+		}
+
+		if entry.IsDir() {
+			innerFiles, innerDirectories, err := t.expandDirectory(templateDirectoryPath, relativePath)
+			if err != nil {
+				return files, directories, err
+			}
+
+			if len(t.Include) > 0 && len(innerFiles) == 0 && len(innerDirectories) == 0 {
+				continue
+			}
+
+			directories = append(directories, DirectoryScaffold{
+				Name:        entry.Name(),
+				Files:       innerFiles,
+				Directories: innerDirectories,
+			})
+			continue
+		}
+
+		if len(t.Include) > 0 && !matchesAnyPattern(t.Include, relativePath) {
+			continue
+		}
+
+		files = append(files, FileScaffold{
+			Name:         strings.TrimSuffix(entry.Name(), templateSuffix),
+			TemplatePath: path.Join(t.Path, relativePath),
+		})
+	}
+
+	return files, directories, nil
+}
+
+// matchesAnyPattern identifies if the given path (relative to a template tree) matches any of the glob patterns.
+// Patterns without a "/" are matched against the last part of the path (e.g. "*.md" matches "docs/readme.md"). Other patterns
+// are matched against the whole path, and can contain "**" to match any number of directories (e.g. "docs/**/*.md").
+func matchesAnyPattern(patterns []string, relativePath string) bool {
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			if isMatch, _ := path.Match(pattern, path.Base(relativePath)); isMatch {
+				return true
+			}
+
+			continue
+		}
+
+		if matchesSegments(strings.Split(pattern, "/"), strings.Split(relativePath, "/")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesSegments identifies if the parts of a path match the parts of a glob pattern (where a "**" part matches any number
+// of path parts)
+func matchesSegments(patternParts, pathParts []string) bool {
+	if len(patternParts) == 0 {
+		return len(pathParts) == 0
+	}
+
+	if patternParts[0] == "**" {
+		for i := 0; i <= len(pathParts); i++ {
+			if matchesSegments(patternParts[1:], pathParts[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(pathParts) == 0 {
+		return false
+	}
+
+	isMatch, _ := path.Match(patternParts[0], pathParts[0])
+	return isMatch && matchesSegments(patternParts[1:], pathParts[1:])
+}
@@ -0,0 +1,204 @@
+package models_test
+
+import (
+	"encoding/json"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/M-Derbyshire/scaff/models"
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+// mockTemplateTreeFS sets the filesystem funcs to use the given files (their paths are relative to "/templates")
+func mockTemplateTreeFS(files fstest.MapFS) {
+	relativePath := func(fullPath string) string {
+		return strings.TrimPrefix(strings.TrimPrefix(fullPath, "/templates"), "/")
+	}
+
+	models.ReadDir = func(dirPath string) ([]fs.DirEntry, error) {
+		return files.ReadDir(relativePath(dirPath))
+	}
+
+	models.FileStat = func(filePath string) (fs.FileInfo, error) {
+		return files.Stat(relativePath(filePath))
+	}
+
+	models.ReadFile = func(filePath string) ([]byte, error) {
+		return files.ReadFile(relativePath(filePath))
+	}
+}
+
+// describeScaffolds returns a line for each of the given directories/files (e.g. "dir/file.txt <- template.txt")
+func describeScaffolds(files []models.FileScaffold, directories []models.DirectoryScaffold, parentPath string) []string {
+	lines := []string{}
+	for _, file := range files {
+		lines = append(lines, parentPath+file.Name+" <- "+file.TemplatePath)
+	}
+
+	for _, directory := range directories {
+		lines = append(lines, parentPath+directory.Name+"/")
+		lines = append(lines, describeScaffolds(directory.Files, directory.Directories, parentPath+directory.Name+"/")...)
+	}
+
+	return lines
+}
+
+func TestCommandExpandTemplateTreesWillAddTheContentsOfEachTree(t *testing.T) {
+	mockTemplateTreeFS(fstest.MapFS{
+		"service/main.go.tmpl":               {},
+		"service/{: name :}/handler.go.tmpl": {},
+		"service/{: name :}/empty/.gitkeep":  {},
+		"service/README.md":                  {},
+		"docs/guide.md":                      {},
+		"docs/images/logo.png":               {},
+		"listed.txt":                         {},
+	})
+
+	command := models.Command{
+		TemplateTree: &models.TemplateTree{Path: "service"},
+		Files:        []models.FileScaffold{{Name: "listed.txt", TemplatePath: "listed.txt"}},
+		Directories: []models.DirectoryScaffold{
+			{Name: "documentation", TemplateTree: &models.TemplateTree{Path: "docs"}},
+		},
+	}
+
+	expanded, err := command.ExpandTemplateTrees("/templates")
+	if err != nil {
+		t.Fatalf("expected no error. got '%s'", err.Error())
+	}
+
+	expectedLines := []string{
+		"listed.txt <- listed.txt",
+		"README.md <- service/README.md",
+		"main.go <- service/main.go.tmpl",
+		"documentation/",
+		"documentation/guide.md <- docs/guide.md",
+		"documentation/images/",
+		"documentation/images/logo.png <- docs/images/logo.png",
+		"{: name :}/",
+		"{: name :}/handler.go <- service/{: name :}/handler.go.tmpl",
+		"{: name :}/empty/",
+		"{: name :}/empty/.gitkeep <- service/{: name :}/empty/.gitkeep",
+	}
+
+	resultLines := describeScaffolds(expanded.Files, expanded.Directories, "")
+	if !slices.Equal(resultLines, expectedLines) {
+		t.Errorf("expected the expanded scaffolds to be:\n%s\ngot:\n%s", strings.Join(expectedLines, "\n"), strings.Join(resultLines, "\n"))
+	}
+
+	if expanded.TemplateTree != nil || expanded.Directories[0].TemplateTree != nil {
+		t.Errorf("expected the template trees of the expanded command to be removed")
+	}
+
+	if len(command.Files) != 1 || command.TemplateTree == nil || command.Directories[0].TemplateTree == nil {
+		t.Errorf("expected the original command to be unchanged")
+	}
+}
+
+func TestCommandExpandTemplateTreesWillOnlyCopyIncludedFilesThatArentExcluded(t *testing.T) {
+	mockTemplateTreeFS(fstest.MapFS{
+		"tree/main.go":                  {},
+		"tree/main_test.go":             {},
+		"tree/notes.txt":                {},
+		"tree/pkg/util.go":              {},
+		"tree/pkg/deep/inner.go":        {},
+		"tree/pkg/deep/inner.txt":       {},
+		"tree/assets/logo.png":          {},
+		"tree/node_modules/lib/code.go": {},
+	})
+
+	command := models.Command{
+		TemplateTree: &models.TemplateTree{
+			Path:    "tree",
+			Include: []string{"*.go", "pkg/**/*.txt"},
+			Exclude: []string{"*_test.go", "node_modules"},
+		},
+	}
+
+	expanded, err := command.ExpandTemplateTrees("/templates")
+	if err != nil {
+		t.Fatalf("expected no error. got '%s'", err.Error())
+	}
+
+	expectedLines := []string{
+		"main.go <- tree/main.go",
+		"pkg/",
+		"pkg/util.go <- tree/pkg/util.go",
+		"pkg/deep/",
+		"pkg/deep/inner.go <- tree/pkg/deep/inner.go",
+		"pkg/deep/inner.txt <- tree/pkg/deep/inner.txt",
+	}
+
+	resultLines := describeScaffolds(expanded.Files, expanded.Directories, "")
+	if !slices.Equal(resultLines, expectedLines) {
+		t.Errorf("expected the expanded scaffolds to be:\n%s\ngot:\n%s", strings.Join(expectedLines, "\n"), strings.Join(resultLines, "\n"))
+	}
+}
+
+func TestTemplateTreeCanBeGivenAsAPathOrAnObject(t *testing.T) {
+	var command models.Command
+	if err := json.Unmarshal([]byte(`{"templateTree": "service"}`), &command); err != nil {
+		t.Fatalf("expected no error. got '%s'", err.Error())
+	}
+
+	if command.TemplateTree == nil || command.TemplateTree.Path != "service" {
+		t.Errorf("expected the template tree path to be 'service'. got %v", command.TemplateTree)
+	}
+
+	var directory models.DirectoryScaffold
+	if err := json.Unmarshal([]byte(`{"templateTree": {"path": "docs", "exclude": ["*.png"]}}`), &directory); err != nil {
+		t.Fatalf("expected no error. got '%s'", err.Error())
+	}
+
+	if directory.TemplateTree == nil || directory.TemplateTree.Path != "docs" || !slices.Equal(directory.TemplateTree.Exclude, []string{"*.png"}) {
+		t.Errorf("expected the template tree to have the path 'docs' and exclude '*.png'. got %v", directory.TemplateTree)
+	}
+}
+
+func TestTemplateTreeValidateWillReturnErrorsForMissingPathsAndInvalidPatterns(t *testing.T) {
+	mockTemplateTreeFS(fstest.MapFS{
+		"tree/file.txt": {},
+	})
+
+	cases := map[string]models.TemplateTree{
+		"template tree objects should have a 'path' property that is set to a non-empty value": {Path: " "},
+		"unable to locate template tree directory at path: '/templates/missing'":               {Path: "missing"},
+		"unable to locate template tree directory at path: '/templates/tree/file.txt'":         {Path: "tree/file.txt"},
+		"the template tree pattern '[' is not a valid glob pattern":                            {Path: "tree", Include: []string{"["}},
+		"the template tree pattern '' is not a valid glob pattern":                             {Path: "tree", Exclude: []string{""}},
+	}
+
+	for expectedMessage, tree := range cases {
+		results := tree.Validate("/templates")
+		if len(results) != 1 || results[0].Message != expectedMessage {
+			t.Errorf("expected the error '%s'. got %v", expectedMessage, results)
+		}
+	}
+
+	validTree := models.TemplateTree{Path: "tree", Include: []string{"**/*.txt"}}
+	if results := validTree.Validate("/templates"); len(results) > 0 {
+		t.Errorf("expected no errors. got %v", results)
+	}
+}
+
+func TestCommandValidateShouldCheckTheVariablesInTemplateTreesAreDeclared(t *testing.T) {
+	mockTemplateTreeFS(fstest.MapFS{
+		"tree/{: name :}.txt": {Data: []byte("{: undeclared :}")},
+	})
+
+	command := models.Command{
+		TemplateDirectoryPath: "/templates",
+		TemplateTree:          &models.TemplateTree{Path: "tree"},
+		Vars:                  []variable.Definition{{Name: "name"}},
+	}
+
+	results := command.Validate("/templates")
+
+	expectedMessage := "the variable 'undeclared' is used in the template '/templates/tree/{: name :}.txt', but is not declared in the command's 'vars'"
+	if len(results) != 1 || results[0].Message != expectedMessage {
+		t.Errorf("expected the error '%s'. got %v", expectedMessage, results)
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+// ValidationReport is the result of validating a scaff file, along with all of its child files
+type ValidationReport struct {
+	ScaffFilePaths []string            `json:"scaffFiles"`   // The full paths to every scaff file that was read
+	CommandCount   int                 `json:"commandCount"` // The number of commands that were validated
+	Problems       []ValidationProblem `json:"problems"`
+}
+
+// ValidationProblem is a problem found in a scaff file (or in one of its commands)
+type ValidationProblem struct {
+	ScaffFilePath string `json:"scaffFile"`          // The full path to the scaff file that contains the problem
+	CommandName   string `json:"command,omitempty"`  // The name of the command that contains the problem (if any)
+	Location      string `json:"location,omitempty"` // Where the problem is within the scaff file, as a JSON path (e.g. "commands[0].files[1].name")
+	Message       string `json:"message"`
+}
@@ -8,6 +8,14 @@ import (
 // FileStat is used to get details about files in the filesystem (this can also be used to confirm a file exists)
 var FileStat func(filePath string) (fs.FileInfo, error)
 
+// ReadFile is used to read files from the filesystem
+var ReadFile func(filePath string) ([]byte, error)
+
+// ReadDir is used to read the entries in a directory (sorted by name)
+var ReadDir func(dirPath string) ([]fs.DirEntry, error)
+
 func init() {
 	FileStat = os.Stat
+	ReadFile = os.ReadFile
+	ReadDir = os.ReadDir
 }
@@ -0,0 +1,9 @@
+package models
+
+// VariableUsage is a variable that is used in a command's names/templates
+type VariableUsage struct {
+	Name       string
+	Locations  []string // Describes each place the variable is used (e.g. "the file name 'my_{: var1 :}.txt'")
+	HasDefault bool     // True if every use of the variable has a default value (so a value doesn't need to be provided)
+	IsList     bool     // True if the variable is used as the list in a range block
+}
@@ -0,0 +1,2 @@
+// Package options handles the flags that are passed into the program by the user
+package options
@@ -0,0 +1,98 @@
+package options
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/M-Derbyshire/scaff/models"
+)
+
+// Options holds the settings that can be provided to the application via flags
+type Options struct {
+	DryRun           bool                  // If true, the generation plan is printed, rather than written to disk
+	OnConflict       models.ConflictPolicy // How to handle paths that already exist
+	AllowOutsideRoot bool                  // If true, paths outside of the working directory can be generated
+	NoInput          bool                  // If true, the user is never prompted for input
+	VarsFiles        []string              // Paths to files to load variables from (in the order they were given)
+	JSON             bool                  // If true, subcommands (e.g. "list") output JSON, rather than text
+	Explain          bool                  // If true, a trace of how the command name is resolved is output before the command is run
+	NotSubcommand    bool                  // If true, the command name is never treated as a subcommand (set by giving "--")
+}
+
+// Parse separates any flags (arguments starting with "-") from the given arguments.
+// Flags that take a value can be given as "--flag=value" or "--flag value". A "--" argument identifies that the command
+// name is not a subcommand (so a command with the same name as a subcommand can be run).
+// Returns the parsed Options, and the remaining (non-flag) arguments in their original order.
+// An error is returned if an unrecognised flag (or an invalid flag value) is given.
+func Parse(args []string) (Options, []string, error) {
+	opts := Options{
+		OnConflict: models.ConflictError,
+	}
+	remainingArgs := []string{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			opts.NotSubcommand = true
+			continue
+		}
+
+		if !strings.HasPrefix(arg, "-") {
+			remainingArgs = append(remainingArgs, arg)
+			continue
+		}
+
+		flagName, flagValue, hasValue := strings.Cut(arg, "=")
+
+		// Gets the flag's value (which may be the next argument)
+		takeValue := func() (string, error) {
+			if hasValue {
+				return flagValue, nil
+			}
+
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("the '%s' flag requires a value", flagName)
+			}
+
+			i++
+			return args[i], nil
+		}
+
+		switch {
+		case strings.EqualFold(arg, "--dry-run"):
+			opts.DryRun = true
+		case strings.EqualFold(arg, "--no-input"):
+			opts.NoInput = true
+		case strings.EqualFold(arg, "--allow-outside-root"):
+			opts.AllowOutsideRoot = true
+		case strings.EqualFold(arg, "--json"):
+			opts.JSON = true
+		case strings.EqualFold(arg, "--explain"):
+			opts.Explain = true
+		case strings.EqualFold(flagName, "--vars-file"):
+			value, err := takeValue()
+			if err != nil {
+				return opts, remainingArgs, err
+			}
+
+			opts.VarsFiles = append(opts.VarsFiles, value)
+		case strings.EqualFold(flagName, "--on-conflict"):
+			value, err := takeValue()
+			if err != nil {
+				return opts, remainingArgs, err
+			}
+
+			policy := models.ConflictPolicy(strings.ToLower(value))
+			if !slices.Contains(models.ConflictPolicies, policy) {
+				return opts, remainingArgs, fmt.Errorf("invalid value for '%s': '%s' (expected one of: %v)", flagName, value, models.ConflictPolicies)
+			}
+
+			opts.OnConflict = policy
+		default:
+			return opts, remainingArgs, fmt.Errorf("unrecognised flag: '%s'", arg)
+		}
+	}
+
+	return opts, remainingArgs, nil
+}
@@ -0,0 +1,212 @@
+package options_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/models"
+	"github.com/M-Derbyshire/scaff/options"
+)
+
+func TestParseWillReturnNonFlagArgumentsInOrder(t *testing.T) {
+	args := []string{"my_command", "--dry-run", "var1=val1", "var2=val2"}
+	expectedArgs := []string{"my_command", "var1=val1", "var2=val2"}
+
+	_, result, err := options.Parse(args)
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if !slices.Equal(result, expectedArgs) {
+		t.Errorf("expected remaining arguments to be %v. Got %v", expectedArgs, result)
+	}
+}
+
+func TestParseWillSetDryRunIfFlagGiven(t *testing.T) {
+	flags := []string{"--dry-run", "--DRY-RUN", "--Dry-Run"}
+
+	for _, flag := range flags {
+		result, _, err := options.Parse([]string{"my_command", flag})
+		if err != nil {
+			t.Errorf("expected no error. Got '%s'", err.Error())
+		}
+
+		if !result.DryRun {
+			t.Errorf("expected DryRun to be true when given '%s'. Got false", flag)
+		}
+	}
+}
+
+func TestParseWillNotSetDryRunIfFlagNotGiven(t *testing.T) {
+	result, _, err := options.Parse([]string{"my_command", "var1=val1"})
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if result.DryRun {
+		t.Errorf("expected DryRun to be false. Got true")
+	}
+}
+
+func TestParseWillReturnErrorForUnrecognisedFlag(t *testing.T) {
+	expectedErr := "unrecognised flag: '--not-a-flag'"
+
+	_, _, err := options.Parse([]string{"my_command", "--not-a-flag"})
+	if err == nil {
+		t.Errorf("expected an error. Got nil")
+		return
+	}
+
+	if err.Error() != expectedErr {
+		t.Errorf("expected error to be '%s'. Got '%s'", expectedErr, err.Error())
+	}
+}
+
+func TestParseWillDefaultOnConflictToError(t *testing.T) {
+	result, _, err := options.Parse([]string{"my_command"})
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if result.OnConflict != models.ConflictError {
+		t.Errorf("expected OnConflict to be '%s'. Got '%s'", models.ConflictError, result.OnConflict)
+	}
+}
+
+func TestParseWillSetOnConflictFromFlagValue(t *testing.T) {
+	argsToTest := [][]string{
+		{"my_command", "--on-conflict=skip"},
+		{"my_command", "--on-conflict", "skip"},
+		{"my_command", "--ON-CONFLICT=SKIP"},
+	}
+
+	for _, args := range argsToTest {
+		result, remainingArgs, err := options.Parse(args)
+		if err != nil {
+			t.Errorf("expected no error for %v. Got '%s'", args, err.Error())
+		}
+
+		if result.OnConflict != models.ConflictSkip {
+			t.Errorf("expected OnConflict to be '%s' for %v. Got '%s'", models.ConflictSkip, args, result.OnConflict)
+		}
+
+		if !slices.Equal(remainingArgs, []string{"my_command"}) {
+			t.Errorf("expected the flag value not to be in the remaining arguments for %v. Got %v", args, remainingArgs)
+		}
+	}
+}
+
+func TestParseWillReturnErrorForInvalidOnConflictValue(t *testing.T) {
+	_, _, err := options.Parse([]string{"my_command", "--on-conflict=sometimes"})
+
+	if err == nil {
+		t.Errorf("expected an error. Got nil")
+	}
+}
+
+func TestParseWillReturnErrorIfFlagValueMissing(t *testing.T) {
+	expectedErr := "the '--on-conflict' flag requires a value"
+
+	_, _, err := options.Parse([]string{"my_command", "--on-conflict"})
+	if err == nil {
+		t.Errorf("expected an error. Got nil")
+		return
+	}
+
+	if err.Error() != expectedErr {
+		t.Errorf("expected error to be '%s'. Got '%s'", expectedErr, err.Error())
+	}
+}
+
+func TestParseWillSetAllowOutsideRootIfFlagGiven(t *testing.T) {
+	result, _, err := options.Parse([]string{"my_command", "--Allow-Outside-Root"})
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if !result.AllowOutsideRoot {
+		t.Errorf("expected AllowOutsideRoot to be true. Got false")
+	}
+}
+
+func TestParseWillNotSetAllowOutsideRootIfFlagNotGiven(t *testing.T) {
+	result, _, err := options.Parse([]string{"my_command"})
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if result.AllowOutsideRoot {
+		t.Errorf("expected AllowOutsideRoot to be false. Got true")
+	}
+}
+
+func TestParseWillSetNoInputIfFlagGiven(t *testing.T) {
+	result, _, err := options.Parse([]string{"my_command", "--NO-INPUT"})
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if !result.NoInput {
+		t.Errorf("expected NoInput to be true. Got false")
+	}
+}
+
+func TestParseWillCollectEveryVarsFileInOrder(t *testing.T) {
+	args := []string{"my_command", "--vars-file", "first.json", "var1=val1", "--VARS-FILE=second.yaml", "--vars-file=third.env"}
+	expectedFiles := []string{"first.json", "second.yaml", "third.env"}
+
+	result, remainingArgs, err := options.Parse(args)
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if !slices.Equal(result.VarsFiles, expectedFiles) {
+		t.Errorf("expected VarsFiles to be %v. Got %v", expectedFiles, result.VarsFiles)
+	}
+
+	expectedArgs := []string{"my_command", "var1=val1"}
+	if !slices.Equal(remainingArgs, expectedArgs) {
+		t.Errorf("expected remaining arguments to be %v. Got %v", expectedArgs, remainingArgs)
+	}
+}
+
+func TestParseWillSetJSONIfFlagGiven(t *testing.T) {
+	result, remainingArgs, err := options.Parse([]string{"list", "--Json"})
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if !result.JSON {
+		t.Errorf("expected JSON to be true. Got false")
+	}
+
+	if !slices.Equal(remainingArgs, []string{"list"}) {
+		t.Errorf("expected remaining arguments to be [list]. Got %v", remainingArgs)
+	}
+}
+
+func TestParseWillSetExplainIfFlagGiven(t *testing.T) {
+	result, _, err := options.Parse([]string{"my_command", "--EXPLAIN"})
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if !result.Explain {
+		t.Errorf("expected Explain to be true. Got false")
+	}
+}
+
+func TestParseWillSetNotSubcommandIfDoubleDashGiven(t *testing.T) {
+	result, remainingArgs, err := options.Parse([]string{"--", "list", "--dry-run"})
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if !result.NotSubcommand || !result.DryRun {
+		t.Errorf("expected NotSubcommand and DryRun to be true. Got %+v", result)
+	}
+
+	if !slices.Equal(remainingArgs, []string{"list"}) {
+		t.Errorf("expected remaining arguments to be [list]. Got %v", remainingArgs)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/M-Derbyshire/scaff/command"
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/options"
+)
+
+// subcommands are the functions that run each subcommand (keyed by the subcommand's name). The args given to each are any
+// arguments given after the subcommand's name.
+var subcommands = map[string]func(opts options.Options, args []string, scaffFileNameAndExt, workingDir string){
+	command.SubcommandList:     runList,
+	command.SubcommandWhich:    runWhich,
+	command.SubcommandDescribe: runDescribe,
+	command.SubcommandValidate: runValidate,
+}
+
+// warnIfCommandIsHidden prints a warning (to the Stderr) if a command with the given subcommand name can be found from the
+// working directory, as the subcommand is run instead of it
+func warnIfCommandIsHidden(subcommandName, scaffFileNameAndExt, workingDir string) {
+	_, _, isFound, err := command.Find(subcommandName, scaffFileNameAndExt, workingDir)
+	if err == nil && isFound {
+		fmt.Fprintf(os.Stderr, "warning: a scaff file defines a command named '%s', but this is the name of a subcommand (use 'scaff -- %s' to run the command)\n", subcommandName, subcommandName)
+	}
+}
+
+// runList prints every command that can be found from the working directory, and where each one is defined (see
+// command.List). The args are any arguments given after the subcommand's name (none are expected).
+func runList(opts options.Options, args []string, scaffFileNameAndExt, workingDir string) {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "the 'list' subcommand doesn't take any arguments")
+		os.Exit(1)
+	}
+
+	listings, err := command.List(scaffFileNameAndExt, workingDir)
+	if err != nil {
+		exitOnScaffFileError(err)
+	}
+
+	if opts.JSON {
+		printJSON(listings)
+		return
+	}
+
+	if len(listings) == 0 {
+		fmt.Println("no commands were found (in a " + scaffFileNameAndExt + " file in the current directory, or any directory above it)")
+		return
+	}
+
+	fmt.Print(command.FormatList(listings, workingDir))
+}
+
+// runWhich prints a trace of how the command name (the only expected argument) is resolved to a command (see
+// command.Explain). If the command isn't found, the exit code is 4.
+func runWhich(opts options.Options, args []string, scaffFileNameAndExt, workingDir string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "please provide the name of the command to explain (e.g. 'scaff which my_command')")
+		os.Exit(1)
+	}
+
+	resolution, err := command.Explain(args[0], scaffFileNameAndExt, workingDir)
+	if opts.JSON {
+		printJSON(resolution)
+	} else {
+		fmt.Print(command.FormatResolution(resolution))
+	}
+
+	if err != nil {
+		exitOnScaffFileError(err)
+	}
+
+	if len(resolution.Definitions) == 0 {
+		os.Exit(4)
+	}
+}
+
+// runDescribe prints what the command (the only expected argument) needs, and what it creates (see command.Describe)
+func runDescribe(opts options.Options, args []string, scaffFileNameAndExt, workingDir string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "please provide the name of the command to describe (e.g. 'scaff describe my_command')")
+		os.Exit(1)
+	}
+
+	commandToDescribe, fullTemplatePath, isFound, err := command.Find(args[0], scaffFileNameAndExt, workingDir)
+	if err != nil {
+		exitOnScaffFileError(err)
+	}
+	if !isFound {
+		fmt.Fprintln(os.Stderr, "unable to find the requested command ('"+args[0]+"')")
+		os.Exit(4)
+	}
+
+	description, err := command.Describe(commandToDescribe, fullTemplatePath)
+	if opts.JSON {
+		printJSON(description)
+	} else {
+		fmt.Print(command.FormatDescription(description))
+	}
+
+	if err != nil {
+		exitOnTemplateError(err)
+	}
+}
+
+// runValidate validates a scaff file, along with all of its child files (see command.ValidateAll). The only (optional)
+// argument is the path to the scaff file, or to a directory that contains one (the working directory by default). If any
+// problems are found, the exit code is 5.
+func runValidate(opts options.Options, args []string, scaffFileNameAndExt, workingDir string) {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "the 'validate' subcommand only takes the path to a scaff file (e.g. 'scaff validate path/to/scaff.json')")
+		os.Exit(1)
+	}
+
+	scaffFilePath := workingDir
+	if len(args) == 1 {
+		scaffFilePath = args[0]
+		if !filepath.IsAbs(scaffFilePath) {
+			scaffFilePath = filepath.Join(workingDir, scaffFilePath)
+		}
+	}
+
+	if info, statErr := os.Stat(scaffFilePath); statErr == nil && info.IsDir() {
+		scaffFilePath = filepath.Join(scaffFilePath, scaffFileNameAndExt)
+	}
+
+	report := command.ValidateAll(scaffFilePath)
+	if opts.JSON {
+		printJSON(report)
+	} else {
+		fmt.Print(command.FormatValidationReport(report, workingDir))
+	}
+
+	if len(report.Problems) > 0 {
+		os.Exit(5)
+	}
+}
+
+// exitOnScaffFileError outputs an error from reading the scaff files, and exits with the matching exit code
+func exitOnScaffFileError(err error) {
+	var validationErr *customerrors.ValidationError
+	if errors.As(err, &validationErr) {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(3)
+	}
+
+	fmt.Fprintln(os.Stderr, "error while reading scaff files:", err.Error())
+	os.Exit(7)
+}
+
+// printJSON prints the given value as indented JSON
+func printJSON(value any) {
+	jsonBytes, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error while outputting JSON:", err.Error())
+		os.Exit(7)
+	}
+
+	fmt.Println(string(jsonBytes))
+}
@@ -0,0 +1,275 @@
+package variable
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PartialLoader finds and reads the partial at the given path (as it is written in an include tag).
+// Returns the contents of the partial, and its full path (which identifies the partial in errors, and when checking for
+// include cycles).
+type PartialLoader func(partialPath string) (contents string, fullPath string, err error)
+
+// ifNode is a conditional block. The body of the first branch whose condition is true is rendered (or the else body, if
+// none of them are true).
+type ifNode struct {
+	branches []ifBranch
+	elseBody []node
+}
+
+// ifBranch is an "if" (or "else if") within a conditional block
+type ifBranch struct {
+	condition condition
+	body      []node
+}
+
+func (n *ifNode) render(context *renderContext) error {
+	for _, branch := range n.branches {
+		isTrue, err := branch.condition.evaluate(context.vars, context.noInput)
+		if err != nil {
+			return err
+		}
+
+		if isTrue {
+			return renderNodes(branch.body, context)
+		}
+	}
+
+	return renderNodes(n.elseBody, context)
+}
+
+// rangeNode is a loop block. The body is rendered once for each item in the list variable.
+type rangeNode struct {
+	itemName string // The name the current item is given within the body
+	listName string
+	body     []node
+}
+
+// loopNames returns the names of the variables that are available within the body of the loop: the item itself, its
+// (zero-based) index, and whether it is the first/last item
+func (n *rangeNode) loopNames() []string {
+	return LoopNames(n.itemName)
+}
+
+func (n *rangeNode) render(context *renderContext) error {
+	itemLoopVars, err := LoopVars(n.listName, n.itemName, context.vars, context.noInput)
+	if err != nil {
+		return err
+	}
+
+	for _, loopVars := range itemLoopVars {
+		err := WithLoopVars(context.vars, loopVars, func() error {
+			return renderNodes(n.body, context)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// includeNode is an include tag, which is replaced with the rendered contents of a partial
+type includeNode struct {
+	partialPath  string
+	includeTag   string // The tag as it is written in the template
+	templateName string
+	pos          position
+	lineEnding   string     // If the tag is on a line by itself, this is the line ending that was removed along with the line
+	delimiters   Delimiters // The delimiters of the including template (which are also used for the partial)
+}
+
+func (n *includeNode) render(context *renderContext) error {
+	partial, err := loadPartial(n, context.loadPartial, context.partials, context.includeStack)
+	if err != nil {
+		return err
+	}
+
+	context.includeStack = append(context.includeStack, partial.fullPath)
+	defer func() { context.includeStack = context.includeStack[:len(context.includeStack)-1] }()
+
+	writtenBefore := context.writer.written
+	if err := renderNodes(partial.template.nodes, context); err != nil {
+		return err
+	}
+
+	// A standalone include is replaced by the lines of the partial, so the line ending is only removed if the partial has
+	// its own
+	if context.writer.written > writtenBefore && context.writer.lastByte != '\n' {
+		_, err = io.WriteString(context.writer, n.lineEnding)
+	}
+
+	return err
+}
+
+// tagCollector collects the variable tags used within a template (see Template.Tags)
+type tagCollector struct {
+	tags        []Tag
+	loadPartial PartialLoader // If nil, includes are ignored
+	partials    map[string]*loadedPartial
+}
+
+// collect adds the variable tags used within the given nodes (in the order they are used).
+// Any tags for the hidden names (the variables defined by an enclosing range block) are ignored.
+func (c *tagCollector) collect(nodes []node, hiddenNames []string, includeStack []string) error {
+	for _, currentNode := range nodes {
+		switch typedNode := currentNode.(type) {
+		case *variableNode:
+			if !slices.Contains(hiddenNames, typedNode.tag.Name) {
+				c.tags = append(c.tags, typedNode.tag)
+			}
+
+		case *ifNode:
+			for _, branch := range typedNode.branches {
+				for _, name := range branch.condition.names() {
+					if !slices.Contains(hiddenNames, name) {
+						c.tags = append(c.tags, Tag{Name: name})
+					}
+				}
+
+				if err := c.collect(branch.body, hiddenNames, includeStack); err != nil {
+					return err
+				}
+			}
+
+			if err := c.collect(typedNode.elseBody, hiddenNames, includeStack); err != nil {
+				return err
+			}
+
+		case *rangeNode:
+			if !slices.Contains(hiddenNames, typedNode.listName) {
+				c.tags = append(c.tags, Tag{Name: typedNode.listName, IsList: true})
+			}
+
+			bodyHiddenNames := append(slices.Clone(hiddenNames), typedNode.loopNames()...)
+			if err := c.collect(typedNode.body, bodyHiddenNames, includeStack); err != nil {
+				return err
+			}
+
+		case *includeNode:
+			if c.loadPartial == nil {
+				continue
+			}
+
+			partial, err := loadPartial(typedNode, c.loadPartial, c.partials, includeStack)
+			if err != nil {
+				return err
+			}
+
+			partialIncludeStack := append(slices.Clone(includeStack), partial.fullPath)
+			if err := c.collect(partial.template.nodes, hiddenNames, partialIncludeStack); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// condition is the test in an "if" tag. It is either a single operand (which is true if its value is truthy), or a
+// comparison between two operands (using "==" or "!="). It can be negated with "not" (or "!").
+type condition struct {
+	isNegated bool
+	left      operand
+	operator  string // "==", "!=", or empty (if there is only a left operand)
+	right     operand
+}
+
+// operand is a value within a condition (either a variable, or a literal value)
+type operand struct {
+	name      string // The variable name (if this isn't a literal)
+	literal   string
+	isLiteral bool
+}
+
+// parseOperand parses a token in a condition (either a quoted string, "true"/"false", a number, or a variable name)
+func parseOperand(operandToken token) (operand, error) {
+	if operandToken.typ == tokenString {
+		return operand{literal: operandToken.value, isLiteral: true}, nil
+	}
+
+	word := operandToken.value
+	if operandToken.typ != tokenWord {
+		return operand{}, fmt.Errorf("'%s' is not a valid variable name or value", word)
+	}
+
+	if _, err := strconv.ParseFloat(word, 64); err == nil || word == "true" || word == "false" {
+		return operand{literal: word, isLiteral: true}, nil
+	}
+
+	if !namePattern.MatchString(word) {
+		return operand{}, fmt.Errorf("'%s' is not a valid variable name or value", word)
+	}
+
+	return operand{name: word}, nil
+}
+
+// value returns the operand's value (prompting for the variable, if it isn't in the map, unless noInput is true)
+func (o *operand) value(vars map[string]string, noInput bool) (string, error) {
+	if o.isLiteral {
+		return o.literal, nil
+	}
+
+	return lookup(o.name, vars, noInput)
+}
+
+// evaluate identifies if the condition is true (using the values in the given map). If noInput is true, a
+// MissingVariablesError is returned for any missing variables (rather than prompting for them).
+func (c *condition) evaluate(vars map[string]string, noInput bool) (bool, error) {
+	leftValue, err := c.left.value(vars, noInput)
+	if err != nil {
+		return false, err
+	}
+
+	var result bool
+	if c.operator == "" {
+		result = isTruthy(leftValue)
+	} else {
+		rightValue, err := c.right.value(vars, noInput)
+		if err != nil {
+			return false, err
+		}
+
+		result = valuesAreEqual(leftValue, rightValue) == (c.operator == "==")
+	}
+
+	return result != c.isNegated, nil
+}
+
+// names returns the names of the variables used in the condition
+func (c *condition) names() []string {
+	names := []string{}
+	for _, conditionOperand := range []operand{c.left, c.right} {
+		if !conditionOperand.isLiteral && conditionOperand.name != "" {
+			names = append(names, conditionOperand.name)
+		}
+	}
+
+	return names
+}
+
+// isTruthy identifies if a value counts as true in a condition. Empty values, and values that strconv.ParseBool treats as
+// false (e.g. "false" and "0"), are false. Anything else is true.
+func isTruthy(value string) bool {
+	value = strings.TrimSpace(value)
+	if boolValue, err := strconv.ParseBool(value); err == nil {
+		return boolValue
+	}
+
+	return value != ""
+}
+
+// valuesAreEqual compares two values in a condition. Values are equal if they are the same, or if both are booleans with the
+// same value (e.g. "True" and "true").
+func valuesAreEqual(left, right string) bool {
+	if left == right {
+		return true
+	}
+
+	leftBool, leftErr := strconv.ParseBool(left)
+	rightBool, rightErr := strconv.ParseBool(right)
+	return leftErr == nil && rightErr == nil && leftBool == rightBool
+}
@@ -0,0 +1,338 @@
+package variable_test
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+func TestPopulateWillRenderTheBranchOfAnIfBlockThatMatchesTheCondition(t *testing.T) {
+	text := `before {: if withDocker :}docker{: else if name == "api" :}api{: else :}neither{: end :} after`
+
+	cases := []struct {
+		vars     map[string]string
+		expected string
+	}{
+		{map[string]string{"withDocker": "true", "name": "api"}, "before docker after"},
+		{map[string]string{"withDocker": "false", "name": "api"}, "before api after"},
+		{map[string]string{"withDocker": "", "name": "web"}, "before neither after"},
+	}
+
+	for _, testCase := range cases {
+		result, err := variable.Populate(text, testCase.vars)
+		if err != nil {
+			t.Errorf("expected no error. Got %v", err)
+		}
+
+		if result != testCase.expected {
+			t.Errorf("expected result to be '%s'. Got '%s'", testCase.expected, result)
+		}
+	}
+}
+
+func TestPopulateWillEvaluateNegatedAndNotEqualConditions(t *testing.T) {
+	vars := map[string]string{"flag": "false", "kind": "service"}
+
+	cases := map[string]string{
+		`{: if not flag :}yes{: end :}`:              "yes",
+		`{: if !flag :}yes{: end :}`:                 "yes",
+		`{: if kind != "service" :}yes{: end :}`:     "",
+		`{: if kind!="model" :}yes{: end :}`:         "yes",
+		`{: if not kind == "service" :}yes{: end :}`: "",
+		`{: if flag == false :}yes{: end :}`:         "yes",
+	}
+
+	for text, expected := range cases {
+		result, err := variable.Populate(text, vars)
+		if err != nil {
+			t.Errorf("expected no error for '%s'. Got %v", text, err)
+		}
+
+		if result != expected {
+			t.Errorf("expected result of '%s' to be '%s'. Got '%s'", text, expected, result)
+		}
+	}
+}
+
+func TestPopulateWillRenderNestedIfBlocksAndPopulateTagsWithinThem(t *testing.T) {
+	text := `{: if outer :}[{: if inner :}{: name | upper :}{: else :}no inner{: end :}]{: end :}`
+	vars := map[string]string{"outer": "true", "inner": "yes", "name": "bob"}
+
+	result, err := variable.Populate(text, vars)
+	if err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	if result != "[BOB]" {
+		t.Errorf("expected result to be '[BOB]'. Got '%s'", result)
+	}
+}
+
+func TestPopulateWillRemoveLinesThatOnlyContainControlTags(t *testing.T) {
+	text := "start\n  {: if flag :}\nflagged\n  {: end :}\nend"
+	vars := map[string]string{"flag": "true"}
+
+	result, err := variable.Populate(text, vars)
+	if err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	if result != "start\nflagged\nend" {
+		t.Errorf("expected result to be 'start\\nflagged\\nend'. Got '%s'", result)
+	}
+}
+
+func TestPopulateWillNotPromptForTagsInBranchesThatAreNotChosen(t *testing.T) {
+	vars := map[string]string{"flag": "false"}
+
+	// There is no mock stdin, so a prompt would fail
+	result, err := variable.Populate(`{: if flag :}{: missing :}{: end :}done`, vars)
+	if err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	if result != "done" {
+		t.Errorf("expected result to be 'done'. Got '%s'", result)
+	}
+}
+
+func TestPopulateWillNotTreatEscapedControlTagsAsBlocks(t *testing.T) {
+	text := `{\: if flag :}text{\: end :}`
+	expected := `{: if flag :}text{: end :}`
+
+	result, err := variable.Populate(text, map[string]string{})
+	if err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("expected result to be '%s'. Got '%s'", expected, result)
+	}
+}
+
+func TestPopulateWillReturnValidationErrorsForInvalidBlocks(t *testing.T) {
+	cases := map[string]string{
+		"text {: end :}":                          "there is no block for it to end",
+		"{: else :}":                              "there is no 'if' block for it to be part of",
+		"line1\n{: if flag :}text":                "2:1: the 'if' block is missing its '{: end :}' tag",
+		"{: if a :}{: else :}{: else :}{: end :}": "already has an 'else'",
+		`{: if a < "b" :}{: end :}`:               "1:9: unexpected character '<' in the tag",
+		`{: if a b c :}{: end :}`:                 "1:9: invalid tag '{: if a b c :}': unknown operator 'b'",
+		`{: if a b c d :}{: end :}`:               "expected a variable, or a comparison",
+		`{: if a == b.c :}{: end :}`:              "'b.c' is not a valid variable name or value",
+	}
+
+	for text, expectedMessage := range cases {
+		_, err := variable.Populate(text, map[string]string{"a": "b", "flag": "true"})
+
+		var validationError *customerrors.ValidationError
+		if !errors.As(err, &validationError) {
+			t.Errorf("expected a ValidationError for '%s'. Got %v", text, err)
+			continue
+		}
+
+		if !strings.Contains(err.Error(), expectedMessage) {
+			t.Errorf("expected the error for '%s' to contain '%s'. Got '%s'", text, expectedMessage, err.Error())
+		}
+	}
+}
+
+func TestNamesWillIncludeConditionVariablesAndExcludeControlKeywords(t *testing.T) {
+	text := `{: a :}{: if b == c :}{: else if not d :}{: else :}{: e :}{: end :}{: if "x" == f :}{: end :}{\: if g :}`
+	expectedNames := []string{"a", "b", "c", "d", "e", "f"}
+
+	result := variable.Names(text)
+
+	if !slices.Equal(result, expectedNames) {
+		t.Errorf("expected names to be %v. Got %v", expectedNames, result)
+	}
+}
+
+func TestPopulateWillRenderRangeBlocksOnceForEachItemInTheList(t *testing.T) {
+	text := "type User struct {\n" +
+		"{: range field in fields :}\n" +
+		"\t{: field | pascal :} string // {: field_index :}{: if field_first :} (first){: end :}{: if field_last :} (last){: end :}\n" +
+		"{: end :}\n" +
+		"}"
+	vars := map[string]string{"fields": "id, user_name,email"}
+
+	expected := "type User struct {\n" +
+		"\tId string // 0 (first)\n" +
+		"\tUserName string // 1\n" +
+		"\tEmail string // 2 (last)\n" +
+		"}"
+
+	result, err := variable.Populate(text, vars)
+	if err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	if result != expected {
+		t.Errorf("expected result to be '%s'. Got '%s'", expected, result)
+	}
+}
+
+func TestPopulateWillRenderNestedRangeBlocksAndRestoreHiddenVariables(t *testing.T) {
+	text := `{: range a in outer :}{: range b in inner :}{: a :}{: b :}{: if not b_last :},{: end :}{: end :};{: end :}{: a :}`
+	vars := map[string]string{"outer": `["x,1", "y"]`, "inner": "1,2", "a": "original"}
+
+	result, err := variable.Populate(text, vars)
+	if err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	expected := "x,11,x,12;y1,y2;original"
+	if result != expected {
+		t.Errorf("expected result to be '%s'. Got '%s'", expected, result)
+	}
+
+	if _, exists := vars["b"]; exists {
+		t.Errorf("expected the loop variable 'b' to be removed once the loop was finished")
+	}
+}
+
+func TestPopulateWillRenderNothingForRangeBlocksOverEmptyLists(t *testing.T) {
+	result, err := variable.Populate(`[{: range item in items :}{: item :}{: end :}]`, map[string]string{"items": ""})
+	if err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	if result != "[]" {
+		t.Errorf("expected result to be '[]'. Got '%s'", result)
+	}
+}
+
+func TestPopulateWillReturnValidationErrorsForInvalidRangeBlocks(t *testing.T) {
+	cases := map[string]string{
+		"{: range item in items :}":                    "1:1: the 'range' block is missing its '{: end :}' tag",
+		"{: range item in items :}{: else :}{: end :}": "there is no 'if' block for it to be part of",
+		"{: range end in items :}{: end :}{: end :}":   "'end' is a reserved word",
+	}
+
+	for text, expectedMessage := range cases {
+		_, err := variable.Populate(text, map[string]string{"items": "a"})
+
+		var validationError *customerrors.ValidationError
+		if !errors.As(err, &validationError) {
+			t.Errorf("expected a ValidationError for '%s'. Got %v", text, err)
+			continue
+		}
+
+		if !strings.Contains(err.Error(), expectedMessage) {
+			t.Errorf("expected the error for '%s' to contain '%s'. Got '%s'", text, expectedMessage, err.Error())
+		}
+	}
+}
+
+func TestTagsWillIncludeRangeListsAndExcludeLoopVariables(t *testing.T) {
+	text := `{: range field in fields :}{: field :}{: field_index :}{: if field_last :}{: end :}{: other :}{: end :}{: field :}`
+
+	result := variable.Tags(text)
+
+	expectedNames := []string{"fields", "other", "field"}
+	if len(result) != len(expectedNames) {
+		t.Fatalf("expected tags for %v. Got %v", expectedNames, result)
+	}
+
+	for i, tag := range result {
+		if tag.Name != expectedNames[i] || tag.IsList != (i == 0) {
+			t.Errorf("expected tag %d to be '%s' (with IsList %t). Got %+v", i, expectedNames[i], i == 0, tag)
+		}
+	}
+}
+
+// mockPartialLoader returns a PartialLoader that loads the partials from the given map (keyed by path)
+func mockPartialLoader(partials map[string]string) variable.PartialLoader {
+	return func(partialPath string) (string, string, error) {
+		contents, exists := partials[partialPath]
+		if !exists {
+			return "", "", fmt.Errorf("no partial at '%s'", partialPath)
+		}
+
+		return contents, "/partials/" + partialPath, nil
+	}
+}
+
+func TestPopulateWithPartialsWillRenderIncludedPartialsWithTheCurrentVariables(t *testing.T) {
+	loadPartial := mockPartialLoader(map[string]string{
+		"header.txt":        "// Copyright {: owner :}\n{: include \"nested/notice.txt\" :}\n",
+		"nested/notice.txt": "// Generated for {: item | upper :}",
+	})
+
+	text := "{: range item in items :}\n{: include \"header.txt\" :}\npackage {: item :}\n{: end :}"
+	vars := map[string]string{"owner": "Me", "items": "a,b"}
+
+	result, err := variable.PopulateWithPartials(text, vars, loadPartial)
+	if err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	expected := "// Copyright Me\n// Generated for A\npackage a\n// Copyright Me\n// Generated for B\npackage b\n"
+	if result != expected {
+		t.Errorf("expected result to be '%s'. Got '%s'", expected, result)
+	}
+}
+
+func TestPopulateWithPartialsWillReturnValidationErrorForIncludeCycles(t *testing.T) {
+	loadPartial := mockPartialLoader(map[string]string{
+		"a.txt": `{: include "b.txt" :}`,
+		"b.txt": `{: include "a.txt" :}`,
+	})
+
+	_, err := variable.PopulateWithPartials(`{: include "a.txt" :}`, map[string]string{}, loadPartial)
+
+	var validationError *customerrors.ValidationError
+	if !errors.As(err, &validationError) {
+		t.Fatalf("expected a ValidationError. Got %v", err)
+	}
+
+	expectedMessage := "/partials/b.txt:1:1: the partial '/partials/a.txt' includes itself (/partials/a.txt -> /partials/b.txt -> /partials/a.txt)"
+	if err.Error() != expectedMessage {
+		t.Errorf("expected the error to be '%s'. Got '%s'", expectedMessage, err.Error())
+	}
+}
+
+func TestPopulateWithPartialsWillAllowTheSamePartialToBeIncludedMoreThanOnce(t *testing.T) {
+	loadPartial := mockPartialLoader(map[string]string{"line.txt": "-"})
+
+	result, err := variable.PopulateWithPartials(`{: include "line.txt" :}{: include "line.txt" :}`, map[string]string{}, loadPartial)
+	if err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	if result != "--" {
+		t.Errorf("expected result to be '--'. Got '%s'", result)
+	}
+}
+
+func TestPopulateWillReturnValidationErrorForIncludeTags(t *testing.T) {
+	_, err := variable.Populate(`{: include "header.txt" :}`, map[string]string{})
+
+	var validationError *customerrors.ValidationError
+	if !errors.As(err, &validationError) {
+		t.Errorf("expected a ValidationError. Got %v", err)
+	}
+}
+
+func TestTemplateTagsWillIncludeTagsFromIncludedPartials(t *testing.T) {
+	loadPartial := mockPartialLoader(map[string]string{"partial.txt": `{: b :}{: include "missing.txt" :}`})
+
+	template, err := variable.Compile("template.txt", `{: a :}{: include "partial.txt" :}`)
+	if err != nil {
+		t.Fatalf("expected no error compiling the template. Got %v", err)
+	}
+
+	tags, err := template.Tags(loadPartial)
+	if err == nil || !strings.Contains(err.Error(), "no partial at 'missing.txt'") {
+		t.Errorf("expected the error from loading the missing partial. Got %v", err)
+	}
+
+	if len(tags) != 2 || tags[0].Name != "a" || tags[1].Name != "b" {
+		t.Errorf("expected the tags 'a' and 'b'. Got %v", tags)
+	}
+}
@@ -0,0 +1,44 @@
+package variable
+
+import (
+	"fmt"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+)
+
+// Condition is a compiled standalone condition (e.g. the "when" property of a file), which uses the same syntax as the
+// condition in an "if" tag (e.g. `withDocker`, `not withDocker` or `kind == 'api'`)
+type Condition struct {
+	condition condition
+}
+
+// CompileCondition compiles the given condition. Errors are ValidationErrors.
+func CompileCondition(text string) (*Condition, error) {
+	tokens, err := lexExpression("", text)
+	if err != nil {
+		return nil, &customerrors.ValidationError{Message: fmt.Sprintf("invalid condition '%s': %v", text, err)}
+	}
+
+	parsed, errPos, err := parseConditionTokens(tokens)
+	if err != nil {
+		if errPos != nil {
+			err = fmt.Errorf("%d:%d: %w", errPos.line, errPos.column, err)
+		}
+
+		return nil, &customerrors.ValidationError{Message: fmt.Sprintf("invalid condition '%s': %v", text, err)}
+	}
+
+	return &Condition{condition: parsed}, nil
+}
+
+// Evaluate identifies if the condition is true, using the values in the given map (if a variable doesn't exist in the map,
+// the user is prompted to provide it, and it is then added to the map). If noInput is true, a MissingVariablesError is
+// returned instead of prompting.
+func (c *Condition) Evaluate(vars map[string]string, noInput bool) (bool, error) {
+	return c.condition.evaluate(vars, noInput)
+}
+
+// Names returns the names of the variables used in the condition
+func (c *Condition) Names() []string {
+	return c.condition.names()
+}
@@ -0,0 +1,82 @@
+package variable_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+func TestConditionEvaluateWillEvaluateTheCondition(t *testing.T) {
+	vars := map[string]string{"withDocker": "true", "kind": "api", "name": "it's"}
+
+	cases := map[string]bool{
+		"withDocker":              true,
+		"not withDocker":          false,
+		"withDocker == 'true'":    true,
+		"withDocker == \"false\"": false,
+		"kind != 'api'":           false,
+		"!(kind)":                 false,
+		"name == 'it\\'s'":        true,
+		"  kind\n== 'api'  ":      true,
+	}
+
+	for text, expected := range cases {
+		condition, err := variable.CompileCondition(text)
+		if text == "!(kind)" {
+			if err == nil {
+				t.Errorf("expected an error for '%s'", text)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("expected no error for '%s'. Got %v", text, err)
+			continue
+		}
+
+		result, err := condition.Evaluate(vars, false)
+		if err != nil || result != expected {
+			t.Errorf("expected '%s' to be %t. Got %t (error: %v)", text, expected, result, err)
+		}
+	}
+}
+
+func TestCompileConditionWillReturnValidationErrorsForInvalidConditions(t *testing.T) {
+	cases := map[string]string{
+		"":             `invalid condition '': expected a variable, or a comparison (e.g. 'name == "value"')`,
+		"a < 'b'":      "invalid condition 'a < 'b'': 1:3: unexpected character '<' in the condition",
+		"a is 'b'":     "invalid condition 'a is 'b'': 1:3: unknown operator 'is' (expected '==' or '!=')",
+		"a == 'b":      "invalid condition 'a == 'b': 1:6: a quoted value is missing its closing quote",
+		"flag | upper": "invalid condition 'flag | upper': 1:6: unknown operator '|' (expected '==' or '!=')",
+	}
+
+	for text, expectedMessage := range cases {
+		_, err := variable.CompileCondition(text)
+
+		var validationErr *customerrors.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("expected a ValidationError for '%s'. Got %v", text, err)
+			continue
+		}
+
+		if validationErr.Message != expectedMessage {
+			t.Errorf("expected the error for '%s' to be '%s'. Got '%s'", text, expectedMessage, validationErr.Message)
+		}
+	}
+}
+
+func TestConditionNamesWillReturnTheVariablesInTheCondition(t *testing.T) {
+	condition, err := variable.CompileCondition("not kind == otherKind")
+	if err != nil {
+		t.Fatalf("expected no error. Got %v", err)
+	}
+
+	if names := condition.Names(); !slices.Equal(names, []string{"kind", "otherKind"}) {
+		t.Errorf("expected the names 'kind' and 'otherKind'. Got %s", strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,160 @@
+package variable
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+)
+
+// Type is the type of value that a declared variable holds
+type Type string
+
+const (
+	TypeString Type = "string" // Any text (the default)
+	TypeInt    Type = "int"    // A whole number
+	TypeBool   Type = "bool"   // "true" or "false" (other values accepted by strconv.ParseBool are converted to these)
+	TypeEnum   Type = "enum"   // One of the values in the definition's Options
+	TypeList   Type = "list"   // A comma-separated list of values (see ParseList)
+)
+
+// Types holds all of the valid variable types
+var Types = []Type{TypeString, TypeInt, TypeBool, TypeEnum, TypeList}
+
+// namePattern matches the names that can be used in variable tags
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9-_]+$`)
+
+// Definition declares a variable that is used by a command (including how it should be prompted for, and what values are valid)
+type Definition struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"` // Shown to the user when they are prompted for the value
+	Default     string   `json:"default"`     // Used if the user doesn't enter a value when prompted
+	Type        Type     `json:"type"`        // Defaults to TypeString
+	Options     []string `json:"options"`     // The valid values, if the Type is TypeEnum
+	Pattern     string   `json:"pattern"`     // A regular expression that the whole value must match (each item, for a list)
+	Required    bool     `json:"required"`    // If true, the value can't be empty
+}
+
+// GetType returns the Type of the variable (TypeString, if no type has been set)
+func (d *Definition) GetType() Type {
+	if d.Type == "" {
+		return TypeString
+	}
+
+	return d.Type
+}
+
+// IsValidName identifies if the given name can be used as a variable name (it can only contain letters, numbers, '-' or
+// '_', and can't be a reserved word)
+func IsValidName(name string) bool {
+	return namePattern.MatchString(name) && !slices.Contains(reservedNames, name)
+}
+
+// Validate validates the properties in the Definition, and returns any validation errors
+func (d *Definition) Validate() []customerrors.ValidationError {
+	errs := []customerrors.ValidationError{}
+
+	if !namePattern.MatchString(d.Name) {
+		errs = append(errs, customerrors.ValidationError{
+			Message:  fmt.Sprintf("variable objects should have a 'name' property that only contains letters, numbers, '-' or '_' (got '%s')", d.Name),
+			Location: "name",
+		})
+	}
+
+	if slices.Contains(reservedNames, d.Name) {
+		errs = append(errs, customerrors.ValidationError{
+			Message:  fmt.Sprintf("'%s' is a reserved word, so can't be used as a variable name", d.Name),
+			Location: "name",
+		})
+	}
+
+	if !slices.Contains(Types, d.GetType()) {
+		errs = append(errs, customerrors.ValidationError{
+			Message:  fmt.Sprintf("the variable '%s' has an invalid 'type' ('%s'). Expected one of: %v", d.Name, d.Type, Types),
+			Location: "type",
+		})
+	}
+
+	if d.GetType() == TypeEnum && len(d.Options) == 0 {
+		errs = append(errs, customerrors.ValidationError{
+			Message:  fmt.Sprintf("the variable '%s' is an enum, so should have an 'options' property that contains at least one value", d.Name),
+			Location: "options",
+		})
+	}
+
+	patternIsValid := true
+	if _, patternErr := regexp.Compile(d.Pattern); patternErr != nil {
+		errs = append(errs, customerrors.ValidationError{
+			Message:  fmt.Sprintf("the variable '%s' has an invalid 'pattern': %v", d.Name, patternErr),
+			Location: "pattern",
+		})
+
+		patternIsValid = false
+	}
+
+	if d.Default != "" && patternIsValid && len(errs) == 0 {
+		if _, defaultErr := d.Check(d.Default); defaultErr != nil {
+			errs = append(errs, customerrors.ValidationError{
+				Message:  fmt.Sprintf("the variable '%s' has an invalid 'default': %v", d.Name, defaultErr),
+				Location: "default",
+			})
+		}
+	}
+
+	return errs
+}
+
+// Check confirms that the given value is valid for the variable.
+// Returns the value to use (which may have been normalised, e.g. a bool of "yes" becomes "true"), or an error explaining why
+// the value is invalid. Empty values are only invalid if the variable is required.
+func (d *Definition) Check(value string) (string, error) {
+	if strings.TrimSpace(value) == "" {
+		if d.Required {
+			return "", fmt.Errorf("a value is required")
+		}
+
+		return value, nil
+	}
+
+	switch d.GetType() {
+	case TypeInt:
+		value = strings.TrimSpace(value)
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", fmt.Errorf("'%s' is not a whole number", value)
+		}
+	case TypeBool:
+		boolValue, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("'%s' is not 'true' or 'false'", value)
+		}
+
+		value = strconv.FormatBool(boolValue)
+	case TypeEnum:
+		if !slices.Contains(d.Options, value) {
+			return "", fmt.Errorf("'%s' is not one of: %s", value, strings.Join(d.Options, ", "))
+		}
+	}
+
+	if d.Pattern != "" {
+		pattern, patternErr := regexp.Compile("^(?:" + d.Pattern + ")$")
+		if patternErr != nil {
+			return "", patternErr
+		}
+
+		valuesToMatch := []string{value}
+		if d.GetType() == TypeList {
+			valuesToMatch = ParseList(value)
+		}
+
+		for _, valueToMatch := range valuesToMatch {
+			if !pattern.MatchString(valueToMatch) {
+				return "", fmt.Errorf("'%s' does not match the pattern '%s'", valueToMatch, d.Pattern)
+			}
+		}
+	}
+
+	return value, nil
+}
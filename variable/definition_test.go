@@ -0,0 +1,90 @@
+package variable_test
+
+import (
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+func TestDefinitionCheckWillAcceptValidValues(t *testing.T) {
+	testCases := []struct {
+		definition    variable.Definition
+		value         string
+		expectedValue string
+	}{
+		{variable.Definition{Name: "a"}, "any text", "any text"},
+		{variable.Definition{Name: "a"}, "", ""},
+		{variable.Definition{Name: "a", Type: variable.TypeInt}, " 42 ", "42"},
+		{variable.Definition{Name: "a", Type: variable.TypeBool}, "TRUE", "true"},
+		{variable.Definition{Name: "a", Type: variable.TypeBool}, "0", "false"},
+		{variable.Definition{Name: "a", Type: variable.TypeEnum, Options: []string{"x", "y"}}, "y", "y"},
+		{variable.Definition{Name: "a", Pattern: "[a-z]+"}, "abc", "abc"},
+		{variable.Definition{Name: "a", Type: variable.TypeList, Pattern: "[a-z]+"}, "id,name, email", "id,name, email"},
+	}
+
+	for _, testCase := range testCases {
+		result, err := testCase.definition.Check(testCase.value)
+		if err != nil {
+			t.Errorf("expected no error for the value '%s'. Got '%s'", testCase.value, err.Error())
+			continue
+		}
+
+		if result != testCase.expectedValue {
+			t.Errorf("expected the value '%s' to be checked as '%s'. Got '%s'", testCase.value, testCase.expectedValue, result)
+		}
+	}
+}
+
+func TestDefinitionCheckWillReturnErrorForInvalidValues(t *testing.T) {
+	testCases := []struct {
+		definition variable.Definition
+		value      string
+	}{
+		{variable.Definition{Name: "a", Required: true}, "  "},
+		{variable.Definition{Name: "a", Type: variable.TypeInt}, "4.2"},
+		{variable.Definition{Name: "a", Type: variable.TypeBool}, "maybe"},
+		{variable.Definition{Name: "a", Type: variable.TypeEnum, Options: []string{"x", "y"}}, "z"},
+		{variable.Definition{Name: "a", Pattern: "[a-z]+"}, "abc1"},
+		{variable.Definition{Name: "a", Type: variable.TypeList, Pattern: "[a-z]+"}, "id,name2"},
+	}
+
+	for _, testCase := range testCases {
+		if _, err := testCase.definition.Check(testCase.value); err == nil {
+			t.Errorf("expected an error for the value '%s' (definition: %+v). Got nil", testCase.value, testCase.definition)
+		}
+	}
+}
+
+func TestDefinitionValidateWillReturnNoErrorsIfValid(t *testing.T) {
+	definition := variable.Definition{
+		Name:        "my_var-1",
+		Description: "My variable",
+		Default:     "b",
+		Type:        variable.TypeEnum,
+		Options:     []string{"a", "b"},
+		Pattern:     "[a-z]",
+		Required:    true,
+	}
+
+	if errs := definition.Validate(); len(errs) > 0 {
+		t.Errorf("expected no errors. Got %v", errs)
+	}
+}
+
+func TestDefinitionValidateWillReturnErrorsIfInvalid(t *testing.T) {
+	definitions := []variable.Definition{
+		{Name: ""},
+		{Name: "my var"},
+		{Name: "a", Type: "float"},
+		{Name: "a", Type: variable.TypeEnum},
+		{Name: "a", Pattern: "[a-z"},
+		{Name: "a", Type: variable.TypeInt, Default: "ten"},
+		{Name: "end"},
+	}
+
+	for _, definition := range definitions {
+		if errs := definition.Validate(); len(errs) != 1 {
+			t.Errorf("expected 1 error for the definition %+v. Got %d: %v", definition, len(errs), errs)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package variable
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+)
+
+// Delimiters are the opening and closing delimiters of tags
+type Delimiters struct {
+	Left  string
+	Right string
+}
+
+// DefaultDelimiters are the delimiters used by SCAFF's tags, if no others are given
+var DefaultDelimiters = Delimiters{Left: "{:", Right: ":}"}
+
+// ParseDelimiters creates Delimiters from the given values (as they are written in a scaff file, e.g. ["<%", "%>"]).
+// If there are no values, the zero Delimiters are returned (meaning the engine's own defaults should be used).
+// There must be 2 values (the opening and closing delimiters), neither of which can be empty or contain whitespace. The
+// opening delimiter must be at least 2 characters long, so it can be escaped (see Delimiters.EscapedLeft).
+// Errors are ValidationErrors.
+func ParseDelimiters(values []string) (Delimiters, error) {
+	if len(values) == 0 {
+		return Delimiters{}, nil
+	}
+
+	if len(values) != 2 {
+		return Delimiters{}, &customerrors.ValidationError{
+			Message: fmt.Sprintf("delimiters should be an array of 2 values (the opening and closing delimiters). Got %d values", len(values)),
+		}
+	}
+
+	for _, value := range values {
+		if value == "" || strings.IndexFunc(value, unicode.IsSpace) >= 0 {
+			return Delimiters{}, &customerrors.ValidationError{
+				Message: fmt.Sprintf("the delimiter '%s' is invalid (delimiters can't be empty, or contain whitespace)", value),
+			}
+		}
+	}
+
+	if utf8.RuneCountInString(values[0]) < 2 {
+		return Delimiters{}, &customerrors.ValidationError{
+			Message: fmt.Sprintf("the opening delimiter '%s' is invalid (it should be at least 2 characters long, so it can be escaped)", values[0]),
+		}
+	}
+
+	return Delimiters{Left: values[0], Right: values[1]}, nil
+}
+
+// EscapedLeft returns the escaped form of the opening delimiter, which is output as the opening delimiter (rather than
+// starting a tag). This is the opening delimiter with a backslash after its first character (e.g. "{\:" for "{:").
+func (d Delimiters) EscapedLeft() string {
+	_, firstLength := utf8.DecodeRuneInString(d.Left)
+	return d.Left[:firstLength] + "\\" + d.Left[firstLength:]
+}
+
+// isZero identifies if the delimiters haven't been set
+func (d Delimiters) isZero() bool {
+	return d.Left == "" && d.Right == ""
+}
+
+// tag formats the given contents as a tag (used to show example tags in errors, e.g. "{: end :}")
+func (d Delimiters) tag(contents string) string {
+	return d.Left + " " + contents + " " + d.Right
+}
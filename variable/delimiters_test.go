@@ -0,0 +1,92 @@
+package variable_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+func TestParseDelimitersWillReturnValidationErrorsForInvalidDelimiters(t *testing.T) {
+	cases := map[string][]string{
+		"an array of 2 values":       {"<%"},
+		"can't be empty":             {"<%", ""},
+		"or contain whitespace":      {"<% ", "%>"},
+		"at least 2 characters long": {"$", "$"},
+	}
+
+	for expectedMessage, values := range cases {
+		_, err := variable.ParseDelimiters(values)
+
+		var validationErr *customerrors.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("expected a ValidationError for %v. Got %v", values, err)
+			continue
+		}
+
+		if !strings.Contains(validationErr.Message, expectedMessage) {
+			t.Errorf("expected error for %v to contain '%s'. Got '%s'", values, expectedMessage, validationErr.Message)
+		}
+	}
+
+	delimiters, err := variable.ParseDelimiters([]string{"<%", "%>"})
+	if err != nil || delimiters.Left != "<%" || delimiters.Right != "%>" {
+		t.Errorf("expected the delimiters '<%%' and '%%>'. Got %v (error: %v)", delimiters, err)
+	}
+}
+
+func TestCompileWithDelimitersWillUseTheDelimitersForTagsAndEscapes(t *testing.T) {
+	text := "{: name :} {{ name }} <% name | upper %> <\\% name %> <% if flag %>yes<% end %>"
+
+	template, err := variable.CompileWithDelimiters("t.txt", text, variable.Delimiters{Left: "<%", Right: "%>"})
+	if err != nil {
+		t.Fatalf("expected no error. Got %v", err)
+	}
+
+	var builder strings.Builder
+	if err := template.Render(&builder, map[string]string{"name": "value", "flag": "true"}, nil); err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	expected := "{: name :} {{ name }} VALUE <% name %> yes"
+	if builder.String() != expected {
+		t.Errorf("expected '%s'. Got '%s'", expected, builder.String())
+	}
+}
+
+func TestCompileWithDelimitersWillUseTheDelimitersInErrors(t *testing.T) {
+	delimiters := variable.Delimiters{Left: "[[", Right: "]]"}
+
+	cases := map[string]string{
+		"a [[ name":        "t.txt:1:3: the tag is missing its closing ']]'",
+		"[[ if a ]]\ntext": "t.txt:1:1: the 'if' block is missing its '[[ end ]]' tag",
+	}
+
+	for text, expectedMessage := range cases {
+		_, err := variable.CompileWithDelimiters("t.txt", text, delimiters)
+		if err == nil || err.Error() != expectedMessage {
+			t.Errorf("expected the error '%s'. Got %v", expectedMessage, err)
+		}
+	}
+}
+
+func TestCompileWithDelimitersWillUseTheSameDelimitersForIncludedPartials(t *testing.T) {
+	loadPartial := mockPartialLoader(map[string]string{"header.txt": "<% name %> {: name :}"})
+
+	template, err := variable.CompileWithDelimiters("t.txt", `<% include "header.txt" %>!`, variable.Delimiters{Left: "<%", Right: "%>"})
+	if err != nil {
+		t.Fatalf("expected no error. Got %v", err)
+	}
+
+	var builder strings.Builder
+	if err := template.Render(&builder, map[string]string{"name": "value"}, loadPartial); err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	expected := "value {: name :}!"
+	if builder.String() != expected {
+		t.Errorf("expected '%s'. Got '%s'", expected, builder.String())
+	}
+}
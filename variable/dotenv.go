@@ -0,0 +1,89 @@
+package variable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseDotenvVars parses the variables from the contents of a dotenv file.
+// Each variable is on a line in the format "NAME=value" (optionally starting with "export "). Blank lines, and lines
+// starting with "#", are ignored.
+// Values can be wrapped in double quotes (in which case they can span multiple lines, and can contain the escape sequences
+// "\n", "\t", "\"" and "\\"), or single quotes (in which case they can span multiple lines, and are used as they are).
+// Unquoted values are trimmed, and anything after " #" is treated as a comment.
+func parseDotenvVars(text string) (map[string]string, error) {
+	vars := make(map[string]string)
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		name, value, hasEquals := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !hasEquals || name == "" {
+			return nil, fmt.Errorf("line %d should be in the format 'NAME=value'", lineNumber)
+		}
+
+		value = strings.TrimLeft(value, " \t")
+		if value == "" || (value[0] != '"' && value[0] != '\'') {
+			if commentIndex := strings.Index(value, " #"); commentIndex >= 0 {
+				value = value[:commentIndex]
+			}
+
+			vars[name] = strings.TrimSpace(value)
+			continue
+		}
+
+		// Quoted values may continue onto the following lines, until the closing quote is found
+		quote := value[0]
+		quotedText := value[1:]
+		for {
+			if closingIndex := findClosingQuote(quotedText, quote); closingIndex >= 0 {
+				quotedText = quotedText[:closingIndex]
+				break
+			}
+
+			i++
+			if i >= len(lines) {
+				return nil, fmt.Errorf("the value of '%s' (on line %d) is missing its closing quote", name, lineNumber)
+			}
+
+			quotedText += "\n" + lines[i]
+		}
+
+		if quote == '"' {
+			quotedText = unescapeDoubleQuoted(quotedText)
+		}
+
+		vars[name] = quotedText
+	}
+
+	return vars, nil
+}
+
+// findClosingQuote returns the index of the given (unescaped) quote character in the text, or -1 if it isn't found.
+// Backslashes only escape characters within double quotes.
+func findClosingQuote(text string, quote byte) int {
+	for i := 0; i < len(text); i++ {
+		if quote == '"' && text[i] == '\\' {
+			i++
+			continue
+		}
+
+		if text[i] == quote {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// unescapeDoubleQuoted replaces the escape sequences in a double-quoted value
+func unescapeDoubleQuoted(text string) string {
+	return strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(text)
+}
@@ -0,0 +1,86 @@
+package variable
+
+import (
+	"io"
+	"strings"
+)
+
+// The template engines that can be used to populate names and templates
+const (
+	EngineScaff      = "scaff"      // SCAFF's own tags (e.g. "{: name | upper :}"). This is the default.
+	EngineGoTemplate = "gotemplate" // Go's text/template (e.g. "{{ .name | upper }}")
+)
+
+// Engines holds the name of every template engine
+var Engines = []string{EngineScaff, EngineGoTemplate}
+
+// Options configures how names and templates are compiled
+type Options struct {
+	Engine     string     // The name of the template engine (an empty name is treated as EngineScaff)
+	Delimiters Delimiters // The delimiters of tags (if these haven't been set, the engine's own defaults are used)
+
+	// If true, the user is never prompted for variables that are missing when rendering (a MissingVariablesError is
+	// returned instead)
+	NoInput bool
+}
+
+// Renderer is a compiled template (from any of the engines), which can be rendered any number of times
+type Renderer interface {
+	// Render writes the populated template to the writer (see Template.Render)
+	Render(writer io.Writer, vars map[string]string, loadPartial PartialLoader) error
+
+	// Tags returns the variables that are used in the template, in the order they are used (see Template.Tags)
+	Tags(loadPartial PartialLoader) ([]Tag, error)
+}
+
+// CompileWithOptions compiles the given text with the engine (and delimiters) in the options. If the options have NoInput
+// set, the compiled template never prompts for missing variables.
+// The name identifies the template in any errors. Errors are ValidationErrors.
+func CompileWithOptions(options Options, name, text string) (Renderer, error) {
+	if options.Engine == EngineGoTemplate {
+		goTemplate, err := CompileGoTemplate(name, text, options.Delimiters)
+		if err != nil {
+			return nil, err
+		}
+
+		goTemplate.noInput = options.NoInput
+		return goTemplate, nil
+	}
+
+	template, err := CompileWithDelimiters(name, text, options.Delimiters)
+	if err != nil {
+		return nil, err
+	}
+
+	template.noInput = options.NoInput
+	return template, nil
+}
+
+// PopulateWithOptions populates the given text (e.g. a file/directory name) with the engine (and delimiters) in the options,
+// in the same way as Populate. Include tags can't be used.
+func PopulateWithOptions(options Options, text string, vars map[string]string) (string, error) {
+	renderer, err := CompileWithOptions(options, "", text)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	if err := renderer.Render(&builder, vars, nil); err != nil {
+		return "", err
+	}
+
+	return builder.String(), nil
+}
+
+// TagsWithOptions returns the variable tags within the given text (e.g. a file/directory name), when it is compiled with the
+// engine (and delimiters) in the options. If the text can't be compiled, no tags are returned (the error is reported when
+// populating).
+func TagsWithOptions(options Options, text string) []Tag {
+	renderer, err := CompileWithOptions(options, "", text)
+	if err != nil {
+		return []Tag{}
+	}
+
+	tags, _ := renderer.Tags(nil)
+	return tags
+}
@@ -0,0 +1,245 @@
+package variable
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// filterFunc transforms a variable's value (using the arguments given to the filter in the tag)
+type filterFunc func(value string, args []string) (string, error)
+
+// filters holds every filter that can be used in a tag, keyed by name
+var filters = map[string]filterFunc{
+	"upper":   noArgs(strings.ToUpper),
+	"lower":   noArgs(strings.ToLower),
+	"pascal":  noArgs(toPascalCase),
+	"camel":   noArgs(toCamelCase),
+	"snake":   noArgs(func(value string) string { return joinWords(value, "_") }),
+	"kebab":   noArgs(func(value string) string { return joinWords(value, "-") }),
+	"plural":  noArgs(toPlural),
+	"replace": replaceFilter,
+	"default": defaultFilter,
+}
+
+// noArgs creates a filterFunc from a function that doesn't take any arguments
+func noArgs(transform func(string) string) filterFunc {
+	return func(value string, args []string) (string, error) {
+		if len(args) > 0 {
+			return "", fmt.Errorf("no arguments were expected (got %d)", len(args))
+		}
+
+		return transform(value), nil
+	}
+}
+
+// replaceFilter replaces every instance of the first argument with the second argument
+func replaceFilter(value string, args []string) (string, error) {
+	if len(args) != 2 {
+		return "", fmt.Errorf("2 arguments were expected (got %d)", len(args))
+	}
+
+	return strings.ReplaceAll(value, args[0], args[1]), nil
+}
+
+// defaultFilter replaces an empty value with the argument
+func defaultFilter(value string, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("1 argument was expected (got %d)", len(args))
+	}
+
+	if value == "" {
+		return args[0], nil
+	}
+
+	return value, nil
+}
+
+// splitWords splits a value into its words. Words are separated by any character that isn't a letter or number, and by
+// changes in case (e.g. "userProfile", "UserProfile", "user-profile" and "user_profile" all become "user" and "profile").
+// Runs of capitals are treated as a single word (e.g. "HTTPServer" becomes "HTTP" and "Server").
+func splitWords(value string) []string {
+	words := []string{}
+	currentWord := []rune{}
+
+	runes := []rune(value)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(currentWord) > 0 {
+				words = append(words, string(currentWord))
+				currentWord = []rune{}
+			}
+
+			continue
+		}
+
+		if len(currentWord) > 0 && unicode.IsUpper(r) {
+			previous := currentWord[len(currentWord)-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if !unicode.IsUpper(previous) || nextIsLower {
+				words = append(words, string(currentWord))
+				currentWord = []rune{}
+			}
+		}
+
+		currentWord = append(currentWord, r)
+	}
+
+	if len(currentWord) > 0 {
+		words = append(words, string(currentWord))
+	}
+
+	return words
+}
+
+// capitalise returns the word in lower case, with the first letter in upper case
+func capitalise(word string) string {
+	runes := []rune(strings.ToLower(word))
+	if len(runes) == 0 {
+		return ""
+	}
+
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// toPascalCase converts a value to PascalCase (e.g. "user-profile" becomes "UserProfile")
+func toPascalCase(value string) string {
+	var builder strings.Builder
+	for _, word := range splitWords(value) {
+		builder.WriteString(capitalise(word))
+	}
+
+	return builder.String()
+}
+
+// toCamelCase converts a value to camelCase (e.g. "user-profile" becomes "userProfile")
+func toCamelCase(value string) string {
+	var builder strings.Builder
+	for i, word := range splitWords(value) {
+		if i == 0 {
+			builder.WriteString(strings.ToLower(word))
+		} else {
+			builder.WriteString(capitalise(word))
+		}
+	}
+
+	return builder.String()
+}
+
+// joinWords converts a value to lower-case words, joined with the given separator (e.g. "UserProfile" becomes "user_profile")
+func joinWords(value, separator string) string {
+	words := splitWords(value)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+
+	return strings.Join(words, separator)
+}
+
+// irregularPlurals holds the plurals of common words that don't follow the usual rules
+var irregularPlurals = map[string]string{
+	"child":  "children",
+	"person": "people",
+	"man":    "men",
+	"woman":  "women",
+	"mouse":  "mice",
+	"goose":  "geese",
+	"tooth":  "teeth",
+	"foot":   "feet",
+	"datum":  "data",
+	"index":  "indices",
+}
+
+// uncountableWords holds common words that are the same in their singular and plural forms
+var uncountableWords = []string{"data", "equipment", "information", "media", "metadata", "news", "series", "sheep", "species"}
+
+// toPlural converts the last word in a value to its (English) plural form (e.g. "category" becomes "categories").
+// The case of the value is kept (e.g. "BOX" becomes "BOXES"). Only common rules (and a few irregular words) are handled, so
+// some rarer words aren't pluralised correctly.
+func toPlural(value string) string {
+	words := splitWords(value)
+	if len(words) == 0 {
+		return value
+	}
+
+	lastWord := words[len(words)-1]
+	lowerLastWord := strings.ToLower(lastWord)
+	prefix := value[:strings.LastIndex(value, lastWord)]
+	suffix := value[strings.LastIndex(value, lastWord)+len(lastWord):]
+
+	for _, uncountableWord := range uncountableWords {
+		if lowerLastWord == uncountableWord {
+			return value
+		}
+	}
+
+	var pluralWord string
+	if irregularPlural, isIrregular := irregularPlurals[lowerLastWord]; isIrregular {
+		pluralWord = matchCase(irregularPlural, lastWord)
+	} else {
+		ending := ""
+		stem := lastWord
+		switch {
+		case endsWithSingleVowelAndZ(lowerLastWord):
+			stem = lastWord + lastWord[len(lastWord)-1:] // The z is doubled (e.g. "quiz" becomes "quizzes")
+			ending = "es"
+		case hasAnySuffix(lowerLastWord, "s", "x", "z", "ch", "sh"):
+			ending = "es"
+		case strings.HasSuffix(lowerLastWord, "y") && len(lowerLastWord) > 1 && !strings.ContainsRune("aeiou", rune(lowerLastWord[len(lowerLastWord)-2])):
+			stem = lastWord[:len(lastWord)-1]
+			ending = "ies"
+		default:
+			ending = "s"
+		}
+
+		if isUpperCase(lastWord) && len(lastWord) > 1 {
+			ending = strings.ToUpper(ending)
+		}
+
+		pluralWord = stem + ending
+	}
+
+	return prefix + pluralWord + suffix
+}
+
+// endsWithSingleVowelAndZ identifies if the (lower case) word ends with a "z" after a single vowel (e.g. "quiz" or "fez",
+// but not "waltz" or "buzz"). A "u" after a "q" is treated as part of a consonant.
+func endsWithSingleVowelAndZ(word string) bool {
+	length := len(word)
+	if length < 2 || word[length-1] != 'z' || !strings.ContainsRune("aeiou", rune(word[length-2])) {
+		return false
+	}
+
+	stem := word[:length-2]
+	return stem == "" || strings.HasSuffix(stem, "qu") || !strings.ContainsRune("aeiou", rune(stem[len(stem)-1]))
+}
+
+// hasAnySuffix identifies if the value ends with any of the given suffixes
+func hasAnySuffix(value string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(value, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isUpperCase identifies if every letter in the value is upper case
+func isUpperCase(value string) bool {
+	return strings.ToUpper(value) == value && strings.ToLower(value) != value
+}
+
+// matchCase returns the (lower case) word in the same case as the original word (upper case, capitalised, or lower case)
+func matchCase(word, original string) string {
+	switch {
+	case isUpperCase(original) && len(original) > 1:
+		return strings.ToUpper(word)
+	case unicode.IsUpper([]rune(original)[0]):
+		return capitalise(word)
+	default:
+		return word
+	}
+}
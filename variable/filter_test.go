@@ -0,0 +1,171 @@
+package variable_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+func TestPopulateWillApplyCaseConversionFilters(t *testing.T) {
+	inputs := []string{"user profile", "user-profile", "user_profile", "userProfile", "UserProfile", "USER_PROFILE"}
+	expectedResults := map[string]string{
+		"pascal": "UserProfile",
+		"camel":  "userProfile",
+		"snake":  "user_profile",
+		"kebab":  "user-profile",
+	}
+
+	for _, input := range inputs {
+		for filterName, expectedResult := range expectedResults {
+			result, err := variable.Populate("{: name | "+filterName+" :}", map[string]string{"name": input})
+			if err != nil {
+				t.Errorf("expected no error. Got '%s'", err.Error())
+				continue
+			}
+
+			if result != expectedResult {
+				t.Errorf("expected '%s' with the '%s' filter to be '%s'. Got '%s'", input, filterName, expectedResult, result)
+			}
+		}
+	}
+}
+
+func TestPopulateWillApplyFiltersInOrder(t *testing.T) {
+	testCases := map[string]string{
+		`{: name | snake | upper :}`:               "USER_PROFILE",
+		`{: name | upper | snake :}`:               "userprofile", // The word boundary is lost once in upper case
+		`{: name | lower :}`:                       "userprofile",
+		`{:name|kebab|replace "-" "_":}`:           "user_profile",
+		`{: name | replace "Profile" "Account" :}`: "UserAccount",
+		`{: name | replace ":}" "x" :}`:            "UserProfile",
+		`{: name | pascal | plural :}`:             "UserProfiles",
+	}
+
+	for text, expectedResult := range testCases {
+		result, err := variable.Populate(text, map[string]string{"name": "UserProfile"})
+		if err != nil {
+			t.Errorf("expected no error for '%s'. Got '%s'", text, err.Error())
+			continue
+		}
+
+		if result != expectedResult {
+			t.Errorf("expected '%s' to be populated as '%s'. Got '%s'", text, expectedResult, result)
+		}
+	}
+}
+
+func TestPopulateWillApplyPluralFilter(t *testing.T) {
+	testCases := map[string]string{
+		"user":         "users",
+		"category":     "categories",
+		"day":          "days",
+		"box":          "boxes",
+		"branch":       "branches",
+		"address":      "addresses",
+		"person":       "people",
+		"Child":        "Children",
+		"BOX":          "BOXES",
+		"news":         "news",
+		"user_address": "user_addresses",
+		"UserCategory": "UserCategories",
+		"quiz":         "quizzes",
+		"QUIZ":         "QUIZZES",
+		"waltz":        "waltzes",
+		"buzz":         "buzzes",
+	}
+
+	for value, expectedResult := range testCases {
+		result, err := variable.Populate("{: name | plural :}", map[string]string{"name": value})
+		if err != nil {
+			t.Errorf("expected no error. Got '%s'", err.Error())
+			continue
+		}
+
+		if result != expectedResult {
+			t.Errorf("expected the plural of '%s' to be '%s'. Got '%s'", value, expectedResult, result)
+		}
+	}
+}
+
+func TestPopulateWillUseDefaultFilterWithoutPromptingForMissingVariables(t *testing.T) {
+	// Nothing is available on the Stdin, so a prompt would fail with an EOF error
+	if err := setupMockStdIn(""); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := map[string]string{"empty": ""}
+
+	result, err := variable.Populate(`{: missing | default "x" :}-{: empty | default "y" | upper :}`, vars)
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if result != "x-Y" {
+		t.Errorf("expected result to be 'x-Y'. Got '%s'", result)
+	}
+
+	if _, isAdded := vars["missing"]; isAdded {
+		t.Errorf("expected the missing variable not to be added to the map")
+	}
+}
+
+func TestPopulateWillReturnValidationErrorForInvalidFilters(t *testing.T) {
+	texts := []string{
+		`{: name | unknown :}`,
+		`{: name | upper "x" :}`,
+		`{: name | replace "x" :}`,
+		`{: name | default :}`,
+	}
+
+	for _, text := range texts {
+		_, err := variable.Populate(text, map[string]string{"name": "value"})
+		if err == nil {
+			t.Errorf("expected an error for '%s'. Got nil", text)
+			continue
+		}
+
+		var validationErr *customerrors.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("expected a ValidationError for '%s'. Got '%s'", text, err.Error())
+		}
+	}
+}
+
+func TestTagsWillParseTheFiltersInEachTag(t *testing.T) {
+	tags := variable.Tags(`{: a :} {: b | replace "\"" "|" | default "x y" :} {\: c | upper :}`)
+
+	if len(tags) != 2 {
+		t.Errorf("expected 2 tags. Got %d: %v", len(tags), tags)
+		return
+	}
+
+	if tags[0].Name != "a" || len(tags[0].Filters) != 0 || tags[0].HasDefault() {
+		t.Errorf("expected the first tag to be 'a' with no filters. Got %+v", tags[0])
+	}
+
+	expectedFilters := []variable.FilterCall{
+		{Name: "replace", Args: []string{`"`, "|"}},
+		{Name: "default", Args: []string{"x y"}},
+	}
+
+	if tags[1].Name != "b" || len(tags[1].Filters) != len(expectedFilters) || !tags[1].HasDefault() {
+		t.Errorf("expected the second tag to be 'b' with 2 filters. Got %+v", tags[1])
+		return
+	}
+
+	for i, expectedFilter := range expectedFilters {
+		resultFilter := tags[1].Filters[i]
+		if resultFilter.Name != expectedFilter.Name || len(resultFilter.Args) != len(expectedFilter.Args) {
+			t.Errorf("expected filter %d to be %+v. Got %+v", i, expectedFilter, resultFilter)
+			continue
+		}
+
+		for j := range expectedFilter.Args {
+			if resultFilter.Args[j] != expectedFilter.Args[j] {
+				t.Errorf("expected filter %d to be %+v. Got %+v", i, expectedFilter, resultFilter)
+			}
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package variable
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+	templateparse "text/template/parse"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+)
+
+// goTemplateFuncs are the functions that can be used in Go templates (the same transformations as the filters in SCAFF's
+// own tags, plus "list", which splits a list variable into its items so it can be used in a range action, and "truthy",
+// which identifies if a value is true in the same way as SCAFF's own conditions)
+var goTemplateFuncs = template.FuncMap{
+	"upper":  strings.ToUpper,
+	"lower":  strings.ToLower,
+	"pascal": toPascalCase,
+	"camel":  toCamelCase,
+	"snake":  func(value string) string { return joinWords(value, "_") },
+	"kebab":  func(value string) string { return joinWords(value, "-") },
+	"plural": toPlural,
+	"replace": func(old, new, value string) string {
+		return strings.ReplaceAll(value, old, new)
+	},
+	"default": func(defaultValue, value string) string {
+		if value == "" {
+			return defaultValue
+		}
+
+		return value
+	},
+	"list":   ParseList,
+	"truthy": isTruthy,
+}
+
+// GoTemplate is a template compiled with Go's text/template package. Variables are accessed as fields of the dot (e.g.
+// "{{ .name | upper }}").
+type GoTemplate struct {
+	name     string
+	template *template.Template
+	noInput  bool // If true, a MissingVariablesError is returned for missing variables (rather than prompting for them)
+}
+
+// CompileGoTemplate compiles the given text as a Go text/template, with actions that use the given delimiters (if the
+// delimiters haven't been set, Go's own "{{" and "}}" are used).
+// The name identifies the template in any errors. Errors are ValidationErrors.
+func CompileGoTemplate(name, text string, delimiters Delimiters) (*GoTemplate, error) {
+	compiled, err := template.New(name).Delims(delimiters.Left, delimiters.Right).Funcs(goTemplateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, goTemplateError(name, err)
+	}
+
+	return &GoTemplate{name: name, template: compiled}, nil
+}
+
+// Render executes the template, writing the result to the given writer.
+// Before it is executed, the user is prompted for any variables it uses that don't exist in the vars map (unless they are
+// only used with the "default" function). If the template was compiled with NoInput, a MissingVariablesError is returned
+// instead of prompting. Partials can't be included in Go templates, so loadPartial isn't used (the template can use its own
+// "define" and "template" actions instead).
+func (t *GoTemplate) Render(writer io.Writer, vars map[string]string, loadPartial PartialLoader) error {
+	tags, _ := t.Tags(nil)
+	for _, tag := range tags {
+		if tag.HasDefault() {
+			continue
+		}
+
+		if _, err := lookup(tag.Name, vars, t.noInput); err != nil {
+			return err
+		}
+	}
+
+	if err := t.template.Execute(writer, vars); err != nil {
+		return goTemplateError(t.name, err)
+	}
+
+	return nil
+}
+
+// Tags returns the variables used in the template (in the order they are used), as tags without filters. Variables used
+// with the "default" function are given a "default" filter, and those passed to the "list" function are marked as lists.
+// Only the fields of the top-level dot (e.g. ".name", "$.name" or `index . "name"`) are variables (within "range" and
+// "with" actions, the dot is something else).
+func (t *GoTemplate) Tags(loadPartial PartialLoader) ([]Tag, error) {
+	collector := &goTemplateTagCollector{tags: []Tag{}}
+
+	// The main template is walked first, followed by any templates it defines (in name order)
+	collector.walk(t.template.Tree.Root, true)
+
+	definedTemplates := t.template.Templates()
+	slices.SortFunc(definedTemplates, func(a, b *template.Template) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	for _, definedTemplate := range definedTemplates {
+		if definedTemplate.Name() != t.name && definedTemplate.Tree != nil {
+			collector.walk(definedTemplate.Tree.Root, true)
+		}
+	}
+
+	return collector.tags, nil
+}
+
+// goTemplateTagCollector collects the variables used within a Go template's parse tree (see GoTemplate.Tags)
+type goTemplateTagCollector struct {
+	tags []Tag
+}
+
+// walk collects the variables used within the given node. The dotIsRoot identifies if the dot is the top-level data.
+func (c *goTemplateTagCollector) walk(node templateparse.Node, dotIsRoot bool) {
+	switch typedNode := node.(type) {
+	case *templateparse.ListNode:
+		if typedNode == nil {
+			return
+		}
+
+		for _, child := range typedNode.Nodes {
+			c.walk(child, dotIsRoot)
+		}
+
+	case *templateparse.ActionNode:
+		c.pipe(typedNode.Pipe, dotIsRoot)
+
+	case *templateparse.IfNode:
+		c.pipe(typedNode.Pipe, dotIsRoot)
+		c.walk(typedNode.List, dotIsRoot)
+		c.walk(typedNode.ElseList, dotIsRoot)
+
+	case *templateparse.RangeNode:
+		c.pipe(typedNode.Pipe, dotIsRoot)
+		c.walk(typedNode.List, false)
+		c.walk(typedNode.ElseList, dotIsRoot)
+
+	case *templateparse.WithNode:
+		c.pipe(typedNode.Pipe, dotIsRoot)
+		c.walk(typedNode.List, false)
+		c.walk(typedNode.ElseList, dotIsRoot)
+
+	case *templateparse.TemplateNode:
+		c.pipe(typedNode.Pipe, dotIsRoot)
+	}
+}
+
+// pipe collects the variables used within the given pipeline
+func (c *goTemplateTagCollector) pipe(pipe *templateparse.PipeNode, dotIsRoot bool) {
+	if pipe == nil {
+		return
+	}
+
+	hasDefault := false
+	for _, command := range pipe.Cmds {
+		if commandFunction(command) == "default" {
+			hasDefault = true
+		}
+	}
+
+	for _, command := range pipe.Cmds {
+		isList := commandFunction(command) == "list"
+
+		if commandFunction(command) == "index" && len(command.Args) > 2 {
+			if _, isDot := command.Args[1].(*templateparse.DotNode); isDot && dotIsRoot {
+				if name, isString := command.Args[2].(*templateparse.StringNode); isString {
+					c.add(name.Text, hasDefault, isList)
+				}
+			}
+		}
+
+		for _, arg := range command.Args {
+			c.argument(arg, dotIsRoot, hasDefault, isList)
+		}
+	}
+}
+
+// argument collects the variable used by an argument within a pipeline (if it is one)
+func (c *goTemplateTagCollector) argument(arg templateparse.Node, dotIsRoot, hasDefault, isList bool) {
+	switch typedArg := arg.(type) {
+	case *templateparse.FieldNode:
+		if dotIsRoot {
+			c.add(typedArg.Ident[0], hasDefault, isList)
+		}
+
+	case *templateparse.VariableNode:
+		if typedArg.Ident[0] == "$" && len(typedArg.Ident) > 1 {
+			c.add(typedArg.Ident[1], hasDefault, isList)
+		}
+
+	case *templateparse.ChainNode:
+		c.argument(typedArg.Node, dotIsRoot, hasDefault, isList)
+
+	case *templateparse.PipeNode:
+		c.pipe(typedArg, dotIsRoot)
+	}
+}
+
+// add adds a tag for the variable
+func (c *goTemplateTagCollector) add(name string, hasDefault, isList bool) {
+	tag := Tag{Name: name, IsList: isList}
+	if hasDefault {
+		tag.Filters = []FilterCall{{Name: "default"}}
+	}
+
+	c.tags = append(c.tags, tag)
+}
+
+// commandFunction returns the name of the function that is called by the command (or an empty string, if it doesn't call
+// a function)
+func commandFunction(command *templateparse.CommandNode) string {
+	if len(command.Args) == 0 {
+		return ""
+	}
+
+	if identifier, isIdentifier := command.Args[0].(*templateparse.IdentifierNode); isIdentifier {
+		return identifier.Ident
+	}
+
+	return ""
+}
+
+// goTemplateError creates a ValidationError from an error returned by text/template (which already includes the name, line
+// and column of the problem)
+func goTemplateError(name string, err error) error {
+	message := strings.TrimPrefix(err.Error(), "template: ")
+	if name == "" {
+		message = strings.TrimPrefix(message, ":")
+	}
+
+	return &customerrors.ValidationError{Message: message}
+}
@@ -0,0 +1,151 @@
+package variable_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+func TestGoTemplateRenderWillPopulateTheTemplateWithTheCaseConversionFuncs(t *testing.T) {
+	text := `{{ .name | pascal }} {{ .name | snake | upper }} {{ .name | replace "-" "." }} {{ .missing | default "none" }}` +
+		`{{ range $i, $tag := list .tags }} {{ $i }}={{ $tag | camel }}{{ end }}{{ if eq $.kind "api" }} api{{ end }}`
+
+	template, err := variable.CompileGoTemplate("t.txt", text, variable.Delimiters{})
+	if err != nil {
+		t.Fatalf("expected no error. Got %v", err)
+	}
+
+	vars := map[string]string{"name": "user-profile", "tags": "first tag, second_tag", "kind": "api"}
+
+	var builder strings.Builder
+	if err := template.Render(&builder, vars, nil); err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	expected := "UserProfile USER_PROFILE user.profile none 0=firstTag 1=secondTag api"
+	if builder.String() != expected {
+		t.Errorf("expected '%s'. Got '%s'", expected, builder.String())
+	}
+}
+
+func TestGoTemplateTruthyWillTreatValuesInTheSameWayAsConditions(t *testing.T) {
+	template, err := variable.CompileGoTemplate("t.txt", `{{ if truthy .flag }}yes{{ else }}no{{ end }}`, variable.Delimiters{})
+	if err != nil {
+		t.Fatalf("expected no error. Got %v", err)
+	}
+
+	cases := map[string]string{"true": "yes", "1": "yes", "docker": "yes", "false": "no", "0": "no", " ": "no"}
+	for value, expected := range cases {
+		var builder strings.Builder
+		if err := template.Render(&builder, map[string]string{"flag": value}, nil); err != nil {
+			t.Errorf("expected no error. Got %v", err)
+		}
+
+		if builder.String() != expected {
+			t.Errorf("expected '%s' for the value '%s'. Got '%s'", expected, value, builder.String())
+		}
+	}
+}
+
+func TestGoTemplateRenderWillPromptForMissingVariables(t *testing.T) {
+	originalPrintF := variable.PrintFormatted
+	defer func() { variable.PrintFormatted = originalPrintF }()
+
+	prompted := []string{}
+	variable.PrintFormatted = func(format string, a ...any) (n int, err error) {
+		prompted = append(prompted, a[0].(string))
+		return 0, nil
+	}
+
+	if err := setupMockStdIn("prompted-first\nprompted-third\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	template, err := variable.CompileGoTemplate("t.txt", `{{ .first }} {{ .second | default "x" }} {{ index . "third" }}`, variable.Delimiters{})
+	if err != nil {
+		t.Fatalf("expected no error. Got %v", err)
+	}
+
+	vars := map[string]string{}
+
+	var builder strings.Builder
+	if err := template.Render(&builder, vars, nil); err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	expected := "prompted-first x prompted-third"
+	if builder.String() != expected {
+		t.Errorf("expected '%s'. Got '%s'", expected, builder.String())
+	}
+
+	if strings.Join(prompted, ",") != "first,third" {
+		t.Errorf("expected to be prompted for 'first' and 'third'. Got %v", prompted)
+	}
+}
+
+func TestGoTemplateTagsWillReturnTheTopLevelVariables(t *testing.T) {
+	text := `{{ .a }}{{ if .b }}{{ .c | default "x" }}{{ end }}{{ range list .d }}{{ .ignored }}{{ $.e }}{{ end }}` +
+		`{{ with .f }}{{ .ignored }}{{ end }}{{ define "inner" }}{{ .g }}{{ end }}`
+
+	template, err := variable.CompileGoTemplate("t.txt", text, variable.Delimiters{})
+	if err != nil {
+		t.Fatalf("expected no error. Got %v", err)
+	}
+
+	tags, _ := template.Tags(nil)
+
+	names := []string{}
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+
+	expectedNames := []string{"a", "b", "c", "d", "e", "f", "g"}
+	if strings.Join(names, ",") != strings.Join(expectedNames, ",") {
+		t.Fatalf("expected the names %v. Got %v", expectedNames, names)
+	}
+
+	if !tags[2].HasDefault() || tags[0].HasDefault() {
+		t.Errorf("expected only 'c' to have a default. Got %v", tags)
+	}
+
+	if !tags[3].IsList || tags[4].IsList {
+		t.Errorf("expected only 'd' to be a list. Got %v", tags)
+	}
+}
+
+func TestCompileGoTemplateWillReturnValidationErrorsWithTheLocation(t *testing.T) {
+	_, err := variable.CompileGoTemplate("t.txt", "line one\n{{ .name ", variable.Delimiters{})
+
+	var validationErr *customerrors.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a ValidationError. Got %v", err)
+	}
+
+	if !strings.HasPrefix(validationErr.Message, "t.txt:2:") {
+		t.Errorf("expected the error to start with the name and line. Got '%s'", validationErr.Message)
+	}
+}
+
+func TestPopulateWithOptionsWillUseTheNamedEngine(t *testing.T) {
+	vars := map[string]string{"name": "user-profile"}
+
+	cases := map[string]string{
+		variable.EngineScaff:      "{: name | pascal :}.go",
+		"":                        "{: name | pascal :}.go",
+		variable.EngineGoTemplate: "{{ .name | pascal }}.go",
+	}
+
+	for engine, text := range cases {
+		result, err := variable.PopulateWithOptions(variable.Options{Engine: engine}, text, vars)
+		if err != nil {
+			t.Errorf("expected no error for the engine '%s'. Got %v", engine, err)
+		}
+
+		if result != "UserProfile.go" {
+			t.Errorf("expected 'UserProfile.go' for the engine '%s'. Got '%s'", engine, result)
+		}
+	}
+}
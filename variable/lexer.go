@@ -0,0 +1,270 @@
+package variable
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// position is a location within a template (both the line and column start at 1)
+type position struct {
+	line   int
+	column int
+}
+
+// itemType identifies the type of an item
+type itemType int
+
+const (
+	itemText itemType = iota // Plain text (with any escaped tags already unescaped)
+	itemTag                  // A tag (e.g. "{: name | upper :}")
+)
+
+// item is a piece of a template, as produced by the lexer
+type item struct {
+	typ    itemType
+	text   string  // The text (for text items), or the whole tag as it is written in the template (for tags)
+	tokens []token // The tokens within the tag (for tags)
+	pos    position
+}
+
+// tokenType identifies the type of a token within a tag
+type tokenType int
+
+const (
+	tokenWord     tokenType = iota // A variable name, keyword, number, etc
+	tokenString                    // A double-quoted string (the token's value is unquoted)
+	tokenPipe                      // "|"
+	tokenEqual                     // "=="
+	tokenNotEqual                  // "!="
+	tokenNot                       // "!"
+)
+
+// token is a part of a tag
+type token struct {
+	typ   tokenType
+	value string
+	pos   position
+}
+
+// lexer splits the text of a template into items
+type lexer struct {
+	name       string // Identifies the template in errors
+	delimiters Delimiters
+	text       string
+	offset     int      // The current offset within the text
+	pos        position // The position of the current offset
+	items      []item
+}
+
+// lex splits the text of a template into text and tag items.
+// Tags start with the opening delimiter and end with the closing delimiter (on the same line), e.g. "{:" and ":}". Tags can
+// be escaped by placing a backslash after the first character of the opening delimiter (e.g. "{\:"), in which case they are
+// treated as text (and the backslash is removed).
+// The name identifies the template in any errors.
+func lex(name, text string, delimiters Delimiters) ([]item, error) {
+	l := &lexer{name: name, delimiters: delimiters, text: text, pos: position{line: 1, column: 1}}
+	escapedLeft := delimiters.EscapedLeft()
+	leftStart := delimiters.Left[0]
+
+	var textBuilder strings.Builder
+	textPos := l.pos
+
+	for l.offset < len(l.text) {
+		nextStart := strings.IndexByte(l.text[l.offset:], leftStart)
+		if nextStart < 0 {
+			textBuilder.WriteString(l.text[l.offset:])
+			l.advance(len(l.text) - l.offset)
+			break
+		}
+
+		textBuilder.WriteString(l.text[l.offset : l.offset+nextStart])
+		l.advance(nextStart)
+
+		remaining := l.text[l.offset:]
+		switch {
+		case strings.HasPrefix(remaining, escapedLeft):
+			textBuilder.WriteString(delimiters.Left)
+			l.advance(len(escapedLeft))
+
+		case strings.HasPrefix(remaining, delimiters.Left):
+			if textBuilder.Len() > 0 {
+				l.items = append(l.items, item{typ: itemText, text: textBuilder.String(), pos: textPos})
+				textBuilder.Reset()
+			}
+
+			if err := l.lexTag(); err != nil {
+				return nil, err
+			}
+
+			textPos = l.pos
+
+		default:
+			textBuilder.WriteByte(leftStart)
+			l.advance(1)
+		}
+	}
+
+	if textBuilder.Len() > 0 {
+		l.items = append(l.items, item{typ: itemText, text: textBuilder.String(), pos: textPos})
+	}
+
+	return l.items, nil
+}
+
+// advance moves the offset forward by the given number of bytes (keeping track of the line and column)
+func (l *lexer) advance(byteCount int) {
+	for _, character := range []byte(l.text[l.offset : l.offset+byteCount]) {
+		switch {
+		case character == '\n':
+			l.pos.line++
+			l.pos.column = 1
+		case character&0xC0 != 0x80: // Continuation bytes of multi-byte characters aren't counted
+			l.pos.column++
+		}
+	}
+
+	l.offset += byteCount
+}
+
+// lexTag reads the tag that starts at the current offset (including its delimiters)
+func (l *lexer) lexTag() error {
+	tagStart := l.offset
+	tagPos := l.pos
+	tokens := []token{}
+
+	l.advance(len(l.delimiters.Left))
+	for {
+		for l.offset < len(l.text) && (l.text[l.offset] == ' ' || l.text[l.offset] == '\t') {
+			l.advance(1)
+		}
+
+		if l.offset >= len(l.text) || l.text[l.offset] == '\n' || l.text[l.offset] == '\r' {
+			return templateError(l.name, tagPos, fmt.Sprintf("the tag is missing its closing '%s'", l.delimiters.Right))
+		}
+
+		if strings.HasPrefix(l.text[l.offset:], l.delimiters.Right) {
+			l.advance(len(l.delimiters.Right))
+			l.items = append(l.items, item{typ: itemTag, text: l.text[tagStart:l.offset], tokens: tokens, pos: tagPos})
+			return nil
+		}
+
+		nextToken, err := l.lexToken("tag")
+		if err != nil {
+			return err
+		}
+
+		tokens = append(tokens, nextToken)
+	}
+}
+
+// lexExpression splits a standalone expression (e.g. the condition in a "when" property) into tokens.
+// The name identifies the expression in any errors.
+func lexExpression(name, text string) ([]token, error) {
+	l := &lexer{name: name, text: text, pos: position{line: 1, column: 1}}
+	tokens := []token{}
+
+	for {
+		for l.offset < len(l.text) && strings.ContainsRune(" \t\r\n", rune(l.text[l.offset])) {
+			l.advance(1)
+		}
+
+		if l.offset >= len(l.text) {
+			return tokens, nil
+		}
+
+		nextToken, err := l.lexToken("condition")
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, nextToken)
+	}
+}
+
+// lexToken reads the token that starts at the current offset. The context (e.g. "tag") is used in errors.
+func (l *lexer) lexToken(context string) (token, error) {
+	remaining := l.text[l.offset:]
+	tokenPos := l.pos
+
+	switch {
+	case remaining[0] == '"' || remaining[0] == '\'':
+		value, err := l.lexString()
+		if err != nil {
+			return token{}, err
+		}
+
+		return token{typ: tokenString, value: value, pos: tokenPos}, nil
+
+	case remaining[0] == '|':
+		l.advance(1)
+		return token{typ: tokenPipe, value: "|", pos: tokenPos}, nil
+
+	case strings.HasPrefix(remaining, "=="):
+		l.advance(2)
+		return token{typ: tokenEqual, value: "==", pos: tokenPos}, nil
+
+	case strings.HasPrefix(remaining, "!="):
+		l.advance(2)
+		return token{typ: tokenNotEqual, value: "!=", pos: tokenPos}, nil
+
+	case remaining[0] == '!':
+		l.advance(1)
+		return token{typ: tokenNot, value: "!", pos: tokenPos}, nil
+
+	case isWordCharacter(remaining[0]):
+		wordLength := 1
+		for wordLength < len(remaining) && isWordCharacter(remaining[wordLength]) {
+			wordLength++
+		}
+
+		l.advance(wordLength)
+		return token{typ: tokenWord, value: remaining[:wordLength], pos: tokenPos}, nil
+
+	default:
+		character := []rune(remaining)[0]
+		return token{}, templateError(l.name, tokenPos, fmt.Sprintf("unexpected character '%c' in the %s", character, context))
+	}
+}
+
+// lexString reads the quoted string that starts at the current offset, and returns its unquoted value.
+// Double-quoted strings use Go's escape sequences (e.g. "a\"b"). In single-quoted strings, only a quote or backslash can be
+// escaped (e.g. 'it\'s').
+func (l *lexer) lexString() (string, error) {
+	stringPos := l.pos
+	remaining := l.text[l.offset:]
+	quote := remaining[0]
+
+	for i := 1; i < len(remaining) && remaining[i] != '\n'; i++ {
+		switch remaining[i] {
+		case '\\':
+			i++
+		case quote:
+			quoted := remaining[:i+1]
+			l.advance(i + 1)
+
+			if quote == '\'' {
+				unescaper := strings.NewReplacer("\\'", "'", "\\\\", "\\")
+				return unescaper.Replace(quoted[1 : len(quoted)-1]), nil
+			}
+
+			value, err := strconv.Unquote(quoted)
+			if err != nil {
+				return "", templateError(l.name, stringPos, fmt.Sprintf("invalid quoted value: %s", quoted))
+			}
+
+			return value, nil
+		}
+	}
+
+	return "", templateError(l.name, stringPos, "a quoted value is missing its closing quote")
+}
+
+// isWordCharacter identifies if the character can be part of a word within a tag (variable names can only contain letters,
+// numbers, "-" and "_", but numbers in conditions can also contain a ".")
+func isWordCharacter(character byte) bool {
+	return (character >= 'a' && character <= 'z') ||
+		(character >= 'A' && character <= 'Z') ||
+		(character >= '0' && character <= '9') ||
+		character == '-' || character == '_' || character == '.'
+}
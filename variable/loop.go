@@ -0,0 +1,58 @@
+package variable
+
+import "strconv"
+
+// LoopNames returns the names of the variables that are available for each item in a loop over a list (in a range block,
+// or the "forEach" of a file/directory): the item itself, its (zero-based) index, and whether it is the first/last item
+func LoopNames(itemName string) []string {
+	return []string{itemName, itemName + "_index", itemName + "_first", itemName + "_last"}
+}
+
+// LoopVars returns the values of the loop variables (see LoopNames) for each item in the given list variable. If the list
+// variable doesn't exist in the map, the user is prompted to provide it (and it is then added to the map), unless noInput is
+// true (in which case, a MissingVariablesError is returned).
+func LoopVars(listName, itemName string, vars map[string]string, noInput bool) ([]map[string]string, error) {
+	listValue, err := lookup(listName, vars, noInput)
+	if err != nil {
+		return nil, err
+	}
+
+	loopNames := LoopNames(itemName)
+	items := ParseList(listValue)
+	loopVars := make([]map[string]string, 0, len(items))
+	for i, item := range items {
+		loopVars = append(loopVars, map[string]string{
+			loopNames[0]: item,
+			loopNames[1]: strconv.Itoa(i),
+			loopNames[2]: strconv.FormatBool(i == 0),
+			loopNames[3]: strconv.FormatBool(i == len(items)-1),
+		})
+	}
+
+	return loopVars, nil
+}
+
+// WithLoopVars adds the given loop variables to the map while the given func is called. The loop variables hide any
+// variables with the same names, until the func returns.
+func WithLoopVars(vars, loopVars map[string]string, call func() error) error {
+	hiddenValues := make(map[string]string)
+	for name, value := range loopVars {
+		if hiddenValue, varExists := vars[name]; varExists {
+			hiddenValues[name] = hiddenValue
+		}
+
+		vars[name] = value
+	}
+
+	defer func() {
+		for name := range loopVars {
+			if hiddenValue, wasHidden := hiddenValues[name]; wasHidden {
+				vars[name] = hiddenValue
+			} else {
+				delete(vars, name)
+			}
+		}
+	}()
+
+	return call()
+}
@@ -0,0 +1,51 @@
+package variable_test
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+func TestLoopVarsWillReturnTheLoopVariablesForEachItem(t *testing.T) {
+	vars := map[string]string{"entities": `["user", "order, item"]`}
+
+	results, err := variable.LoopVars("entities", "entity", vars, false)
+	if err != nil {
+		t.Fatalf("expected no error. Got %v", err)
+	}
+
+	expected := []map[string]string{
+		{"entity": "user", "entity_index": "0", "entity_first": "true", "entity_last": "false"},
+		{"entity": "order, item", "entity_index": "1", "entity_first": "false", "entity_last": "true"},
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d items. Got %v", len(expected), results)
+	}
+
+	for i := range expected {
+		if !maps.Equal(results[i], expected[i]) {
+			t.Errorf("expected item %d to be %v. Got %v", i, expected[i], results[i])
+		}
+	}
+}
+
+func TestWithLoopVarsWillRestoreTheHiddenVariablesAfterwards(t *testing.T) {
+	vars := map[string]string{"entity": "original", "other": "value"}
+
+	err := variable.WithLoopVars(vars, map[string]string{"entity": "user", "entity_index": "0"}, func() error {
+		if vars["entity"] != "user" || vars["entity_index"] != "0" || vars["other"] != "value" {
+			t.Errorf("expected the loop variables to be added. Got %v", vars)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error. Got %v", err)
+	}
+
+	if !maps.Equal(vars, map[string]string{"entity": "original", "other": "value"}) {
+		t.Errorf("expected the original variables to be restored. Got %v", vars)
+	}
+}
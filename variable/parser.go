@@ -0,0 +1,350 @@
+package variable
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// controlKeywords holds the words that start control tags (rather than variable tags)
+var controlKeywords = []string{"if", "else", "end", "range", "include"}
+
+// reservedNames holds the words used in control tags, which can't be used as variable names
+var reservedNames = []string{"if", "else", "end", "not", "range", "include"}
+
+// parser builds the nodes of a template from its items
+type parser struct {
+	name       string     // Identifies the template in errors
+	delimiters Delimiters // Used to show example tags in errors
+}
+
+// openBlock is a block that has been opened (by an "if" or "range" tag), but not yet ended
+type openBlock struct {
+	keyword  string  // "if" or "range"
+	node     *ifNode // Only set for "if" blocks
+	pos      position
+	hasElse  bool
+	previous *[]node // The body that the block itself is in
+}
+
+// parse builds the nodes of a template from its items (see lex).
+// The name identifies the template in any errors.
+func parse(name string, items []item, delimiters Delimiters) ([]node, error) {
+	p := &parser{name: name, delimiters: delimiters}
+	items, lineEndings := trimStandaloneLines(items)
+
+	rootNodes := []node{}
+	currentBody := &rootNodes
+	openBlocks := []*openBlock{}
+
+	for i, currentItem := range items {
+		if currentItem.typ == itemText {
+			if currentItem.text != "" {
+				*currentBody = append(*currentBody, &textNode{text: currentItem.text})
+			}
+
+			continue
+		}
+
+		if len(currentItem.tokens) == 0 {
+			return nil, p.errorAt(currentItem.pos, "the tag is empty")
+		}
+
+		keyword := ""
+		if currentItem.tokens[0].typ == tokenWord && slices.Contains(controlKeywords, currentItem.tokens[0].value) {
+			keyword = currentItem.tokens[0].value
+		}
+
+		switch keyword {
+		case "":
+			tag, err := p.parseVariableTag(currentItem)
+			if err != nil {
+				return nil, err
+			}
+
+			*currentBody = append(*currentBody, &variableNode{tag: tag, templateName: name, pos: currentItem.pos})
+
+		case "if":
+			parsedCondition, err := p.parseCondition(currentItem, currentItem.tokens[1:])
+			if err != nil {
+				return nil, err
+			}
+
+			ifBlock := &ifNode{branches: []ifBranch{{condition: parsedCondition}}}
+			*currentBody = append(*currentBody, ifBlock)
+
+			openBlocks = append(openBlocks, &openBlock{keyword: "if", node: ifBlock, pos: currentItem.pos, previous: currentBody})
+			currentBody = &ifBlock.branches[0].body
+
+		case "range":
+			rangeBlock, err := p.parseRangeTag(currentItem)
+			if err != nil {
+				return nil, err
+			}
+			*currentBody = append(*currentBody, rangeBlock)
+
+			openBlocks = append(openBlocks, &openBlock{keyword: "range", pos: currentItem.pos, previous: currentBody})
+			currentBody = &rangeBlock.body
+
+		case "include":
+			tokens := currentItem.tokens
+			if len(tokens) != 2 || tokens[1].typ != tokenString {
+				return nil, p.errorAt(currentItem.pos, fmt.Sprintf("invalid tag '%s': expected a quoted path (e.g. '%s')", currentItem.text, p.delimiters.tag(`include "partials/header.txt"`)))
+			}
+
+			*currentBody = append(*currentBody, &includeNode{
+				partialPath:  tokens[1].value,
+				includeTag:   currentItem.text,
+				templateName: name,
+				pos:          currentItem.pos,
+				lineEnding:   lineEndings[i],
+				delimiters:   delimiters,
+			})
+
+		case "else":
+			if len(openBlocks) == 0 || openBlocks[len(openBlocks)-1].keyword != "if" {
+				return nil, p.errorAt(currentItem.pos, fmt.Sprintf("invalid tag '%s': there is no 'if' block for it to be part of", currentItem.text))
+			}
+
+			block := openBlocks[len(openBlocks)-1]
+			if block.hasElse {
+				return nil, p.errorAt(currentItem.pos, fmt.Sprintf(
+					"invalid tag '%s': the 'if' block (opened at %d:%d) already has an 'else'",
+					currentItem.text,
+					block.pos.line,
+					block.pos.column,
+				))
+			}
+
+			if len(currentItem.tokens) == 1 {
+				block.hasElse = true
+				currentBody = &block.node.elseBody
+				continue
+			}
+
+			if currentItem.tokens[1].typ != tokenWord || currentItem.tokens[1].value != "if" {
+				return nil, p.errorAt(currentItem.pos, fmt.Sprintf("invalid tag '%s': expected '%s' or '%s'", currentItem.text, p.delimiters.tag("else"), p.delimiters.tag("else if condition")))
+			}
+
+			parsedCondition, err := p.parseCondition(currentItem, currentItem.tokens[2:])
+			if err != nil {
+				return nil, err
+			}
+
+			block.node.branches = append(block.node.branches, ifBranch{condition: parsedCondition})
+			currentBody = &block.node.branches[len(block.node.branches)-1].body
+
+		case "end":
+			if len(currentItem.tokens) != 1 {
+				return nil, p.errorAt(currentItem.pos, fmt.Sprintf("invalid tag '%s': expected '%s'", currentItem.text, p.delimiters.tag("end")))
+			}
+
+			if len(openBlocks) == 0 {
+				return nil, p.errorAt(currentItem.pos, fmt.Sprintf("invalid tag '%s': there is no block for it to end", currentItem.text))
+			}
+
+			block := openBlocks[len(openBlocks)-1]
+			openBlocks = openBlocks[:len(openBlocks)-1]
+			currentBody = block.previous
+		}
+	}
+
+	if len(openBlocks) > 0 {
+		block := openBlocks[len(openBlocks)-1]
+		return nil, p.errorAt(block.pos, fmt.Sprintf("the '%s' block is missing its '%s' tag", block.keyword, p.delimiters.tag("end")))
+	}
+
+	return rootNodes, nil
+}
+
+// errorAt creates a ValidationError for a problem at the given position within the template
+func (p *parser) errorAt(pos position, message string) error {
+	return templateError(p.name, pos, message)
+}
+
+// parseVariableTag parses a variable tag (e.g. `{: name | replace "-" "_" | upper :}`)
+func (p *parser) parseVariableTag(tagItem item) (Tag, error) {
+	tokens := tagItem.tokens
+	if tokens[0].typ != tokenWord || !namePattern.MatchString(tokens[0].value) {
+		return Tag{}, p.errorAt(tokens[0].pos, fmt.Sprintf("invalid tag '%s': expected a variable name", tagItem.text))
+	}
+
+	tag := Tag{Name: tokens[0].value}
+	tokens = tokens[1:]
+
+	for len(tokens) > 0 {
+		if tokens[0].typ != tokenPipe {
+			return Tag{}, p.errorAt(tokens[0].pos, fmt.Sprintf("invalid tag '%s': expected a '|' before '%s'", tagItem.text, tokens[0].value))
+		}
+
+		if len(tokens) < 2 || tokens[1].typ != tokenWord {
+			return Tag{}, p.errorAt(tokens[0].pos, fmt.Sprintf("invalid tag '%s': a filter name is missing after a '|'", tagItem.text))
+		}
+
+		filter := FilterCall{Name: tokens[1].value, Args: []string{}}
+		filterPos := tokens[1].pos
+		tokens = tokens[2:]
+
+		for len(tokens) > 0 && tokens[0].typ != tokenPipe {
+			if tokens[0].typ != tokenString {
+				return Tag{}, p.errorAt(tokens[0].pos, fmt.Sprintf("invalid tag '%s': the arguments for the filter '%s' should be in double quotes", tagItem.text, filter.Name))
+			}
+
+			filter.Args = append(filter.Args, tokens[0].value)
+			tokens = tokens[1:]
+		}
+
+		// Filters only return errors for invalid arguments, so they can be checked here (rather than when rendering)
+		filterFunc, filterExists := filters[filter.Name]
+		if !filterExists {
+			return Tag{}, p.errorAt(filterPos, fmt.Sprintf(
+				"unknown filter '%s' used with the variable '%s' (expected one of: %s)",
+				filter.Name,
+				tag.Name,
+				strings.Join(slices.Sorted(maps.Keys(filters)), ", "),
+			))
+		}
+
+		if _, err := filterFunc("", filter.Args); err != nil {
+			return Tag{}, p.errorAt(filterPos, fmt.Sprintf("unable to apply the filter '%s' to the variable '%s': %v", filter.Name, tag.Name, err))
+		}
+
+		tag.Filters = append(tag.Filters, filter)
+	}
+
+	return tag, nil
+}
+
+// parseRangeTag parses a "range" tag (e.g. "{: range item in list :}")
+func (p *parser) parseRangeTag(tagItem item) (*rangeNode, error) {
+	tokens := tagItem.tokens
+	if len(tokens) != 4 ||
+		tokens[1].typ != tokenWord || !namePattern.MatchString(tokens[1].value) ||
+		tokens[2].typ != tokenWord || tokens[2].value != "in" ||
+		tokens[3].typ != tokenWord || !namePattern.MatchString(tokens[3].value) {
+		return nil, p.errorAt(tagItem.pos, fmt.Sprintf("invalid tag '%s': expected '%s'", tagItem.text, p.delimiters.tag("range item in list")))
+	}
+
+	if slices.Contains(reservedNames, tokens[1].value) {
+		return nil, p.errorAt(tokens[1].pos, fmt.Sprintf("invalid tag '%s': '%s' is a reserved word, so can't be used as a variable name", tagItem.text, tokens[1].value))
+	}
+
+	return &rangeNode{itemName: tokens[1].value, listName: tokens[3].value}, nil
+}
+
+// parseCondition parses the condition in an "if" (or "else if") tag, from the tokens after the "if"
+func (p *parser) parseCondition(tagItem item, tokens []token) (condition, error) {
+	parsed, errPos, err := parseConditionTokens(tokens)
+	if err != nil {
+		if errPos == nil {
+			errPos = &tagItem.pos
+		}
+
+		return condition{}, p.errorAt(*errPos, fmt.Sprintf("invalid tag '%s': %v", tagItem.text, err))
+	}
+
+	return parsed, nil
+}
+
+// parseConditionTokens parses the tokens of a condition. If they are invalid, the position of the problem is also returned
+// (if it is known).
+func parseConditionTokens(tokens []token) (condition, *position, error) {
+	parsed := condition{}
+
+	if len(tokens) > 0 && (tokens[0].typ == tokenNot || (tokens[0].typ == tokenWord && tokens[0].value == "not")) {
+		parsed.isNegated = true
+		tokens = tokens[1:]
+	}
+
+	var err error
+	switch {
+	case len(tokens) == 1:
+		parsed.left, err = parseOperand(tokens[0])
+	case len(tokens) == 3 && (tokens[1].typ == tokenEqual || tokens[1].typ == tokenNotEqual):
+		parsed.operator = tokens[1].value
+		parsed.left, err = parseOperand(tokens[0])
+		if err == nil {
+			parsed.right, err = parseOperand(tokens[2])
+		}
+	case len(tokens) == 3:
+		return condition{}, &tokens[1].pos, fmt.Errorf("unknown operator '%s' (expected '==' or '!=')", tokens[1].value)
+	default:
+		return condition{}, nil, errors.New(`expected a variable, or a comparison (e.g. 'name == "value"')`)
+	}
+
+	if err != nil {
+		return condition{}, nil, err
+	}
+
+	return parsed, nil, nil
+}
+
+// trimStandaloneLines removes the lines that only contain a control tag (and whitespace), so blocks don't leave blank
+// lines behind. Returns the trimmed items, along with the line ending that was removed after each standalone include tag
+// (keyed by the index of the tag's item).
+func trimStandaloneLines(items []item) ([]item, map[int]string) {
+	trimmedItems := slices.Clone(items)
+	lineEndings := make(map[int]string)
+
+	// The start and end of the text to keep from each text item
+	keepStarts := make([]int, len(items))
+	keepEnds := make([]int, len(items))
+	for i, currentItem := range items {
+		keepEnds[i] = len(currentItem.text)
+	}
+
+	for i, currentItem := range items {
+		if currentItem.typ != itemTag || len(currentItem.tokens) == 0 ||
+			!slices.Contains(controlKeywords, currentItem.tokens[0].value) || currentItem.tokens[0].typ != tokenWord {
+			continue
+		}
+
+		// The text before the tag (on the same line) must be whitespace, and it must be on the first line or follow a newline
+		lineStart := -1
+		if i == 0 {
+			lineStart = 0
+		} else if previous := items[i-1]; previous.typ == itemText {
+			lastNewline := strings.LastIndex(previous.text, "\n")
+			if (lastNewline >= 0 || i == 1) && strings.TrimSpace(previous.text[lastNewline+1:]) == "" {
+				lineStart = lastNewline + 1
+			}
+		}
+
+		// The text after the tag (on the same line) must be whitespace, and it must be followed by a newline or the end
+		lineEnd := -1
+		if i == len(items)-1 {
+			lineEnd = 0
+		} else if next := items[i+1]; next.typ == itemText {
+			firstNewline := strings.Index(next.text, "\n")
+			switch {
+			case firstNewline >= 0 && strings.TrimSpace(next.text[:firstNewline]) == "":
+				lineEnd = firstNewline + 1
+			case firstNewline < 0 && i+1 == len(items)-1 && strings.TrimSpace(next.text) == "":
+				lineEnd = len(next.text)
+			}
+		}
+
+		if lineStart < 0 || lineEnd < 0 {
+			continue
+		}
+
+		if i > 0 {
+			keepEnds[i-1] = lineStart
+		}
+
+		if i < len(items)-1 {
+			keepStarts[i+1] = lineEnd
+			lineEndings[i] = strings.TrimLeft(items[i+1].text[:lineEnd], " \t")
+		}
+	}
+
+	for i, currentItem := range items {
+		if currentItem.typ == itemText {
+			trimmedItems[i].text = currentItem.text[keepStarts[i]:max(keepStarts[i], keepEnds[i])]
+		}
+	}
+
+	return trimmedItems, lineEndings
+}
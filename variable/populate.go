@@ -1,52 +1,83 @@
 package variable
 
 import (
-	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
 )
 
-// Populate returns the given string with the variable tags replaced with values from the given map.
-// If a variable doesn't exist in the map, the user is prompted to provide it (and it is then added to the map)
-// Once done, this will replace any escaped opening braces
+// Populate returns the given string with the variable tags replaced with values from the given map (passed through any
+// filters in the tag).
+// Conditional blocks (`{: if condition :}...{: else :}...{: end :}`) are replaced with the body of the branch that is chosen,
+// and loop blocks (`{: range item in list :}...{: end :}`) are replaced with their body, rendered once for each item.
+// If a variable doesn't exist in the map, the user is prompted to provide it (and it is then added to the map), unless the
+// tag uses the "default" filter.
+// Escaped tags ("{\:") are output as tags ("{:"), but aren't populated
 func Populate(text string, vars map[string]string) (string, error) {
-	resolvedText := text
-
-	// Regex explantion:
-	// Matches a series of alphanumeric characters surrounded by "{:" and ":}". The alphanumeric characters
-	// can also be preceeded/proceeded by spaces.
-	// Tags can be escaped by placing a backslash between the opening handlebar-brace and the colon ("{\:")
-	varTagRegex := regexp.MustCompile(`{: *[a-zA-Z0-9-_]+ *:}`)
-
-	for {
-		// Get the first variable tag
-		variableTag := varTagRegex.FindString(resolvedText)
-		if variableTag == "" {
-			break // No more tags
-		}
+	return PopulateWithPartials(text, vars, nil)
+}
 
-		// Get the variable name out of the tag
-		variableName := strings.Replace(variableTag, "{:", "", 1)
-		variableName = strings.Replace(variableName, ":}", "", 1)
-		variableName = strings.TrimSpace(variableName)
-
-		// Resolve the variable value
-		variableValue, varExists := vars[variableName]
-		if !varExists {
-			newVariableValue, err := Prompt(variableName)
-			if err != nil {
-				return "", err
-			}
-
-			variableValue = newVariableValue
-			vars[variableName] = variableValue
-		}
+// PopulateWithPartials populates the given string in the same way as Populate, but also replaces any include tags
+// (`{: include "path/to/partial.txt" :}`) with the populated contents of the partial (loaded with the loadPartial func).
+// Partials can include other partials, but a ValidationError is returned if a partial (directly or indirectly) includes
+// itself. If loadPartial is nil, a ValidationError is returned for any include tags.
+func PopulateWithPartials(text string, vars map[string]string, loadPartial PartialLoader) (string, error) {
+	template, err := Compile("", text)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	if err := template.Render(&builder, vars, loadPartial); err != nil {
+		return "", err
+	}
+
+	return builder.String(), nil
+}
+
+// lookup returns the value of the variable from the map (if it doesn't exist in the map, the user is prompted to provide it,
+// and it is then added to the map). If noInput is true, a MissingVariablesError is returned instead of prompting.
+func lookup(name string, vars map[string]string, noInput bool) (string, error) {
+	if value, varExists := vars[name]; varExists {
+		return value, nil
+	}
 
-		// Now replace the tag
-		resolvedText = strings.Replace(resolvedText, variableTag, variableValue, 1)
+	if noInput {
+		return "", &customerrors.MissingVariablesError{Names: []string{name}}
 	}
 
-	// Finally, resolve any escaped tags
-	resolvedText = strings.ReplaceAll(resolvedText, "{\\:", "{:")
+	value, err := Prompt(name)
+	if err != nil {
+		return "", err
+	}
+
+	vars[name] = value
+	return value, nil
+}
+
+// Tags returns the (parsed) variable tags within the given string, in the order they are used (see Template.Tags).
+// Include tags are ignored. If the string can't be compiled, no tags are returned (the error is reported when populating).
+func Tags(text string) []Tag {
+	template, err := Compile("", text)
+	if err != nil {
+		return []Tag{}
+	}
+
+	tags, _ := template.Tags(nil)
+	return tags
+}
+
+// Names returns the names of the variables that are used in tags within the given string (in the order they are first used).
+// Escaped tags are ignored.
+func Names(text string) []string {
+	names := []string{}
+
+	for _, tag := range Tags(text) {
+		if !slices.Contains(names, tag.Name) {
+			names = append(names, tag.Name)
+		}
+	}
 
-	return resolvedText, nil
+	return names
 }
@@ -1,6 +1,7 @@
 package variable_test
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/M-Derbyshire/scaff/variable"
@@ -86,3 +87,14 @@ func TestPopulateWillReplaceEscapedTagsWithTagsAndNotPopulateThem(t *testing.T)
 		t.Errorf("expected result to be '%s'. Got '%s'", expectedText, result)
 	}
 }
+
+func TestNamesWillReturnEachVariableNameOnceInOrderOfFirstUse(t *testing.T) {
+	text := `{: b :} {:a:} {: b :} {\: escaped :} {: c-1_2 :}`
+	expectedNames := []string{"b", "a", "c-1_2"}
+
+	result := variable.Names(text)
+
+	if !slices.Equal(result, expectedNames) {
+		t.Errorf("expected names to be %v. Got %v", expectedNames, result)
+	}
+}
@@ -1,7 +1,6 @@
 package variable
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"strings"
@@ -10,6 +9,7 @@ import (
 var (
 	// Stdin is an open file, pointing to the standard input
 	Stdin = os.Stdin
+
 	// PrintFormatted is used to print a formatted string to standard output
 	PrintFormatted = fmt.Printf
 )
@@ -18,20 +18,111 @@ var (
 // Returns the given value (empty strings are considered valid)
 func Prompt(varName string) (string, error) {
 	PrintFormatted("variable value required for '%s' > ", varName)
+	return readInput()
+}
+
+// PromptFor prompts the user for the value of a declared variable. The variable's description, and any default value, are
+// shown to the user (the default is used if nothing is entered). If the entered value is invalid, the user is told why, and
+// is prompted again.
+// For lists, the user is prompted repeatedly (each entry can be one item, or several comma-separated items), until nothing
+// is entered.
+// Returns the entered value (normalised by the definition's Check method)
+func PromptFor(definition Definition) (string, error) {
+	if definition.Description != "" {
+		PrintFormatted("%s\n", definition.Description)
+	}
+
+	hints := ""
+	switch definition.GetType() {
+	case TypeInt:
+		hints += " (a whole number)"
+	case TypeBool:
+		hints += " (true/false)"
+	case TypeEnum:
+		hints += fmt.Sprintf(" (one of: %s)", strings.Join(definition.Options, ", "))
+	case TypeList:
+		hints += " (a list: enter each item, then an empty line to finish)"
+	}
+
+	if definition.Default != "" {
+		hints += fmt.Sprintf(" [%s]", definition.Default)
+	}
+
+	for {
+		PrintFormatted("variable value required for '%s'%s > ", definition.Name, hints)
+
+		input, err := readInput()
+		if err != nil {
+			return "", err
+		}
+
+		if input != "" && definition.GetType() == TypeList {
+			input, err = readListItems(input)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if input == "" {
+			input = definition.Default
+		}
+
+		value, checkErr := definition.Check(input)
+		if checkErr == nil {
+			return value, nil
+		}
+
+		PrintFormatted("invalid value for '%s': %v\n", definition.Name, checkErr)
+	}
+}
+
+// readListItems reads the rest of a list's items (one entry per line, until an empty line), after the given first entry.
+// Returns the list value (see FormatList)
+func readListItems(firstEntry string) (string, error) {
+	items := ParseList(firstEntry)
+
+	for {
+		PrintFormatted("  next item (or nothing to finish) > ")
+
+		entry, err := readInput()
+		if err != nil {
+			return "", err
+		}
+
+		if entry == "" {
+			return FormatList(items), nil
+		}
+
+		items = append(items, ParseList(entry)...)
+	}
+}
+
+// readInput reads a line of input from the Stdin, and removes the line ending (and any surrounding quotes) from it.
+// Only one line is read from the Stdin (nothing after the line is consumed, so it can be read by the next prompt).
+func readInput() (string, error) {
+	input := []byte{}
+	nextByte := make([]byte, 1)
+	for {
+		readCount, err := Stdin.Read(nextByte)
+		if readCount > 0 {
+			if nextByte[0] == '\n' {
+				break
+			}
+
+			input = append(input, nextByte[0])
+		}
 
-	inReader := bufio.NewReader(Stdin)
-	input, err := inReader.ReadString('\n')
-	if err != nil {
-		return "", err
+		if err != nil {
+			return "", err
+		}
 	}
 
-	// Remove CRLF (or might just be line feed) from end of value
-	input = strings.TrimSuffix(input, "\n")
-	input = strings.TrimSuffix(input, "\r")
+	// Remove the carriage return (if the line ended with a CRLF)
+	inputText := strings.TrimSuffix(string(input), "\r")
 
 	// Remove surrounding quotes
-	input = strings.TrimPrefix(input, "\"")
-	input = strings.TrimSuffix(input, "\"")
+	inputText = strings.TrimPrefix(inputText, "\"")
+	inputText = strings.TrimSuffix(inputText, "\"")
 
-	return input, nil
+	return inputText, nil
 }
@@ -168,3 +168,105 @@ func TestPromptWillRemoveSurroundingQuotes(t *testing.T) {
 		t.Errorf("expected result to not end with quote. Got string ending with %s quote", quoteValue)
 	}
 }
+
+func TestPromptForWillShowDescriptionAndDefault(t *testing.T) {
+	originalPrintF := variable.PrintFormatted
+	defer func() { variable.PrintFormatted = originalPrintF }()
+
+	var output strings.Builder
+	variable.PrintFormatted = func(format string, a ...any) (n int, err error) {
+		return fmt.Fprintf(&output, format, a...)
+	}
+
+	err := setupMockStdIn("\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	definition := variable.Definition{
+		Name:        "name",
+		Description: "The name of the service",
+		Default:     "my-service",
+	}
+
+	result, err := variable.PromptFor(definition)
+	if err != nil {
+		t.Errorf("expected to recieve no error. Got %e", err)
+	}
+
+	if result != definition.Default {
+		t.Errorf("expected result to be the default ('%s'). Got '%s'", definition.Default, result)
+	}
+
+	expectedOutput := "The name of the service\nvariable value required for 'name' [my-service] > "
+	if output.String() != expectedOutput {
+		t.Errorf("expected output to be '%s'. Got '%s'", expectedOutput, output.String())
+	}
+}
+
+func TestPromptForWillPromptAgainIfValueIsInvalid(t *testing.T) {
+	originalPrintF := variable.PrintFormatted
+	defer func() { variable.PrintFormatted = originalPrintF }()
+
+	var output strings.Builder
+	variable.PrintFormatted = func(format string, a ...any) (n int, err error) {
+		return fmt.Fprintf(&output, format, a...)
+	}
+
+	err := setupMockStdIn("ten\n10\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	definition := variable.Definition{
+		Name: "count",
+		Type: variable.TypeInt,
+	}
+
+	result, err := variable.PromptFor(definition)
+	if err != nil {
+		t.Errorf("expected to recieve no error. Got %e", err)
+	}
+
+	if result != "10" {
+		t.Errorf("expected result to be '10'. Got '%s'", result)
+	}
+
+	expectedOutput := "variable value required for 'count' (a whole number) > " +
+		"invalid value for 'count': 'ten' is not a whole number\n" +
+		"variable value required for 'count' (a whole number) > "
+	if output.String() != expectedOutput {
+		t.Errorf("expected output to be '%s'. Got '%s'", expectedOutput, output.String())
+	}
+}
+
+func TestPromptForWillPromptRepeatedlyForListItems(t *testing.T) {
+	originalPrintF := variable.PrintFormatted
+	defer func() { variable.PrintFormatted = originalPrintF }()
+
+	var output strings.Builder
+	variable.PrintFormatted = func(format string, a ...any) (n int, err error) {
+		return fmt.Fprintf(&output, format, a...)
+	}
+
+	err := setupMockStdIn("id\nname, email\n\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := variable.PromptFor(variable.Definition{Name: "fields", Type: variable.TypeList})
+	if err != nil {
+		t.Errorf("expected to recieve no error. Got %e", err)
+	}
+
+	if result != "id,name,email" {
+		t.Errorf("expected result to be 'id,name,email'. Got '%s'", result)
+	}
+
+	expectedOutput := "variable value required for 'fields' (a list: enter each item, then an empty line to finish) > " +
+		"  next item (or nothing to finish) > " +
+		"  next item (or nothing to finish) > "
+	if output.String() != expectedOutput {
+		t.Errorf("expected output to be '%s'. Got '%s'", expectedOutput, output.String())
+	}
+}
@@ -0,0 +1,52 @@
+package variable
+
+import (
+	"fmt"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+)
+
+// Resolve makes sure that every declared variable has a valid value in the given map.
+// Values that are already in the map are checked (and normalised), and the user is prompted for any that are missing (in
+// the order the variables are declared). The map is updated with the resolved values.
+// If noInput is true, the user is never prompted. Missing variables that have a default value are given that value, and a
+// MissingVariablesError (listing the rest of the missing variables) is returned.
+// A ValidationError is returned if a value that is already in the map is invalid.
+func Resolve(definitions []Definition, vars map[string]string, noInput bool) error {
+	missingNames := []string{}
+
+	for _, definition := range definitions {
+		value, varExists := vars[definition.Name]
+		if !varExists && noInput {
+			if definition.Default == "" {
+				missingNames = append(missingNames, definition.Name)
+				continue
+			}
+
+			value = definition.Default
+		} else if !varExists {
+			promptedValue, err := PromptFor(definition)
+			if err != nil {
+				return err
+			}
+
+			vars[definition.Name] = promptedValue
+			continue
+		}
+
+		checkedValue, checkErr := definition.Check(value)
+		if checkErr != nil {
+			return &customerrors.ValidationError{
+				Message: fmt.Sprintf("invalid value for the variable '%s': %v", definition.Name, checkErr),
+			}
+		}
+
+		vars[definition.Name] = checkedValue
+	}
+
+	if len(missingNames) > 0 {
+		return &customerrors.MissingVariablesError{Names: missingNames}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package variable_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+func TestResolveWillPromptForMissingVariablesInDeclaredOrder(t *testing.T) {
+	originalPrintF := variable.PrintFormatted
+	defer func() { variable.PrintFormatted = originalPrintF }()
+	variable.PrintFormatted = func(format string, a ...any) (n int, err error) {
+		return 0, nil
+	}
+
+	if err := setupMockStdIn("second value\nfirst value\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	definitions := []variable.Definition{
+		{Name: "var2"},
+		{Name: "var1"},
+		{Name: "var3"},
+	}
+	vars := map[string]string{"var3": "third value"}
+
+	if err := variable.Resolve(definitions, vars, false); err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	expectedVars := map[string]string{
+		"var1": "first value",
+		"var2": "second value",
+		"var3": "third value",
+	}
+
+	for name, expectedValue := range expectedVars {
+		if vars[name] != expectedValue {
+			t.Errorf("expected '%s' to be '%s'. Got '%s'", name, expectedValue, vars[name])
+		}
+	}
+}
+
+func TestResolveWillNormaliseGivenValues(t *testing.T) {
+	definitions := []variable.Definition{
+		{Name: "withTests", Type: variable.TypeBool},
+	}
+	vars := map[string]string{"withTests": "yes"}
+
+	if err := variable.Resolve(definitions, vars, false); err == nil {
+		t.Errorf("expected an error for the value 'yes'. Got nil")
+	}
+
+	vars["withTests"] = "T"
+	if err := variable.Resolve(definitions, vars, false); err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+	}
+
+	if vars["withTests"] != "true" {
+		t.Errorf("expected the value to be normalised to 'true'. Got '%s'", vars["withTests"])
+	}
+}
+
+func TestResolveWillReturnValidationErrorIfGivenValueIsInvalid(t *testing.T) {
+	definitions := []variable.Definition{
+		{Name: "count", Type: variable.TypeInt},
+	}
+	vars := map[string]string{"count": "many"}
+
+	expectedErr := "invalid value for the variable 'count': 'many' is not a whole number"
+
+	err := variable.Resolve(definitions, vars, false)
+	if err == nil {
+		t.Errorf("expected an error. Got nil")
+		return
+	}
+
+	var validationErr *customerrors.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected a ValidationError. Got '%s'", err.Error())
+	}
+
+	if err.Error() != expectedErr {
+		t.Errorf("expected error to be '%s'. Got '%s'", expectedErr, err.Error())
+	}
+}
+
+func TestResolveWillNotPromptIfNoInputAndReturnAllMissingVariables(t *testing.T) {
+	// Nothing is available on the Stdin, so a prompt would fail with an EOF error
+	if err := setupMockStdIn(""); err != nil {
+		t.Fatal(err)
+	}
+
+	definitions := []variable.Definition{
+		{Name: "missing1"},
+		{Name: "withDefault", Default: "my default"},
+		{Name: "given"},
+		{Name: "missing2", Type: variable.TypeInt},
+	}
+	vars := map[string]string{"given": "given value"}
+
+	err := variable.Resolve(definitions, vars, true)
+	if err == nil {
+		t.Errorf("expected an error. Got nil")
+		return
+	}
+
+	var missingErr *customerrors.MissingVariablesError
+	if !errors.As(err, &missingErr) {
+		t.Errorf("expected a MissingVariablesError. Got '%s'", err.Error())
+		return
+	}
+
+	expectedNames := []string{"missing1", "missing2"}
+	if !slices.Equal(missingErr.Names, expectedNames) {
+		t.Errorf("expected the missing variables to be %v. Got %v", expectedNames, missingErr.Names)
+	}
+
+	if vars["withDefault"] != "my default" {
+		t.Errorf("expected the default value to be used. Got '%s'", vars["withDefault"])
+	}
+}
@@ -0,0 +1,58 @@
+package variable
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+)
+
+// Tag is a parsed variable tag (e.g. `{: name | replace "-" "_" | upper :}`)
+type Tag struct {
+	Name    string
+	Filters []FilterCall // The filters that the variable's value is passed through (in order)
+	IsList  bool         // True if the variable is the list in a range block (so its value is used as a list)
+}
+
+// FilterCall is a filter (and the arguments given to it) within a tag
+type FilterCall struct {
+	Name string
+	Args []string
+}
+
+// HasDefault identifies if the tag uses the "default" filter (in which case the variable doesn't need a value)
+func (t *Tag) HasDefault() bool {
+	return slices.ContainsFunc(t.Filters, func(filter FilterCall) bool {
+		return filter.Name == "default"
+	})
+}
+
+// Apply passes the given value through the tag's filters, and returns the result
+func (t *Tag) Apply(value string) (string, error) {
+	for _, filter := range t.Filters {
+		filterFunc, filterExists := filters[filter.Name]
+		if !filterExists {
+			return "", &customerrors.ValidationError{
+				Message: fmt.Sprintf(
+					"unknown filter '%s' used with the variable '%s' (expected one of: %s)",
+					filter.Name,
+					t.Name,
+					strings.Join(slices.Sorted(maps.Keys(filters)), ", "),
+				),
+			}
+		}
+
+		filteredValue, err := filterFunc(value, filter.Args)
+		if err != nil {
+			return "", &customerrors.ValidationError{
+				Message: fmt.Sprintf("unable to apply the filter '%s' to the variable '%s': %v", filter.Name, t.Name, err),
+			}
+		}
+
+		value = filteredValue
+	}
+
+	return value, nil
+}
@@ -0,0 +1,209 @@
+package variable
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+)
+
+// Template is a compiled template (or file/directory name), which can be rendered any number of times
+type Template struct {
+	name    string // Identifies the template in errors (e.g. the path to the template file)
+	nodes   []node
+	noInput bool // If true, a MissingVariablesError is returned for missing variables (rather than prompting for them)
+}
+
+// Compile compiles the given text into a Template.
+// The name identifies the template in any errors (e.g. the path to the template file). Errors are ValidationErrors, and
+// include the name, line and column of the problem (e.g. "/path/to/template.txt:3:14: the tag is missing its closing ':}'").
+func Compile(name, text string) (*Template, error) {
+	return CompileWithDelimiters(name, text, DefaultDelimiters)
+}
+
+// CompileWithDelimiters compiles the given text into a Template, in the same way as Compile, but with tags that use the
+// given delimiters (e.g. "<%" and "%>"). If the delimiters haven't been set, the DefaultDelimiters are used.
+func CompileWithDelimiters(name, text string, delimiters Delimiters) (*Template, error) {
+	if delimiters.isZero() {
+		delimiters = DefaultDelimiters
+	}
+
+	items, err := lex(name, text, delimiters)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := parse(name, items, delimiters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Template{name: name, nodes: nodes}, nil
+}
+
+// Render writes the template to the given writer, with each block rendered and each variable tag replaced with its value
+// from the vars map (passed through any filters in the tag). Substituted values are written as they are (any tags within them
+// are not populated).
+// If a variable doesn't exist in the map, the user is prompted to provide it (and it is then added to the map), unless the
+// tag uses the "default" filter (or the template was compiled with NoInput, in which case a MissingVariablesError is
+// returned).
+// Partials are loaded with the loadPartial func (if this is nil, a ValidationError is returned for any include tags).
+func (t *Template) Render(writer io.Writer, vars map[string]string, loadPartial PartialLoader) error {
+	context := &renderContext{
+		writer:      &trackingWriter{writer: writer},
+		vars:        vars,
+		loadPartial: loadPartial,
+		partials:    make(map[string]*loadedPartial),
+		noInput:     t.noInput,
+	}
+
+	return renderNodes(t.nodes, context)
+}
+
+// Tags returns the variable tags within the template, in the order they are used.
+// The variables in the conditions of "if" tags, and the lists in "range" tags, are also returned (as tags without filters).
+// The variables that are defined by range blocks are only available within those blocks, so are not returned.
+// The tags within included partials are also returned, if loadPartial isn't nil (otherwise, include tags are ignored).
+// An error is returned if a partial can't be loaded (or is invalid), or if a partial (directly or indirectly) includes itself.
+func (t *Template) Tags(loadPartial PartialLoader) ([]Tag, error) {
+	collector := &tagCollector{
+		tags:        []Tag{},
+		loadPartial: loadPartial,
+		partials:    make(map[string]*loadedPartial),
+	}
+
+	err := collector.collect(t.nodes, []string{}, []string{})
+	return collector.tags, err
+}
+
+// node is a part of a compiled template (text, a variable tag, a block, or an include)
+type node interface {
+	render(context *renderContext) error
+}
+
+// renderContext holds the state used while rendering
+type renderContext struct {
+	writer       *trackingWriter
+	vars         map[string]string
+	loadPartial  PartialLoader             // If nil, include tags can't be used
+	partials     map[string]*loadedPartial // The partials that have already been loaded (keyed by the path in the include tag)
+	includeStack []string                  // The full paths of the partials that are currently being rendered (used to detect cycles)
+	noInput      bool                      // If true, a MissingVariablesError is returned for missing variables (rather than prompting)
+}
+
+// trackingWriter is a writer that keeps track of the last byte that was written to it
+type trackingWriter struct {
+	writer   io.Writer
+	lastByte byte
+	written  int
+}
+
+func (w *trackingWriter) Write(bytes []byte) (int, error) {
+	if len(bytes) > 0 {
+		w.lastByte = bytes[len(bytes)-1]
+		w.written += len(bytes)
+	}
+
+	return w.writer.Write(bytes)
+}
+
+// renderNodes renders each of the given nodes
+func renderNodes(nodes []node, context *renderContext) error {
+	for _, currentNode := range nodes {
+		if err := currentNode.render(context); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// textNode is plain text within a template
+type textNode struct {
+	text string
+}
+
+func (n *textNode) render(context *renderContext) error {
+	_, err := io.WriteString(context.writer, n.text)
+	return err
+}
+
+// variableNode is a variable tag within a template
+type variableNode struct {
+	tag          Tag
+	templateName string
+	pos          position
+}
+
+// render writes the variable's value (passed through the tag's filters)
+func (n *variableNode) render(context *renderContext) error {
+	value := context.vars[n.tag.Name]
+	if !n.tag.HasDefault() {
+		var err error
+		value, err = lookup(n.tag.Name, context.vars, context.noInput)
+		if err != nil {
+			return err
+		}
+	}
+
+	filteredValue, err := n.tag.Apply(value)
+	if err != nil {
+		return templateError(n.templateName, n.pos, err.Error())
+	}
+
+	_, err = io.WriteString(context.writer, filteredValue)
+	return err
+}
+
+// loadedPartial is a partial that has been loaded (and compiled)
+type loadedPartial struct {
+	template *Template
+	fullPath string
+}
+
+// loadPartial loads (and compiles) the partial at the given path, using the loader (partials are only loaded once, and are
+// then kept in the partials map). The includeStack is used to confirm that the partial isn't already being included.
+func loadPartial(n *includeNode, loader PartialLoader, partials map[string]*loadedPartial, includeStack []string) (*loadedPartial, error) {
+	if loader == nil {
+		return nil, templateError(n.templateName, n.pos, fmt.Sprintf("partials can't be included here ('%s')", n.includeTag))
+	}
+
+	partial, isLoaded := partials[n.partialPath]
+	if !isLoaded {
+		contents, fullPath, err := loader(n.partialPath)
+		if err != nil {
+			return nil, err
+		}
+
+		partialTemplate, err := CompileWithDelimiters(fullPath, contents, n.delimiters)
+		if err != nil {
+			return nil, err
+		}
+
+		partial = &loadedPartial{template: partialTemplate, fullPath: fullPath}
+		partials[n.partialPath] = partial
+	}
+
+	if cycleStart := slices.Index(includeStack, partial.fullPath); cycleStart >= 0 {
+		return nil, templateError(n.templateName, n.pos, fmt.Sprintf(
+			"the partial '%s' includes itself (%s -> %s)",
+			partial.fullPath,
+			strings.Join(includeStack[cycleStart:], " -> "),
+			partial.fullPath,
+		))
+	}
+
+	return partial, nil
+}
+
+// templateError creates a ValidationError for a problem at the given position within a template
+func templateError(templateName string, pos position, message string) error {
+	location := fmt.Sprintf("%d:%d", pos.line, pos.column)
+	if templateName != "" {
+		location = fmt.Sprintf("%s:%d:%d", templateName, pos.line, pos.column)
+	}
+
+	return &customerrors.ValidationError{Message: fmt.Sprintf("%s: %s", location, message)}
+}
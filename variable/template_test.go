@@ -0,0 +1,113 @@
+package variable_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/customerrors"
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+func TestCompileWillReturnTheFileLineAndColumnOfMalformedTags(t *testing.T) {
+	cases := map[string]string{
+		"first line\n  {: name":          "t.txt:2:3: the tag is missing its closing ':}'",
+		"{: name :} {: name\n:}":         "t.txt:1:12: the tag is missing its closing ':}'",
+		"ab {: name @ :}":                "t.txt:1:12: unexpected character '@' in the tag",
+		"a\nb {: name | replace \"x :}":  "t.txt:2:21: a quoted value is missing its closing quote",
+		"{: :}":                          "t.txt:1:1: the tag is empty",
+		"x\n\n{: if a :}\ntext":          "t.txt:3:1: the 'if' block is missing its '{: end :}' tag",
+		"{: name | nonExistentFilter :}": "t.txt:1:11: unknown filter",
+		"{: if a :}{: end :}\n{: end :}": "t.txt:2:1:",
+		"héllo {: name |":                "t.txt:1:7: the tag is missing its closing ':}'",
+	}
+
+	for text, expectedMessage := range cases {
+		_, err := variable.Compile("t.txt", text)
+
+		var validationErr *customerrors.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("expected a ValidationError for '%s'. Got %v", text, err)
+			continue
+		}
+
+		if !strings.HasPrefix(validationErr.Message, expectedMessage) {
+			t.Errorf("expected error for '%s' to start with '%s'. Got '%s'", text, expectedMessage, validationErr.Message)
+		}
+	}
+}
+
+func TestRenderWillWriteTheTemplateToTheWriter(t *testing.T) {
+	template, err := variable.Compile("t.txt", "Hello {: name | upper :}, {\\: escaped :}")
+	if err != nil {
+		t.Fatalf("expected no error. Got %v", err)
+	}
+
+	for _, name := range []string{"world", "again"} {
+		var builder strings.Builder
+		if err := template.Render(&builder, map[string]string{"name": name}, nil); err != nil {
+			t.Errorf("expected no error. Got %v", err)
+		}
+
+		expected := "Hello " + strings.ToUpper(name) + ", {: escaped :}"
+		if builder.String() != expected {
+			t.Errorf("expected '%s'. Got '%s'", expected, builder.String())
+		}
+	}
+}
+
+func TestRenderWillNotInterpretTagsWithinSubstitutedValues(t *testing.T) {
+	template, err := variable.Compile("t.txt", "{: first :}|{: second :}")
+	if err != nil {
+		t.Fatalf("expected no error. Got %v", err)
+	}
+
+	vars := map[string]string{"first": "{: second :}", "second": "{\\: first :} {: if"}
+
+	var builder strings.Builder
+	if err := template.Render(&builder, vars, nil); err != nil {
+		t.Errorf("expected no error. Got %v", err)
+	}
+
+	expected := "{: second :}|{\\: first :} {: if"
+	if builder.String() != expected {
+		t.Errorf("expected '%s'. Got '%s'", expected, builder.String())
+	}
+}
+
+func TestRenderWillReturnMissingVariablesInsteadOfPromptingIfCompiledWithNoInput(t *testing.T) {
+	originalPrintF := variable.PrintFormatted
+	defer func() { variable.PrintFormatted = originalPrintF }()
+
+	prompted := []string{}
+	variable.PrintFormatted = func(format string, a ...any) (n int, err error) {
+		prompted = append(prompted, a[0].(string))
+		return 0, nil
+	}
+
+	cases := map[string]variable.Options{
+		`{: name :}`:                {NoInput: true},
+		`{: if flag :}yes{: end :}`: {NoInput: true},
+		`{: range item in items :}{: item :}{: end :}`: {NoInput: true},
+		`{{ .name }}`: {Engine: variable.EngineGoTemplate, NoInput: true},
+	}
+
+	for text, options := range cases {
+		template, err := variable.CompileWithOptions(options, "t.txt", text)
+		if err != nil {
+			t.Fatalf("expected no error for '%s'. Got %v", text, err)
+		}
+
+		var builder strings.Builder
+		err = template.Render(&builder, map[string]string{}, nil)
+
+		var missingErr *customerrors.MissingVariablesError
+		if !errors.As(err, &missingErr) {
+			t.Errorf("expected a MissingVariablesError for '%s'. Got %v", text, err)
+		}
+	}
+
+	if len(prompted) != 0 {
+		t.Errorf("expected not to be prompted. Got %v", prompted)
+	}
+}
@@ -0,0 +1,12 @@
+package variable
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// IsTerminal identifies if the given file is a terminal (rather than a pipe, a regular file, or /dev/null)
+func IsTerminal(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
@@ -0,0 +1,127 @@
+package variable
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ReadFile is used to read vars files from the filesystem
+var ReadFile = os.ReadFile
+
+// LoadFile loads the variables from a vars file. The format of the file is based on its extension:
+//   - ".json" files contain an object, where each property is a variable.
+//   - ".yaml"/".yml" files contain a mapping, where each key is a variable.
+//   - Any other file is treated as a dotenv file (where each line is "NAME=value").
+//
+// Values can be strings, numbers or booleans. Arrays are converted to lists (see FormatList).
+func LoadFile(filePath string) (map[string]string, error) {
+	fileBytes, err := ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json":
+		return parseJSONVars(fileBytes)
+	case ".yaml", ".yml":
+		return parseYAMLVars(string(fileBytes))
+	default:
+		return parseDotenvVars(string(fileBytes))
+	}
+}
+
+// FormatList converts the given items into a list value.
+// Items are separated with commas, unless any item contains a comma (in which case the list is formatted as a JSON array).
+func FormatList(items []string) string {
+	for _, item := range items {
+		if strings.Contains(item, ",") {
+			jsonBytes, _ := json.Marshal(items)
+			return string(jsonBytes)
+		}
+	}
+
+	return strings.Join(items, ",")
+}
+
+// ParseList converts a list value back into its items (see FormatList).
+// Values that are a JSON array of strings are decoded. Otherwise, the value is split on its commas (with the whitespace around
+// each item removed). Empty values contain no items.
+func ParseList(value string) []string {
+	trimmedValue := strings.TrimSpace(value)
+	if trimmedValue == "" {
+		return []string{}
+	}
+
+	if strings.HasPrefix(trimmedValue, "[") {
+		var items []string
+		if err := json.Unmarshal([]byte(trimmedValue), &items); err == nil {
+			return items
+		}
+	}
+
+	items := strings.Split(trimmedValue, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+
+	return items
+}
+
+// parseJSONVars parses the variables from the contents of a JSON vars file
+func parseJSONVars(fileBytes []byte) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(fileBytes))
+	decoder.UseNumber()
+
+	var rawVars map[string]any
+	if err := decoder.Decode(&rawVars); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	vars := make(map[string]string)
+	for name, rawValue := range rawVars {
+		if rawItems, isArray := rawValue.([]any); isArray {
+			items := []string{}
+			for _, rawItem := range rawItems {
+				item, err := formatJSONScalar(name, rawItem)
+				if err != nil {
+					return nil, err
+				}
+
+				items = append(items, item)
+			}
+
+			vars[name] = FormatList(items)
+			continue
+		}
+
+		value, err := formatJSONScalar(name, rawValue)
+		if err != nil {
+			return nil, err
+		}
+
+		vars[name] = value
+	}
+
+	return vars, nil
+}
+
+// formatJSONScalar converts a JSON string/number/boolean to a variable value
+func formatJSONScalar(name string, rawValue any) (string, error) {
+	switch value := rawValue.(type) {
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("the value of '%s' should be a string, number, boolean or array of these", name)
+	}
+}
@@ -0,0 +1,178 @@
+package variable_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/M-Derbyshire/scaff/variable"
+)
+
+// mockVarsFile sets up the ReadFile func to return the given contents
+func mockVarsFile(contents string) {
+	variable.ReadFile = func(filePath string) ([]byte, error) {
+		return []byte(contents), nil
+	}
+}
+
+// checkLoadedVars loads the given file, and confirms the resulting variables match the expected ones
+func checkLoadedVars(t *testing.T, filePath string, expectedVars map[string]string) {
+	t.Helper()
+
+	result, err := variable.LoadFile(filePath)
+	if err != nil {
+		t.Errorf("expected no error. Got '%s'", err.Error())
+		return
+	}
+
+	if len(result) != len(expectedVars) {
+		t.Errorf("expected %d variables. Got %d: %v", len(expectedVars), len(result), result)
+	}
+
+	for name, expectedValue := range expectedVars {
+		if result[name] != expectedValue {
+			t.Errorf("expected '%s' to be '%s'. Got '%s'", name, expectedValue, result[name])
+		}
+	}
+}
+
+func TestLoadFileWillLoadJSONFiles(t *testing.T) {
+	mockVarsFile(`{
+		"name": "my-service",
+		"port": 8080,
+		"withTests": true,
+		"license": "line 1\nline 2",
+		"fields": ["id", "name"],
+		"commaItems": ["a,b", "c"]
+	}`)
+
+	checkLoadedVars(t, "vars.JSON", map[string]string{
+		"name":       "my-service",
+		"port":       "8080",
+		"withTests":  "true",
+		"license":    "line 1\nline 2",
+		"fields":     "id,name",
+		"commaItems": `["a,b","c"]`,
+	})
+}
+
+func TestLoadFileWillReturnErrorForNestedJSONObjects(t *testing.T) {
+	mockVarsFile(`{ "nested": { "a": "b" } }`)
+
+	if _, err := variable.LoadFile("vars.json"); err == nil {
+		t.Errorf("expected an error. Got nil")
+	}
+}
+
+func TestLoadFileWillLoadYAMLFiles(t *testing.T) {
+	mockVarsFile(`---
+# A comment
+name: my-service # trailing comment
+port: 8080
+quoted: "say \"hi\"\tnow"
+single: 'it''s # not a comment'
+empty:
+license: |
+  line 1
+    indented line 2
+
+  line 4
+summary: >-
+  folded
+  text
+
+  new paragraph
+fields:
+  - id
+  - "name"
+flow: [id, 'e,mail', name]
+`)
+
+	checkLoadedVars(t, "vars.yml", map[string]string{
+		"name":    "my-service",
+		"port":    "8080",
+		"quoted":  "say \"hi\"\tnow",
+		"single":  "it's # not a comment",
+		"empty":   "",
+		"license": "line 1\n  indented line 2\n\nline 4\n",
+		"summary": "folded text\nnew paragraph",
+		"fields":  "id,name",
+		"flow":    `["id","e,mail","name"]`,
+	})
+}
+
+func TestLoadFileWillReturnErrorForNestedYAMLMappings(t *testing.T) {
+	mockVarsFile("parent:\n  child: value\n")
+
+	if _, err := variable.LoadFile("vars.yaml"); err == nil {
+		t.Errorf("expected an error. Got nil")
+	}
+}
+
+func TestLoadFileWillLoadDotenvFiles(t *testing.T) {
+	mockVarsFile(`# A comment
+NAME=my-service
+export PORT = 8080 # trailing comment
+QUOTED="say \"hi\"\nnow"
+MULTILINE="line 1
+line 2"
+SINGLE='no \n escapes # here'
+EMPTY=
+`)
+
+	checkLoadedVars(t, ".env", map[string]string{
+		"NAME":      "my-service",
+		"PORT":      "8080",
+		"QUOTED":    "say \"hi\"\nnow",
+		"MULTILINE": "line 1\nline 2",
+		"SINGLE":    `no \n escapes # here`,
+		"EMPTY":     "",
+	})
+}
+
+func TestLoadFileWillReturnErrorForUnterminatedDotenvQuotes(t *testing.T) {
+	mockVarsFile("NAME=\"my-service\nOTHER=value\n")
+
+	if _, err := variable.LoadFile("my.env"); err == nil {
+		t.Errorf("expected an error. Got nil")
+	}
+}
+
+func TestLoadFileWillReturnErrorFromReadFile(t *testing.T) {
+	expectedErr := "my read error"
+	variable.ReadFile = func(filePath string) ([]byte, error) {
+		return nil, errors.New(expectedErr)
+	}
+
+	_, err := variable.LoadFile("vars.json")
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("expected error to be '%s'. Got '%v'", expectedErr, err)
+	}
+}
+
+func TestFormatListWillUseJSONOnlyIfAnItemContainsAComma(t *testing.T) {
+	if result := variable.FormatList([]string{"a", "b c"}); result != "a,b c" {
+		t.Errorf("expected 'a,b c'. Got '%s'", result)
+	}
+
+	if result := variable.FormatList([]string{"a", "b,c"}); result != `["a","b,c"]` {
+		t.Errorf(`expected '["a","b,c"]'. Got '%s'`, result)
+	}
+}
+
+func TestParseListWillSplitCommaSeparatedValuesAndDecodeJSONArrays(t *testing.T) {
+	testCases := map[string][]string{
+		"":                       {},
+		"id":                     {"id"},
+		"id, name ,email":        {"id", "name", "email"},
+		`["a, b", "c"]`:          {"a, b", "c"},
+		`[not json, but a list]`: {"[not json", "but a list]"},
+	}
+
+	for value, expectedItems := range testCases {
+		result := variable.ParseList(value)
+		if !slices.Equal(result, expectedItems) {
+			t.Errorf("expected '%s' to be parsed as %v. Got %v", value, expectedItems, result)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package variable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseYAMLVars parses the variables from the contents of a YAML vars file.
+// Only the subset of YAML that is needed for variables is supported: a single mapping of names to values, where each value
+// is a scalar (plain, single-quoted or double-quoted), a block scalar ("|" or ">", with an optional "-" or "+" chomping
+// indicator), or a sequence of scalars (either as "- item" lines, or in the flow style "[item1, item2]").
+func parseYAMLVars(text string) (map[string]string, error) {
+	vars := make(map[string]string)
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := lines[i]
+		trimmedLine := strings.TrimSpace(line)
+
+		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") || trimmedLine == "---" || trimmedLine == "..." {
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, fmt.Errorf("unexpected indentation on line %d (only a single mapping of names to values is supported)", lineNumber)
+		}
+
+		name, rest, hasColon := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !hasColon || name == "" {
+			return nil, fmt.Errorf("line %d should be in the format 'name: value'", lineNumber)
+		}
+		if unquotedName, isQuoted := unquoteYAMLKey(name); isQuoted {
+			name = unquotedName
+		}
+
+		rest = strings.TrimSpace(rest)
+
+		// Find the indented lines that belong to this value
+		nestedEnd := i + 1
+		for nestedEnd < len(lines) && (strings.TrimSpace(lines[nestedEnd]) == "" || lines[nestedEnd][0] == ' ' || lines[nestedEnd][0] == '\t') {
+			nestedEnd++
+		}
+		nestedLines := lines[i+1 : nestedEnd]
+
+		var value string
+		var err error
+		switch {
+		case strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, ">"):
+			value, err = parseYAMLBlockScalar(rest, nestedLines, lineNumber)
+			i = nestedEnd - 1
+		case strings.HasPrefix(rest, "["):
+			value, err = parseYAMLFlowSequence(rest, lineNumber)
+		case rest == "" || strings.HasPrefix(rest, "#"):
+			value, err = parseYAMLBlockSequence(nestedLines, lineNumber)
+			i = nestedEnd - 1
+		default:
+			value, err = parseYAMLScalar(rest, lineNumber)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		vars[name] = value
+	}
+
+	return vars, nil
+}
+
+// unquoteYAMLKey removes the quotes from a quoted mapping key (the bool is false if the key isn't quoted)
+func unquoteYAMLKey(key string) (string, bool) {
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') && key[len(key)-1] == key[0] {
+		return key[1 : len(key)-1], true
+	}
+
+	return key, false
+}
+
+// parseYAMLScalar parses a single-line scalar value (removing any trailing comment)
+func parseYAMLScalar(text string, lineNumber int) (string, error) {
+	text = strings.TrimSpace(text)
+
+	if strings.HasPrefix(text, "\"") {
+		closingIndex := findClosingQuote(text[1:], '"')
+		if closingIndex < 0 {
+			return "", fmt.Errorf("the value on line %d is missing its closing quote", lineNumber)
+		}
+
+		return unescapeDoubleQuoted(text[1 : closingIndex+1]), nil
+	}
+
+	if strings.HasPrefix(text, "'") {
+		// Within single quotes, a quote is escaped by doubling it
+		value := strings.Builder{}
+		for i := 1; i < len(text); i++ {
+			if text[i] != '\'' {
+				value.WriteByte(text[i])
+				continue
+			}
+
+			if i+1 < len(text) && text[i+1] == '\'' {
+				value.WriteByte('\'')
+				i++
+				continue
+			}
+
+			return value.String(), nil
+		}
+
+		return "", fmt.Errorf("the value on line %d is missing its closing quote", lineNumber)
+	}
+
+	if commentIndex := strings.Index(text, " #"); commentIndex >= 0 {
+		text = strings.TrimSpace(text[:commentIndex])
+	}
+
+	if text == "~" || text == "null" {
+		return "", nil
+	}
+
+	return text, nil
+}
+
+// parseYAMLBlockScalar parses a literal ("|") or folded (">") block scalar, from its header and its indented lines
+func parseYAMLBlockScalar(header string, nestedLines []string, lineNumber int) (string, error) {
+	isFolded := header[0] == '>'
+	chomping := ""
+	if indicators := strings.TrimSpace(strings.SplitN(header[1:], "#", 2)[0]); indicators != "" {
+		if indicators != "-" && indicators != "+" {
+			return "", fmt.Errorf("unsupported block scalar indicator on line %d: '%s'", lineNumber, indicators)
+		}
+
+		chomping = indicators
+	}
+
+	// The indentation of the first non-blank line is removed from every line
+	indentation := -1
+	for _, nestedLine := range nestedLines {
+		if strings.TrimSpace(nestedLine) != "" {
+			indentation = len(nestedLine) - len(strings.TrimLeft(nestedLine, " \t"))
+			break
+		}
+	}
+
+	contentLines := []string{}
+	for _, nestedLine := range nestedLines {
+		if len(nestedLine) < indentation {
+			contentLines = append(contentLines, "")
+			continue
+		}
+
+		contentLines = append(contentLines, nestedLine[max(indentation, 0):])
+	}
+
+	// Trailing blank lines are only kept with the "+" chomping indicator
+	trailingBlankCount := 0
+	for len(contentLines) > 0 && strings.TrimSpace(contentLines[len(contentLines)-1]) == "" {
+		contentLines = contentLines[:len(contentLines)-1]
+		trailingBlankCount++
+	}
+
+	var value string
+	if isFolded {
+		value = foldYAMLLines(contentLines)
+	} else {
+		value = strings.Join(contentLines, "\n")
+	}
+
+	switch {
+	case len(contentLines) == 0:
+		return "", nil
+	case chomping == "-":
+		return value, nil
+	case chomping == "+":
+		return value + "\n" + strings.Repeat("\n", trailingBlankCount), nil
+	default:
+		return value + "\n", nil
+	}
+}
+
+// foldYAMLLines joins the lines of a folded block scalar (lines are joined with spaces, and each blank line becomes a newline).
+// More-indented lines are kept as they are.
+func foldYAMLLines(lines []string) string {
+	var builder strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			previousLine := lines[i-1]
+			switch {
+			case line == "":
+				builder.WriteString("\n")
+			case previousLine == "":
+				// The line break before the blank line(s) has already been replaced
+			case strings.HasPrefix(line, " ") || strings.HasPrefix(previousLine, " "):
+				builder.WriteString("\n")
+			default:
+				builder.WriteString(" ")
+			}
+		}
+
+		builder.WriteString(line)
+	}
+
+	return builder.String()
+}
+
+// parseYAMLBlockSequence parses a sequence of "- item" lines into a list (see FormatList).
+// If there are no lines, the value is empty.
+func parseYAMLBlockSequence(nestedLines []string, lineNumber int) (string, error) {
+	items := []string{}
+
+	for i, nestedLine := range nestedLines {
+		trimmedLine := strings.TrimSpace(nestedLine)
+		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") {
+			continue
+		}
+
+		itemLineNumber := lineNumber + i + 1
+		if trimmedLine != "-" && !strings.HasPrefix(trimmedLine, "- ") {
+			return "", fmt.Errorf("unexpected value on line %d (nested mappings are not supported)", itemLineNumber)
+		}
+
+		item, err := parseYAMLScalar(strings.TrimPrefix(trimmedLine, "-"), itemLineNumber)
+		if err != nil {
+			return "", err
+		}
+
+		items = append(items, item)
+	}
+
+	if len(items) == 0 {
+		return "", nil
+	}
+
+	return FormatList(items), nil
+}
+
+// parseYAMLFlowSequence parses a flow sequence (e.g. "[item1, 'item 2']") into a list (see FormatList)
+func parseYAMLFlowSequence(text string, lineNumber int) (string, error) {
+	text = strings.TrimSpace(text)
+	if commentIndex := strings.LastIndex(text, "]"); commentIndex >= 0 {
+		text = text[:commentIndex+1]
+	}
+
+	if !strings.HasSuffix(text, "]") {
+		return "", fmt.Errorf("the sequence on line %d is missing its closing ']'", lineNumber)
+	}
+
+	inner := text[1 : len(text)-1]
+	items := []string{}
+
+	// Split on the commas that aren't within quotes
+	itemStart := 0
+	var quote byte
+	for i := 0; i <= len(inner); i++ {
+		if i < len(inner) {
+			character := inner[i]
+			switch {
+			case quote != 0 && character == '\\' && quote == '"':
+				i++
+				continue
+			case quote != 0 && character == quote:
+				quote = 0
+				continue
+			case quote == 0 && (character == '"' || character == '\''):
+				quote = character
+				continue
+			case quote != 0 || character != ',':
+				continue
+			}
+		}
+
+		rawItem := strings.TrimSpace(inner[itemStart:min(i, len(inner))])
+		itemStart = i + 1
+		if rawItem == "" {
+			continue
+		}
+
+		item, err := parseYAMLScalar(rawItem, lineNumber)
+		if err != nil {
+			return "", err
+		}
+
+		items = append(items, item)
+	}
+
+	return FormatList(items), nil
+}